@@ -0,0 +1,51 @@
+/*
+ * layout-bench runs every layout algorithm on generated graphs and writes one
+ * JSON result per line to stdout, with runtime, allocations and quality
+ * metrics, e.g. for comparing a change against the main branch
+ */
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/suxatcode/learn-graph-poc-backend/internal/layoutbench"
+)
+
+func main() {
+	sizes := flag.String("sizes", "100,1000", "comma separated numbers of nodes")
+	graphKinds := flag.String("graphs", strings.Join(layoutbench.GraphKinds, ","), "comma separated kinds of graphs")
+	layouterKinds := flag.String("layouters", strings.Join(layoutbench.LayouterKinds, ","), "comma separated layout algorithms")
+	seed := flag.Int64("seed", 1, "seed for graph generation and layouts")
+	timeout := flag.Duration("timeout", 5*time.Minute, "timeout of a single layout computation")
+	flag.Parse()
+
+	nodeCounts := []int{}
+	for _, size := range strings.Split(*sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			log.Fatalf("invalid size '%s': %v", size, err)
+		}
+		nodeCounts = append(nodeCounts, n)
+	}
+	graphs, err := layoutbench.Graphs(strings.Split(*graphKinds, ","), nodeCounts, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	layouters, err := layoutbench.Layouters(strings.Split(*layouterKinds, ","), *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	layoutbench.RunAll(context.Background(), graphs, layouters, *seed, *timeout, func(result layoutbench.Result) {
+		if err := encoder.Encode(result); err != nil {
+			log.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+/*
+ * migrate-resources extracts URLs from the free-text resources of all nodes
+ * and stores them as structured resources
+ */
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"github.com/suxatcode/learn-graph-poc-backend/db/postgres"
+)
+
+func main() {
+	conf := db.GetEnvConfig()
+	pgdb, err := postgres.NewPostgresDB(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	created, err := pgdb.(*postgres.PostgresDB).MigrateFreeTextResources(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Created %d resources from free-text resources.", created)
+}
@@ -0,0 +1,50 @@
+/*
+ * normalize-languages normalizes the language codes of the descriptions and
+ * resources of all nodes, e.g. "EN" → "en", "english" → "en", see
+ * db.LanguageNormalizer for the configuration
+ */
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"github.com/suxatcode/learn-graph-poc-backend/db/postgres"
+	"golang.org/x/text/language/display"
+)
+
+// languageNameAliases maps English and native language names to their code,
+// e.g. "german" → "de" and "deutsch" → "de"
+func languageNameAliases() map[string]string {
+	aliases := map[string]string{}
+	english := display.English.Languages()
+	for _, base := range display.Values.BaseLanguages() {
+		for _, name := range []string{english.Name(base), display.Self.Name(base)} {
+			if name != "" {
+				aliases[strings.ToLower(name)] = base.String()
+			}
+		}
+	}
+	return aliases
+}
+
+func main() {
+	dropInvalid := flag.Bool("drop-invalid", false, "remove texts, whose language can not be determined")
+	flag.Parse()
+	conf := db.GetEnvConfig()
+	pgdb, err := postgres.NewPostgresDB(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	res, err := pgdb.(*postgres.PostgresDB).NormalizeLanguages(context.Background(), languageNameAliases(), *dropInvalid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for nodeID, languages := range res.InvalidLanguages {
+		log.Printf("Node %d has invalid languages: %q", nodeID, languages)
+	}
+	log.Printf("Normalized languages of %d nodes.", res.ChangedNodes)
+}
@@ -12,17 +12,75 @@ import (
 
 type GraphDB interface {
 	Graph(ctx context.Context) (*model.Graph, error)
+	// Subgraph returns the nodes with the given IDs and the edges between
+	// them, unknown IDs are ignored
+	Subgraph(ctx context.Context, nodeIDs []string) (*model.Graph, error)
+	// Edges returns all edges without their nodes
+	Edges(ctx context.Context) ([]*model.Edge, error)
 	Node(ctx context.Context, ID string) (*model.Node, error)
 	// returns ID of the created node on success
 	CreateNode(ctx context.Context, user User, description *model.Text, resources *model.Text) (string, error)
 	// returns ID of the created edge on success
-	CreateEdge(ctx context.Context, user User, from, to string, weight float64) (string, error)
+	CreateEdge(ctx context.Context, user User, from, to string, weight float64, edgeType model.EdgeType) (string, error)
 	EditNode(ctx context.Context, user User, nodeID string, description *model.Text, resources *model.Text) error
+	// TranslateNode adds a translation in language, which the node must not
+	// have yet
+	TranslateNode(ctx context.Context, user User, nodeID, language, description string, resources *string) error
+	// RemoveTranslation removes the description and resources in language
+	// from a node, or only the resources if resourcesOnly is set. The last
+	// description of a node can not be removed.
+	RemoveTranslation(ctx context.Context, user User, nodeID, language string, resourcesOnly bool) error
 	AddEdgeWeightVote(ctx context.Context, user User, edgeID string, weight float64) error
 	DeleteNode(ctx context.Context, user User, ID string) error
 	DeleteEdge(ctx context.Context, user User, ID string) error
 	NodeEdits(ctx context.Context, ID string) ([]*model.NodeEdit, error)
 	EdgeEdits(ctx context.Context, ID string) ([]*model.EdgeEdit, error)
+	Tags(ctx context.Context) ([]*model.Tag, error)
+	// returns ID of the created tag on success
+	CreateTag(ctx context.Context, user User, name *model.Text) (string, error)
+	AddNodeTag(ctx context.Context, user User, nodeID, tagID string) error
+	RemoveNodeTag(ctx context.Context, user User, nodeID, tagID string) error
+	NodeResources(ctx context.Context, nodeID string) ([]*model.Resource, error)
+	// returns ID of the created resource on success
+	CreateResource(ctx context.Context, user User, nodeID string, resource model.ResourceInput) (string, error)
+	EditResource(ctx context.Context, user User, ID string, resource model.ResourceInput) error
+	DeleteResource(ctx context.Context, user User, ID string) error
+	AddResourceVote(ctx context.Context, user User, ID string, value float64) error
+	ResourceEdits(ctx context.Context, ID string) ([]*model.ResourceEdit, error)
+	// Search returns at most limit nodes matching the full text query, if
+	// tags is non-empty only nodes with one of these tags are searched
+	Search(ctx context.Context, query, language string, limit int, tags []string) ([]*model.SearchResult, error)
+	// SimilarNodes returns at most limit nodes, which have a description with
+	// at least the similarity threshold ∈ [0,1] in one of the languages of
+	// description
+	SimilarNodes(ctx context.Context, description *model.Text, threshold float64, limit int) ([]*model.SimilarNode, error)
+	// TranslationStatus returns the translation coverage of language and
+	// the untranslated nodes in [offset, offset+limit)
+	TranslationStatus(ctx context.Context, language string, offset, limit int) (*model.TranslationStatus, error)
+	// NodeTexts returns description and resources of a node in all languages
+	NodeTexts(ctx context.Context, nodeID string) (description Text, resources Text, err error)
+	// SaveTranslationDraft creates or replaces the draft in draft.Language
+	SaveTranslationDraft(ctx context.Context, nodeID string, draft *model.TranslationDraft) error
+	TranslationDrafts(ctx context.Context, nodeID string) ([]*model.TranslationDraft, error)
+	// PromoteTranslationDraft adds the draft to the node like TranslateNode
+	// and removes the draft
+	PromoteTranslationDraft(ctx context.Context, user User, nodeID, language string) error
+}
+
+// LayoutDB persists the graph embedding, so that it survives restarts
+type LayoutDB interface {
+	// NodePositions returns the most recently saved layout, or nil if none
+	// was saved yet
+	NodePositions(ctx context.Context) (*Layout, error)
+	// SaveNodePositions replaces the saved layout
+	SaveNodePositions(ctx context.Context, layout *Layout) error
+	// NodePins returns node ID → position of all pinned nodes
+	NodePins(ctx context.Context) (map[string]model.Vector, error)
+	// PinNode fixes the position of a node in the graph embedding, or moves
+	// an existing pin
+	PinNode(ctx context.Context, user User, nodeID string, position model.Vector) error
+	// UnpinNode removes the pin of a node, it fails if the node is not pinned
+	UnpinNode(ctx context.Context, user User, nodeID string) error
 }
 
 type UserDB interface {
@@ -38,11 +96,17 @@ type UserDB interface {
 type DB interface {
 	UserDB
 	GraphDB
+	LayoutDB
 }
 
 type Config struct {
 	PGHost     string `env:"DB_POSTGRES_HOST" envDefault:"localhost"`
 	PGPassword string `env:"DB_POSTGRES_PASSWORD" envDefault:"example"`
+	// languages used for texts missing in all requested languages, in order
+	FallbackLanguages []string `env:"FALLBACK_LANGUAGES" envSeparator:"," envDefault:"en"`
+	// see LanguageNormalizer
+	CollapseRegionalVariants bool     `env:"LANGUAGE_COLLAPSE_REGIONAL_VARIANTS" envDefault:"true"`
+	KeepRegionalVariants     []string `env:"LANGUAGE_KEEP_REGIONAL_VARIANTS" envSeparator:","`
 }
 
 func GetEnvConfig() Config {
@@ -51,6 +115,13 @@ func GetEnvConfig() Config {
 	return conf
 }
 
+// Layout is a graph embedding, Version is incremented by every computation
+type Layout struct {
+	Version int
+	// node ID → position
+	Positions map[string]model.Vector
+}
+
 // TODO(skep): remove, was used for arangodb compatibility (which is no longer in use)
 type Document struct {
 	Key string `json:"_key,omitempty"`
@@ -74,8 +145,12 @@ type NodeEdit struct {
 type NodeEditType string
 
 const (
-	NodeEditTypeCreate NodeEditType = "create"
-	NodeEditTypeEdit   NodeEditType = "edit"
+	NodeEditTypeCreate            NodeEditType = "create"
+	NodeEditTypeEdit              NodeEditType = "edit"
+	NodeEditTypeAddTag            NodeEditType = "addTag"
+	NodeEditTypeRemoveTag         NodeEditType = "removeTag"
+	NodeEditTypeTranslate         NodeEditType = "translate"
+	NodeEditTypeRemoveTranslation NodeEditType = "removeTranslation"
 )
 
 type EdgeEdit struct {
@@ -94,6 +169,48 @@ const (
 	EdgeEditTypeVote   EdgeEditType = "edit"
 )
 
+type TagEditType string
+
+const (
+	TagEditTypeCreate TagEditType = "create"
+)
+
+type ResourceEditType string
+
+const (
+	ResourceEditTypeCreate ResourceEditType = "create"
+	ResourceEditTypeEdit   ResourceEditType = "edit"
+	ResourceEditTypeDelete ResourceEditType = "delete"
+	ResourceEditTypeVote   ResourceEditType = "vote"
+)
+
+type ResourceKind string
+
+const (
+	ResourceKindVideo    ResourceKind = "video"
+	ResourceKindArticle  ResourceKind = "article"
+	ResourceKindBook     ResourceKind = "book"
+	ResourceKindExercise ResourceKind = "exercise"
+)
+
+type ResourceDifficulty string
+
+const (
+	ResourceDifficultyBeginner     ResourceDifficulty = "beginner"
+	ResourceDifficultyIntermediate ResourceDifficulty = "intermediate"
+	ResourceDifficultyAdvanced     ResourceDifficulty = "advanced"
+)
+
+// EdgeType describes the semantics of an edge, only prerequisite edges define
+// an order in which topics should be learned.
+type EdgeType string
+
+const (
+	EdgeTypePrerequisite EdgeType = "prerequisite"
+	EdgeTypeRelatedTo    EdgeType = "relatedTo"
+	EdgeTypePartOf       EdgeType = "partOf"
+)
+
 type Edge struct {
 	Document
 	From   string  `json:"_from"`
@@ -110,6 +227,15 @@ type User struct {
 	Roles        []RoleType            `json:"roles,omitempty"`
 }
 
+func (u User) HasRole(role RoleType) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 type RoleType string
 
 const (
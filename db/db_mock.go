@@ -49,19 +49,47 @@ func (mr *MockDBMockRecorder) AddEdgeWeightVote(arg0, arg1, arg2, arg3 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEdgeWeightVote", reflect.TypeOf((*MockDB)(nil).AddEdgeWeightVote), arg0, arg1, arg2, arg3)
 }
 
+// AddNodeTag mocks base method.
+func (m *MockDB) AddNodeTag(arg0 context.Context, arg1 User, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNodeTag", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNodeTag indicates an expected call of AddNodeTag.
+func (mr *MockDBMockRecorder) AddNodeTag(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNodeTag", reflect.TypeOf((*MockDB)(nil).AddNodeTag), arg0, arg1, arg2, arg3)
+}
+
+// AddResourceVote mocks base method.
+func (m *MockDB) AddResourceVote(arg0 context.Context, arg1 User, arg2 string, arg3 float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddResourceVote", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddResourceVote indicates an expected call of AddResourceVote.
+func (mr *MockDBMockRecorder) AddResourceVote(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddResourceVote", reflect.TypeOf((*MockDB)(nil).AddResourceVote), arg0, arg1, arg2, arg3)
+}
+
 // CreateEdge mocks base method.
-func (m *MockDB) CreateEdge(arg0 context.Context, arg1 User, arg2, arg3 string, arg4 float64) (string, error) {
+func (m *MockDB) CreateEdge(arg0 context.Context, arg1 User, arg2, arg3 string, arg4 float64, arg5 model.EdgeType) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEdge", arg0, arg1, arg2, arg3, arg4)
+	ret := m.ctrl.Call(m, "CreateEdge", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateEdge indicates an expected call of CreateEdge.
-func (mr *MockDBMockRecorder) CreateEdge(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+func (mr *MockDBMockRecorder) CreateEdge(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEdge", reflect.TypeOf((*MockDB)(nil).CreateEdge), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEdge", reflect.TypeOf((*MockDB)(nil).CreateEdge), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // CreateNode mocks base method.
@@ -79,6 +107,36 @@ func (mr *MockDBMockRecorder) CreateNode(arg0, arg1, arg2, arg3 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNode", reflect.TypeOf((*MockDB)(nil).CreateNode), arg0, arg1, arg2, arg3)
 }
 
+// CreateResource mocks base method.
+func (m *MockDB) CreateResource(arg0 context.Context, arg1 User, arg2 string, arg3 model.ResourceInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateResource", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateResource indicates an expected call of CreateResource.
+func (mr *MockDBMockRecorder) CreateResource(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResource", reflect.TypeOf((*MockDB)(nil).CreateResource), arg0, arg1, arg2, arg3)
+}
+
+// CreateTag mocks base method.
+func (m *MockDB) CreateTag(arg0 context.Context, arg1 User, arg2 *model.Text) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockDBMockRecorder) CreateTag(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockDB)(nil).CreateTag), arg0, arg1, arg2)
+}
+
 // CreateUserWithEMail mocks base method.
 func (m *MockDB) CreateUserWithEMail(arg0 context.Context, arg1, arg2, arg3 string) (*model.CreateUserResult, error) {
 	m.ctrl.T.Helper()
@@ -136,6 +194,20 @@ func (mr *MockDBMockRecorder) DeleteNode(arg0, arg1, arg2 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockDB)(nil).DeleteNode), arg0, arg1, arg2)
 }
 
+// DeleteResource mocks base method.
+func (m *MockDB) DeleteResource(arg0 context.Context, arg1 User, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteResource", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteResource indicates an expected call of DeleteResource.
+func (mr *MockDBMockRecorder) DeleteResource(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteResource", reflect.TypeOf((*MockDB)(nil).DeleteResource), arg0, arg1, arg2)
+}
+
 // EdgeEdits mocks base method.
 func (m *MockDB) EdgeEdits(arg0 context.Context, arg1 string) ([]*model.EdgeEdit, error) {
 	m.ctrl.T.Helper()
@@ -151,6 +223,21 @@ func (mr *MockDBMockRecorder) EdgeEdits(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EdgeEdits", reflect.TypeOf((*MockDB)(nil).EdgeEdits), arg0, arg1)
 }
 
+// Edges mocks base method.
+func (m *MockDB) Edges(arg0 context.Context) ([]*model.Edge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Edges", arg0)
+	ret0, _ := ret[0].([]*model.Edge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Edges indicates an expected call of Edges.
+func (mr *MockDBMockRecorder) Edges(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edges", reflect.TypeOf((*MockDB)(nil).Edges), arg0)
+}
+
 // EditNode mocks base method.
 func (m *MockDB) EditNode(arg0 context.Context, arg1 User, arg2 string, arg3, arg4 *model.Text) error {
 	m.ctrl.T.Helper()
@@ -165,6 +252,20 @@ func (mr *MockDBMockRecorder) EditNode(arg0, arg1, arg2, arg3, arg4 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditNode", reflect.TypeOf((*MockDB)(nil).EditNode), arg0, arg1, arg2, arg3, arg4)
 }
 
+// EditResource mocks base method.
+func (m *MockDB) EditResource(arg0 context.Context, arg1 User, arg2 string, arg3 model.ResourceInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditResource", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditResource indicates an expected call of EditResource.
+func (mr *MockDBMockRecorder) EditResource(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditResource", reflect.TypeOf((*MockDB)(nil).EditResource), arg0, arg1, arg2, arg3)
+}
+
 // Graph mocks base method.
 func (m *MockDB) Graph(arg0 context.Context) (*model.Graph, error) {
 	m.ctrl.T.Helper()
@@ -254,3 +355,281 @@ func (mr *MockDBMockRecorder) NodeEdits(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeEdits", reflect.TypeOf((*MockDB)(nil).NodeEdits), arg0, arg1)
 }
+
+// NodePins mocks base method.
+func (m *MockDB) NodePins(arg0 context.Context) (map[string]model.Vector, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodePins", arg0)
+	ret0, _ := ret[0].(map[string]model.Vector)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodePins indicates an expected call of NodePins.
+func (mr *MockDBMockRecorder) NodePins(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodePins", reflect.TypeOf((*MockDB)(nil).NodePins), arg0)
+}
+
+// NodePositions mocks base method.
+func (m *MockDB) NodePositions(arg0 context.Context) (*Layout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodePositions", arg0)
+	ret0, _ := ret[0].(*Layout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodePositions indicates an expected call of NodePositions.
+func (mr *MockDBMockRecorder) NodePositions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodePositions", reflect.TypeOf((*MockDB)(nil).NodePositions), arg0)
+}
+
+// NodeResources mocks base method.
+func (m *MockDB) NodeResources(arg0 context.Context, arg1 string) ([]*model.Resource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeResources", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Resource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeResources indicates an expected call of NodeResources.
+func (mr *MockDBMockRecorder) NodeResources(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeResources", reflect.TypeOf((*MockDB)(nil).NodeResources), arg0, arg1)
+}
+
+// NodeTexts mocks base method.
+func (m *MockDB) NodeTexts(arg0 context.Context, arg1 string) (Text, Text, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeTexts", arg0, arg1)
+	ret0, _ := ret[0].(Text)
+	ret1, _ := ret[1].(Text)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// NodeTexts indicates an expected call of NodeTexts.
+func (mr *MockDBMockRecorder) NodeTexts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeTexts", reflect.TypeOf((*MockDB)(nil).NodeTexts), arg0, arg1)
+}
+
+// PinNode mocks base method.
+func (m *MockDB) PinNode(arg0 context.Context, arg1 User, arg2 string, arg3 model.Vector) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinNode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinNode indicates an expected call of PinNode.
+func (mr *MockDBMockRecorder) PinNode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinNode", reflect.TypeOf((*MockDB)(nil).PinNode), arg0, arg1, arg2, arg3)
+}
+
+// PromoteTranslationDraft mocks base method.
+func (m *MockDB) PromoteTranslationDraft(arg0 context.Context, arg1 User, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteTranslationDraft", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PromoteTranslationDraft indicates an expected call of PromoteTranslationDraft.
+func (mr *MockDBMockRecorder) PromoteTranslationDraft(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteTranslationDraft", reflect.TypeOf((*MockDB)(nil).PromoteTranslationDraft), arg0, arg1, arg2, arg3)
+}
+
+// RemoveNodeTag mocks base method.
+func (m *MockDB) RemoveNodeTag(arg0 context.Context, arg1 User, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveNodeTag", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveNodeTag indicates an expected call of RemoveNodeTag.
+func (mr *MockDBMockRecorder) RemoveNodeTag(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveNodeTag", reflect.TypeOf((*MockDB)(nil).RemoveNodeTag), arg0, arg1, arg2, arg3)
+}
+
+// RemoveTranslation mocks base method.
+func (m *MockDB) RemoveTranslation(arg0 context.Context, arg1 User, arg2, arg3 string, arg4 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTranslation", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTranslation indicates an expected call of RemoveTranslation.
+func (mr *MockDBMockRecorder) RemoveTranslation(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTranslation", reflect.TypeOf((*MockDB)(nil).RemoveTranslation), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ResourceEdits mocks base method.
+func (m *MockDB) ResourceEdits(arg0 context.Context, arg1 string) ([]*model.ResourceEdit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceEdits", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ResourceEdit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResourceEdits indicates an expected call of ResourceEdits.
+func (mr *MockDBMockRecorder) ResourceEdits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceEdits", reflect.TypeOf((*MockDB)(nil).ResourceEdits), arg0, arg1)
+}
+
+// SaveNodePositions mocks base method.
+func (m *MockDB) SaveNodePositions(arg0 context.Context, arg1 *Layout) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNodePositions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNodePositions indicates an expected call of SaveNodePositions.
+func (mr *MockDBMockRecorder) SaveNodePositions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNodePositions", reflect.TypeOf((*MockDB)(nil).SaveNodePositions), arg0, arg1)
+}
+
+// SaveTranslationDraft mocks base method.
+func (m *MockDB) SaveTranslationDraft(arg0 context.Context, arg1 string, arg2 *model.TranslationDraft) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTranslationDraft", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTranslationDraft indicates an expected call of SaveTranslationDraft.
+func (mr *MockDBMockRecorder) SaveTranslationDraft(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTranslationDraft", reflect.TypeOf((*MockDB)(nil).SaveTranslationDraft), arg0, arg1, arg2)
+}
+
+// Search mocks base method.
+func (m *MockDB) Search(arg0 context.Context, arg1, arg2 string, arg3 int, arg4 []string) ([]*model.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*model.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockDBMockRecorder) Search(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockDB)(nil).Search), arg0, arg1, arg2, arg3, arg4)
+}
+
+// SimilarNodes mocks base method.
+func (m *MockDB) SimilarNodes(arg0 context.Context, arg1 *model.Text, arg2 float64, arg3 int) ([]*model.SimilarNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimilarNodes", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*model.SimilarNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimilarNodes indicates an expected call of SimilarNodes.
+func (mr *MockDBMockRecorder) SimilarNodes(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilarNodes", reflect.TypeOf((*MockDB)(nil).SimilarNodes), arg0, arg1, arg2, arg3)
+}
+
+// Subgraph mocks base method.
+func (m *MockDB) Subgraph(arg0 context.Context, arg1 []string) (*model.Graph, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subgraph", arg0, arg1)
+	ret0, _ := ret[0].(*model.Graph)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subgraph indicates an expected call of Subgraph.
+func (mr *MockDBMockRecorder) Subgraph(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subgraph", reflect.TypeOf((*MockDB)(nil).Subgraph), arg0, arg1)
+}
+
+// Tags mocks base method.
+func (m *MockDB) Tags(arg0 context.Context) ([]*model.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tags", arg0)
+	ret0, _ := ret[0].([]*model.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tags indicates an expected call of Tags.
+func (mr *MockDBMockRecorder) Tags(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tags", reflect.TypeOf((*MockDB)(nil).Tags), arg0)
+}
+
+// TranslateNode mocks base method.
+func (m *MockDB) TranslateNode(arg0 context.Context, arg1 User, arg2, arg3, arg4 string, arg5 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TranslateNode", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TranslateNode indicates an expected call of TranslateNode.
+func (mr *MockDBMockRecorder) TranslateNode(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslateNode", reflect.TypeOf((*MockDB)(nil).TranslateNode), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// TranslationDrafts mocks base method.
+func (m *MockDB) TranslationDrafts(arg0 context.Context, arg1 string) ([]*model.TranslationDraft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TranslationDrafts", arg0, arg1)
+	ret0, _ := ret[0].([]*model.TranslationDraft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TranslationDrafts indicates an expected call of TranslationDrafts.
+func (mr *MockDBMockRecorder) TranslationDrafts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslationDrafts", reflect.TypeOf((*MockDB)(nil).TranslationDrafts), arg0, arg1)
+}
+
+// TranslationStatus mocks base method.
+func (m *MockDB) TranslationStatus(arg0 context.Context, arg1 string, arg2, arg3 int) (*model.TranslationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TranslationStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*model.TranslationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TranslationStatus indicates an expected call of TranslationStatus.
+func (mr *MockDBMockRecorder) TranslationStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslationStatus", reflect.TypeOf((*MockDB)(nil).TranslationStatus), arg0, arg1, arg2, arg3)
+}
+
+// UnpinNode mocks base method.
+func (m *MockDB) UnpinNode(arg0 context.Context, arg1 User, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpinNode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpinNode indicates an expected call of UnpinNode.
+func (mr *MockDBMockRecorder) UnpinNode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpinNode", reflect.TypeOf((*MockDB)(nil).UnpinNode), arg0, arg1, arg2)
+}
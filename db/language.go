@@ -0,0 +1,97 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"golang.org/x/text/language"
+)
+
+var ErrInvalidLanguage = errors.New("invalid language code")
+
+// LanguageNormalizer validates language codes as BCP-47 tags and converts
+// them into their canonical form, e.g. "EN" → "en", "en_us" → "en-US".
+type LanguageNormalizer struct {
+	// collapse regional variants (and scripts) to the base language, e.g.
+	// "de-CH" → "de"
+	CollapseRegionalVariants bool
+	// regional variants kept even if CollapseRegionalVariants is set, in
+	// canonical form, e.g. "zh-TW"
+	KeepRegionalVariants []string
+}
+
+func NewLanguageNormalizer(conf Config) LanguageNormalizer {
+	return LanguageNormalizer{
+		CollapseRegionalVariants: conf.CollapseRegionalVariants,
+		KeepRegionalVariants:     conf.KeepRegionalVariants,
+	}
+}
+
+// Normalize returns the canonical form of code, variants and extensions are
+// dropped
+func (n LanguageNormalizer) Normalize(code string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil {
+		return "", errors.Wrapf(ErrInvalidLanguage, "'%s': %v", code, err)
+	}
+	base, confidence := tag.Base()
+	if confidence != language.Exact {
+		return "", errors.Wrapf(ErrInvalidLanguage, "'%s': no language given", code)
+	}
+	parts := []interface{}{base}
+	if script, confidence := tag.Script(); confidence == language.Exact {
+		parts = append(parts, script)
+	}
+	if region, confidence := tag.Region(); confidence == language.Exact {
+		parts = append(parts, region)
+	}
+	canonical, err := language.Compose(parts...)
+	if err != nil {
+		return "", errors.Wrapf(ErrInvalidLanguage, "'%s': %v", code, err)
+	}
+	if n.CollapseRegionalVariants && !Contains(n.KeepRegionalVariants, canonical.String()) {
+		return base.String(), nil
+	}
+	return canonical.String(), nil
+}
+
+// NormalizeList normalizes a list of preferred languages, e.g. of an
+// 'Accept-Language' header: invalid languages and duplicates after
+// normalization are dropped, the order is kept.
+func (n LanguageNormalizer) NormalizeList(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		lang, err := n.Normalize(code)
+		if err != nil || Contains(normalized, lang) {
+			continue
+		}
+		normalized = append(normalized, lang)
+	}
+	return normalized
+}
+
+// NormalizeText normalizes the languages of all translations, an error is
+// returned if any language is invalid or occurs twice after normalization.
+func (n LanguageNormalizer) NormalizeText(text *model.Text) (*model.Text, error) {
+	if text == nil {
+		return nil, nil
+	}
+	normalized := &model.Text{Translations: make([]*model.Translation, 0, len(text.Translations))}
+	seen := map[string]bool{}
+	for _, translation := range text.Translations {
+		if translation == nil {
+			continue
+		}
+		lang, err := n.Normalize(translation.Language)
+		if err != nil {
+			return nil, err
+		}
+		if seen[lang] {
+			return nil, errors.Wrapf(ErrInvalidLanguage, "'%s': language '%s' given twice", translation.Language, lang)
+		}
+		seen[lang] = true
+		normalized.Translations = append(normalized.Translations, &model.Translation{Language: lang, Content: translation.Content})
+	}
+	return normalized, nil
+}
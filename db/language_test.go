@@ -0,0 +1,78 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+func TestLanguageNormalizer_Normalize(t *testing.T) {
+	for _, test := range []struct {
+		Name       string
+		Normalizer LanguageNormalizer
+		Inp        string
+		Exp        string
+		ExpErr     bool
+	}{
+		{Name: "canonical", Inp: "en", Exp: "en"},
+		{Name: "upper case", Inp: "EN", Exp: "en"},
+		{Name: "whitespace", Inp: " de ", Exp: "de"},
+		{Name: "region", Inp: "en_us", Exp: "en-US"},
+		{Name: "script", Inp: "zh-hant", Exp: "zh-Hant"},
+		{Name: "extensions are dropped", Inp: "en-US-u-ca-gregory", Exp: "en-US"},
+		{Name: "three letter code", Inp: "deu", Exp: "de"},
+		{Name: "deprecated code", Inp: "iw", Exp: "he"},
+		{Name: "collapse region", Normalizer: LanguageNormalizer{CollapseRegionalVariants: true}, Inp: "de-CH", Exp: "de"},
+		{Name: "collapse script", Normalizer: LanguageNormalizer{CollapseRegionalVariants: true}, Inp: "zh-Hant", Exp: "zh"},
+		{
+			Name:       "keep configured regional variant",
+			Normalizer: LanguageNormalizer{CollapseRegionalVariants: true, KeepRegionalVariants: []string{"zh-TW"}},
+			Inp:        "zh_tw",
+			Exp:        "zh-TW",
+		},
+		{Name: "empty", Inp: "", ExpErr: true},
+		{Name: "undetermined", Inp: "und", ExpErr: true},
+		{Name: "unknown", Inp: "xx", ExpErr: true},
+		{Name: "language name", Inp: "english", ExpErr: true},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			lang, err := test.Normalizer.Normalize(test.Inp)
+			assert.Equal(t, test.Exp, lang)
+			if test.ExpErr {
+				assert.True(t, errors.Is(err, ErrInvalidLanguage), "expected ErrInvalidLanguage, got '%v'", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLanguageNormalizer_NormalizeList(t *testing.T) {
+	assert.Equal(t, []string{"de-CH", "de", "en"}, LanguageNormalizer{}.NormalizeList([]string{"de-ch", "de", "*", "EN", "en"}))
+	assert.Equal(t, []string{"de", "en"}, LanguageNormalizer{CollapseRegionalVariants: true}.NormalizeList([]string{"de-ch", "de", "en"}))
+	assert.Equal(t, []string{}, LanguageNormalizer{}.NormalizeList(nil))
+}
+
+func TestLanguageNormalizer_NormalizeText(t *testing.T) {
+	n := LanguageNormalizer{CollapseRegionalVariants: true}
+	text, err := n.NormalizeText(&model.Text{Translations: []*model.Translation{
+		{Language: "EN", Content: "a"}, nil, {Language: "de-AT", Content: "b"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Text{Translations: []*model.Translation{
+		{Language: "en", Content: "a"}, {Language: "de", Content: "b"},
+	}}, text)
+
+	_, err = n.NormalizeText(&model.Text{Translations: []*model.Translation{{Language: "", Content: "a"}}})
+	assert.Error(t, err, "empty language")
+	_, err = n.NormalizeText(&model.Text{Translations: []*model.Translation{
+		{Language: "de", Content: "a"}, {Language: "de-CH", Content: "b"},
+	}})
+	assert.Error(t, err, "same language twice")
+
+	text, err = n.NormalizeText(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, text)
+}
@@ -1,66 +1,127 @@
 package postgres
 
 import (
+	"sort"
+
 	"github.com/suxatcode/learn-graph-poc-backend/db"
 	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
 )
 
 type ConvertToModel struct {
-	language         string
-	fallbackLanguage string
+	// preferred languages, most preferred first
+	languages []string
+	// tried in order, if none of the preferred languages exist
+	fallbackLanguages []string
+	// node ID → drafts, used for preferred languages without translation
+	drafts map[uint][]TranslationDraft
 }
 
-const defaultFallbackLanguage = "en"
+var DefaultFallbackLanguages = []string{"en"}
 
-func NewConvertToModel(language string) *ConvertToModel {
+func NewConvertToModel(languages ...string) *ConvertToModel {
 	return &ConvertToModel{
-		language:         language,
-		fallbackLanguage: defaultFallbackLanguage,
+		languages:         languages,
+		fallbackLanguages: DefaultFallbackLanguages,
 	}
 }
 
-var LanguageToLanguageFlag = map[string]string{
-	"en": "🇺🇸",
-	"de": "🇩🇪",
-	"zh": "🇹🇼",
-	"es": "🇪🇸",
-	"fr": "🇫🇷",
-	"it": "🇮🇹",
-	"ja": "🇯🇵",
+// translationOrder returns the order in which the languages of text are
+// tried: preferred languages, then fallback languages, then all remaining
+// languages sorted alphabetically.
+func (c *ConvertToModel) translationOrder(text db.Text) []string {
+	order := make([]string, 0, len(c.languages)+len(c.fallbackLanguages)+len(text))
+	for _, list := range [][]string{c.languages, c.fallbackLanguages} {
+		for _, lang := range list {
+			if !db.Contains(order, lang) {
+				order = append(order, lang)
+			}
+		}
+	}
+	remaining := make([]string, 0, len(text))
+	for lang := range text {
+		if !db.Contains(order, lang) {
+			remaining = append(remaining, lang)
+		}
+	}
+	sort.Strings(remaining)
+	return append(order, remaining...)
 }
 
-func (c *ConvertToModel) getTranslationOrFallback(text db.Text) (string, bool) {
-	returnText, ok := text[c.language]
-	if !ok {
-		returnText, ok = text[c.fallbackLanguage]
-		returnText = LanguageToLanguageFlag[c.fallbackLanguage] + " " + returnText
+// getTranslationOrFallback returns nil, if text has no translations
+func (c *ConvertToModel) getTranslationOrFallback(text db.Text) *model.LocalizedString {
+	for _, lang := range c.translationOrder(text) {
+		returnText, ok := text[lang]
 		if !ok {
-			for firstExistingLanguage := range text {
-				returnText = text[firstExistingLanguage]
-				returnText = LanguageToLanguageFlag[firstExistingLanguage] + " " + returnText
-				break
+			continue
+		}
+		return &model.LocalizedString{
+			Text:       returnText,
+			Language:   lang,
+			IsFallback: !db.Contains(c.languages, lang),
+		}
+	}
+	return nil
+}
+
+// WithTranslationDrafts shows drafts in preferred languages, which have no
+// translation yet, instead of a fallback language
+func (c *ConvertToModel) WithTranslationDrafts(drafts []TranslationDraft) *ConvertToModel {
+	c.drafts = make(map[uint][]TranslationDraft, len(drafts))
+	for _, draft := range drafts {
+		c.drafts[draft.NodeID] = append(c.drafts[draft.NodeID], draft)
+	}
+	return c
+}
+
+func (c *ConvertToModel) getTranslationOrDraft(text db.Text, drafts []TranslationDraft, draftText func(TranslationDraft) *string) *model.LocalizedString {
+	for _, lang := range c.languages {
+		if _, ok := text[lang]; ok {
+			break
+		}
+		draft := db.FindFirst(drafts, func(d TranslationDraft) bool { return d.Language == lang && draftText(d) != nil })
+		if draft != nil {
+			return &model.LocalizedString{
+				Text:                 *draftText(*draft),
+				Language:             lang,
+				IsMachineTranslation: draft.Machine,
 			}
 		}
 	}
-	return returnText, ok
+	return c.getTranslationOrFallback(text)
 }
 
 func (c *ConvertToModel) Node(node Node) *model.Node {
 	if len(node.Description) == 0 {
 		return nil
 	}
-	description, _ := c.getTranslationOrFallback(node.Description)
+	drafts := c.drafts[node.ID]
 	res := model.Node{
 		ID:          itoa(node.ID),
-		Description: description,
+		Description: c.getTranslationOrDraft(node.Description, drafts, func(d TranslationDraft) *string { return &d.Description }),
+		Resources:   c.getTranslationOrDraft(node.Resources, drafts, func(d TranslationDraft) *string { return d.Resources }),
 	}
-	resources, ok := c.getTranslationOrFallback(node.Resources)
-	if ok {
-		res.Resources = &resources
+	if len(node.Tags) > 0 {
+		res.Tags = c.Tags(node.Tags)
 	}
 	return &res
 }
 
+func (c *ConvertToModel) Tag(tag Tag) *model.Tag {
+	modelTag := &model.Tag{ID: itoa(tag.ID)}
+	if name := c.getTranslationOrFallback(tag.Name); name != nil {
+		modelTag.Name = name.Text
+	}
+	return modelTag
+}
+
+func (c *ConvertToModel) Tags(tags []Tag) []*model.Tag {
+	modelTags := make([]*model.Tag, 0, len(tags))
+	for _, tag := range tags {
+		modelTags = append(modelTags, c.Tag(tag))
+	}
+	return modelTags
+}
+
 func (c *ConvertToModel) Graph(nodes []Node, edges []Edge) *model.Graph {
 	g := model.Graph{}
 	for _, n := range nodes {
@@ -76,6 +137,7 @@ func (c *ConvertToModel) Graph(nodes []Node, edges []Edge) *model.Graph {
 			From:   itoa(e.FromID),
 			To:     itoa(e.ToID),
 			Weight: e.Weight,
+			Type:   model.EdgeType(e.Type),
 		})
 	}
 	return &g
@@ -84,16 +146,20 @@ func (c *ConvertToModel) Graph(nodes []Node, edges []Edge) *model.Graph {
 func (c *ConvertToModel) NodeEdits(edits []NodeEdit) []*model.NodeEdit {
 	modelEdits := make([]*model.NodeEdit, 0, len(edits))
 	for _, edit := range edits {
-		newDescription, _ := c.getTranslationOrFallback(edit.NewDescription)
+		newDescription := c.getTranslationOrFallback(edit.NewDescription)
+		if newDescription == nil {
+			newDescription = &model.LocalizedString{}
+		}
 		modelEdit := model.NodeEdit{
 			Username:       edit.User.Username,
 			Type:           model.NodeEditType(edit.Type),
 			NewDescription: newDescription,
+			NewResources:   c.getTranslationOrFallback(edit.NewResources),
 			UpdatedAt:      edit.CreatedAt,
+			Language:       edit.Language,
 		}
-		newResrouces, ok := c.getTranslationOrFallback(edit.NewResources)
-		if ok {
-			modelEdit.NewResources = &newResrouces
+		if edit.Tag != nil {
+			modelEdit.Tag = c.Tag(*edit.Tag)
 		}
 		modelEdits = append(modelEdits, &modelEdit)
 	}
@@ -127,3 +193,63 @@ func ConvertToDBText(text *model.Text) db.Text {
 	}
 	return t
 }
+
+func (c *ConvertToModel) Resources(resources []Resource) []*model.Resource {
+	modelResources := make([]*model.Resource, 0, len(resources))
+	for _, resource := range resources {
+		modelResources = append(modelResources, &model.Resource{
+			ID:               itoa(resource.ID),
+			NodeID:           itoa(resource.NodeID),
+			URL:              resource.URL,
+			Title:            resource.Title,
+			Kind:             model.ResourceKind(resource.Kind),
+			Language:         resource.Language,
+			EstimatedMinutes: resource.EstimatedMinutes,
+			Difficulty:       convertResourceDifficulty(resource.Difficulty),
+			Usefulness:       resource.Usefulness,
+		})
+	}
+	return modelResources
+}
+
+func (c *ConvertToModel) ResourceEdits(edits []ResourceEdit) []*model.ResourceEdit {
+	modelEdits := make([]*model.ResourceEdit, 0, len(edits))
+	for _, edit := range edits {
+		modelEdits = append(modelEdits, &model.ResourceEdit{
+			Username:         edit.User.Username,
+			Type:             model.ResourceEditType(edit.Type),
+			UpdatedAt:        edit.CreatedAt,
+			URL:              edit.URL,
+			Title:            edit.Title,
+			Kind:             model.ResourceKind(edit.Kind),
+			Language:         edit.Language,
+			EstimatedMinutes: edit.EstimatedMinutes,
+			Difficulty:       convertResourceDifficulty(edit.Difficulty),
+			Vote:             edit.Vote,
+		})
+	}
+	return modelEdits
+}
+
+func convertResourceDifficulty(difficulty *db.ResourceDifficulty) *model.ResourceDifficulty {
+	if difficulty == nil {
+		return nil
+	}
+	d := model.ResourceDifficulty(*difficulty)
+	return &d
+}
+
+func (c *ConvertToModel) TranslationDrafts(drafts []TranslationDraft) []*model.TranslationDraft {
+	modelDrafts := make([]*model.TranslationDraft, 0, len(drafts))
+	for _, draft := range drafts {
+		modelDrafts = append(modelDrafts, &model.TranslationDraft{
+			Language:    draft.Language,
+			Description: draft.Description,
+			Resources:   draft.Resources,
+			Machine:     draft.Machine,
+			Provider:    draft.Provider,
+			UpdatedAt:   draft.UpdatedAt,
+		})
+	}
+	return modelDrafts
+}
@@ -23,7 +23,7 @@ func TestConvertToModelGraph(t *testing.T) {
 			Language: "en",
 			Exp: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "123", Description: "a"},
+					{ID: "123", Description: localized("a", "en")},
 				},
 			},
 		},
@@ -36,8 +36,8 @@ func TestConvertToModelGraph(t *testing.T) {
 			Language: "en",
 			Exp: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "123", Description: "a"},
-					{ID: "456", Description: "a"},
+					{ID: "123", Description: localized("a", "en")},
+					{ID: "456", Description: localized("a", "en")},
 				},
 			},
 		},
@@ -53,8 +53,8 @@ func TestConvertToModelGraph(t *testing.T) {
 			Language: "en",
 			Exp: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "1", Description: "a"},
-					{ID: "2", Description: "b"},
+					{ID: "1", Description: localized("a", "en")},
+					{ID: "2", Description: localized("b", "en")},
 				},
 				Edges: []*model.Edge{
 					{ID: "3", From: "1", To: "2"},
@@ -67,7 +67,7 @@ func TestConvertToModelGraph(t *testing.T) {
 			Language: "ch",
 			Exp: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "1", Description: "🇺🇸 ok"},
+					{ID: "1", Description: localizedFallback("ok", "en")},
 				},
 			},
 		},
@@ -83,7 +83,7 @@ func TestConvertToModelGraph(t *testing.T) {
 			Language: "en",
 			Exp: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "1", Description: "🇹🇼 打坐"},
+					{ID: "1", Description: localizedFallback("打坐", "zh")},
 				},
 			},
 		},
@@ -93,7 +93,19 @@ func TestConvertToModelGraph(t *testing.T) {
 			Language: "zh",
 			Exp: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "1", Description: "打坐", Resources: strptr("🇺🇸 A")},
+					{ID: "1", Description: localized("打坐", "zh"), Resources: localizedFallback("A", "en")},
+				},
+			},
+		},
+		{
+			Name: "single node with tags",
+			InpV: []Node{{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "a"}, Tags: []Tag{
+				{Model: gorm.Model{ID: 7}, Name: db.Text{"en": "Mathematics", "de": "Mathematik"}},
+			}}},
+			Language: "de",
+			Exp: &model.Graph{
+				Nodes: []*model.Node{
+					{ID: "1", Description: localizedFallback("a", "en"), Tags: []*model.Tag{{ID: "7", Name: "Mathematik"}}},
 				},
 			},
 		},
@@ -104,6 +116,107 @@ func TestConvertToModelGraph(t *testing.T) {
 	}
 }
 
+func TestConvertToModel_getTranslationOrFallback(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Languages []string
+		Fallback  []string
+		Text      db.Text
+		Exp       *model.LocalizedString
+	}{
+		{
+			Name:      "first preferred language",
+			Languages: []string{"de", "en"},
+			Text:      db.Text{"en": "a", "de": "b"},
+			Exp:       localized("b", "de"),
+		},
+		{
+			Name:      "second preferred language is no fallback",
+			Languages: []string{"fr", "de"},
+			Text:      db.Text{"en": "a", "de": "b"},
+			Exp:       localized("b", "de"),
+		},
+		{
+			Name:      "fallback languages in order",
+			Languages: []string{"fr"},
+			Fallback:  []string{"it", "de", "en"},
+			Text:      db.Text{"en": "a", "de": "b"},
+			Exp:       localizedFallback("b", "de"),
+		},
+		{
+			Name:      "remaining languages alphabetically",
+			Languages: []string{"fr"},
+			Fallback:  []string{"en"},
+			Text:      db.Text{"zh": "a", "ja": "b", "it": "c"},
+			Exp:       localizedFallback("c", "it"),
+		},
+		{
+			Name:      "empty text",
+			Languages: []string{"fr"},
+			Text:      db.Text{},
+			Exp:       nil,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			c := NewConvertToModel(test.Languages...)
+			if test.Fallback != nil {
+				c.fallbackLanguages = test.Fallback
+			}
+			assert.Equal(t, test.Exp, c.getTranslationOrFallback(test.Text))
+		})
+	}
+}
+
+func TestConvertToModel_WithTranslationDrafts(t *testing.T) {
+	drafts := []TranslationDraft{
+		{NodeID: 1, Language: "de", Description: "Algebra (de)", Resources: strptr("ein Buch"), Machine: true},
+		{NodeID: 1, Language: "fr", Description: "Algèbre", Machine: true},
+	}
+	for _, test := range []struct {
+		Name      string
+		Languages []string
+		Node      Node
+		Exp       *model.Node
+	}{
+		{
+			Name:      "draft in preferred language instead of fallback",
+			Languages: []string{"de"},
+			Node:      Node{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "Algebra"}, Resources: db.Text{"en": "a book"}},
+			Exp: &model.Node{
+				ID:          "1",
+				Description: &model.LocalizedString{Text: "Algebra (de)", Language: "de", IsMachineTranslation: true},
+				Resources:   &model.LocalizedString{Text: "ein Buch", Language: "de", IsMachineTranslation: true},
+			},
+		},
+		{
+			Name:      "translation preferred over draft",
+			Languages: []string{"en", "de"},
+			Node:      Node{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "Algebra"}, Resources: db.Text{"en": "a book"}},
+			Exp:       &model.Node{ID: "1", Description: localized("Algebra", "en"), Resources: localized("a book", "en")},
+		},
+		{
+			Name:      "draft without resources",
+			Languages: []string{"fr"},
+			Node:      Node{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "Algebra"}, Resources: db.Text{"en": "a book"}},
+			Exp: &model.Node{
+				ID:          "1",
+				Description: &model.LocalizedString{Text: "Algèbre", Language: "fr", IsMachineTranslation: true},
+				Resources:   localizedFallback("a book", "en"),
+			},
+		},
+		{
+			Name:      "drafts of other nodes are ignored",
+			Languages: []string{"de"},
+			Node:      Node{Model: gorm.Model{ID: 2}, Description: db.Text{"en": "Geometry"}},
+			Exp:       &model.Node{ID: "2", Description: localizedFallback("Geometry", "en")},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Exp, NewConvertToModel(test.Languages...).WithTranslationDrafts(drafts).Node(test.Node))
+		})
+	}
+}
+
 func TestConvertToDBText(t *testing.T) {
 	for _, test := range []struct {
 		Name string
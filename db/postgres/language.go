@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"gorm.io/gorm"
+)
+
+// normalizeTextLanguages returns text with normalized languages and the keys,
+// which are no valid language. Invalid keys are looked up in aliases (lower
+// case, e.g. "english" → "en"), and are kept unchanged otherwise unless
+// dropInvalid is set. If several keys normalize to the same language, the
+// value of the already normalized key wins, otherwise the alphabetically
+// first key.
+func normalizeTextLanguages(n db.LanguageNormalizer, text db.Text, aliases map[string]string, dropInvalid bool) (db.Text, []string) {
+	if text == nil {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(text))
+	for key := range text {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	normalized := make(db.Text, len(text))
+	invalid := []string{}
+	for _, key := range keys {
+		lang, err := n.Normalize(key)
+		if err != nil {
+			alias, ok := aliases[strings.ToLower(strings.TrimSpace(key))]
+			if !ok {
+				invalid = append(invalid, key)
+				if !dropInvalid {
+					normalized[key] = text[key]
+				}
+				continue
+			}
+			lang = alias
+		}
+		if _, exists := normalized[lang]; exists && key != lang {
+			continue
+		}
+		normalized[lang] = text[key]
+	}
+	return normalized, invalid
+}
+
+type NormalizeLanguagesResult struct {
+	// number of nodes with changed description or resources
+	ChangedNodes int
+	// node ID → keys, which are no valid language
+	InvalidLanguages map[uint][]string
+}
+
+// NormalizeLanguages normalizes the languages of the descriptions and
+// resources of all nodes (including deleted ones), see
+// normalizeTextLanguages. Node edits are history and are left unchanged.
+// Nothing is changed, if dropInvalid would leave a node without description.
+func (pg *PostgresDB) NormalizeLanguages(ctx context.Context, aliases map[string]string, dropInvalid bool) (*NormalizeLanguagesResult, error) {
+	normalizedAliases := make(map[string]string, len(aliases))
+	for name, code := range aliases {
+		lang, err := pg.languages.Normalize(code)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid alias '%s'", name)
+		}
+		normalizedAliases[strings.ToLower(name)] = lang
+	}
+	res := &NormalizeLanguagesResult{InvalidLanguages: map[uint][]string{}}
+	err := pg.db.Transaction(func(tx *gorm.DB) error {
+		nodes := []Node{}
+		if err := tx.Unscoped().Find(&nodes).Error; err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			description, invalidDescription := normalizeTextLanguages(pg.languages, node.Description, normalizedAliases, dropInvalid)
+			resources, invalidResources := normalizeTextLanguages(pg.languages, node.Resources, normalizedAliases, dropInvalid)
+			if invalid := append(invalidDescription, invalidResources...); len(invalid) > 0 {
+				res.InvalidLanguages[node.ID] = invalid
+			}
+			if len(description) == 0 && len(node.Description) > 0 {
+				return errors.Errorf("node %d has no description in a valid language: %q", node.ID, invalidDescription)
+			}
+			if equalText(description, node.Description) && equalText(resources, node.Resources) {
+				continue
+			}
+			update := map[string]interface{}{"description": description, "resources": resources}
+			if err := tx.Unscoped().Model(&Node{}).Where("id = ?", node.ID).Updates(update).Error; err != nil {
+				return err
+			}
+			res.ChangedNodes++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize languages")
+	}
+	return res, nil
+}
+
+func equalText(a, b db.Text) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+)
+
+func TestNormalizeTextLanguages(t *testing.T) {
+	n := db.LanguageNormalizer{CollapseRegionalVariants: true}
+	aliases := map[string]string{"english": "en", "german": "de"}
+	for _, test := range []struct {
+		Name        string
+		Inp         db.Text
+		DropInvalid bool
+		Exp         db.Text
+		ExpInvalid  []string
+	}{
+		{Name: "nil", Inp: nil, Exp: nil},
+		{Name: "already normalized", Inp: db.Text{"en": "a", "de": "b"}, Exp: db.Text{"en": "a", "de": "b"}, ExpInvalid: []string{}},
+		{Name: "case and region", Inp: db.Text{"EN": "a", "de-CH": "b"}, Exp: db.Text{"en": "a", "de": "b"}, ExpInvalid: []string{}},
+		{Name: "alias", Inp: db.Text{"English": "a"}, Exp: db.Text{"en": "a"}, ExpInvalid: []string{}},
+		{Name: "normalized key wins", Inp: db.Text{"EN": "a", "en": "b", "en-US": "c"}, Exp: db.Text{"en": "b"}, ExpInvalid: []string{}},
+		{Name: "alphabetically first key wins", Inp: db.Text{"en-US": "a", "EN": "b"}, Exp: db.Text{"en": "b"}, ExpInvalid: []string{}},
+		{Name: "invalid keys are kept", Inp: db.Text{"": "a", "xx": "b", "de": "c"}, Exp: db.Text{"": "a", "xx": "b", "de": "c"}, ExpInvalid: []string{"", "xx"}},
+		{Name: "invalid keys are dropped", Inp: db.Text{"": "a", "de": "c"}, DropInvalid: true, Exp: db.Text{"de": "c"}, ExpInvalid: []string{""}},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			text, invalid := normalizeTextLanguages(n, test.Inp, aliases, test.DropInvalid)
+			assert.Equal(t, test.Exp, text)
+			assert.Equal(t, test.ExpInvalid, invalid)
+		})
+	}
+}
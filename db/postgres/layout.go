@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (pg *PostgresDB) NodePositions(ctx context.Context) (*db.Layout, error) {
+	positions := []NodePosition{}
+	if err := pg.db.Find(&positions).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to fetch node positions")
+	}
+	if len(positions) == 0 {
+		return nil, nil
+	}
+	layout := &db.Layout{Positions: make(map[string]model.Vector, len(positions))}
+	for _, pos := range positions {
+		if pos.Version > layout.Version {
+			layout.Version = pos.Version
+		}
+		layout.Positions[itoa(pos.NodeID)] = model.Vector{X: pos.X, Y: pos.Y, Z: pos.Z}
+	}
+	return layout, nil
+}
+
+func (pg *PostgresDB) SaveNodePositions(ctx context.Context, layout *db.Layout) error {
+	err := pg.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&NodePosition{}).Error; err != nil {
+			return err
+		}
+		// nodes may have been removed since the layout was computed
+		nodeIDs := []uint{}
+		if err := tx.Unscoped().Model(&Node{}).Pluck("id", &nodeIDs).Error; err != nil {
+			return err
+		}
+		positions := make([]NodePosition, 0, len(layout.Positions))
+		for _, id := range nodeIDs {
+			if pos, ok := layout.Positions[itoa(id)]; ok {
+				positions = append(positions, NodePosition{NodeID: id, Version: layout.Version, X: pos.X, Y: pos.Y, Z: pos.Z})
+			}
+		}
+		if len(positions) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&positions, 1000).Error
+	})
+	return errors.Wrap(err, "failed to save node positions")
+}
+
+func (pg *PostgresDB) NodePins(ctx context.Context) (map[string]model.Vector, error) {
+	pins := []NodePin{}
+	if err := pg.db.Find(&pins).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to fetch node pins")
+	}
+	res := make(map[string]model.Vector, len(pins))
+	for _, pin := range pins {
+		res[itoa(pin.NodeID)] = model.Vector{X: pin.X, Y: pin.Y, Z: pin.Z}
+	}
+	return res, nil
+}
+
+func (pg *PostgresDB) PinNode(ctx context.Context, user db.User, nodeID string, position model.Vector) error {
+	err := pg.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Node{}, atoi(nodeID)).Error; err != nil {
+			return err
+		}
+		pin := NodePin{NodeID: atoi(nodeID), UserID: atoi(user.Key), X: position.X, Y: position.Y, Z: position.Z}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "node_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "x", "y", "z", "updated_at"}),
+		}).Create(&pin).Error
+	})
+	return errors.Wrapf(err, "failed to pin node '%s'", nodeID)
+}
+
+func (pg *PostgresDB) UnpinNode(ctx context.Context, user db.User, nodeID string) error {
+	res := pg.db.Where("node_id = ?", atoi(nodeID)).Delete(&NodePin{})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "failed to unpin node '%s'", nodeID)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Errorf("node '%s' is not pinned", nodeID)
+	}
+	return nil
+}
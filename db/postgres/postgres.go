@@ -31,6 +31,7 @@ type Node struct {
 	gorm.Model
 	Description db.Text `gorm:"type:jsonb;default:'{}';not null"`
 	Resources   db.Text `gorm:"type:jsonb"`
+	Tags        []Tag   `gorm:"many2many:node_tags;constraint:OnDelete:CASCADE"`
 }
 type NodeEdit struct {
 	gorm.Model
@@ -41,6 +42,38 @@ type NodeEdit struct {
 	Type           db.NodeEditType `gorm:"type:text;not null"`
 	NewDescription db.Text         `gorm:"type:jsonb;default:'{}';not null"`
 	NewResources   db.Text         `gorm:"type:jsonb"`
+	// only set for NodeEditTypeAddTag and NodeEditTypeRemoveTag
+	TagID *uint
+	Tag   *Tag `gorm:"constraint:OnDelete:SET NULL"`
+	// only set for NodeEditTypeTranslate
+	Language *string
+}
+
+// TranslationDraft is an unreviewed translation of a node. Drafts are
+// deleted (not soft-deleted) once promoted or superseded by a translation.
+type TranslationDraft struct {
+	gorm.Model
+	NodeID      uint   `gorm:"uniqueIndex:idx_translation_drafts_node_language"`
+	Node        Node   `gorm:"constraint:OnDelete:CASCADE;not null"`
+	Language    string `gorm:"uniqueIndex:idx_translation_drafts_node_language;not null"`
+	Description string `gorm:"not null"`
+	Resources   *string
+	// created by a machine translation provider
+	Machine  bool `gorm:"not null"`
+	Provider string
+}
+type Tag struct {
+	gorm.Model
+	Name db.Text `gorm:"type:jsonb;default:'{}';not null"`
+}
+type TagEdit struct {
+	gorm.Model
+	TagID   uint
+	Tag     Tag `gorm:"constraint:OnDelete:CASCADE;not null"`
+	UserID  uint
+	User    User           `gorm:"constraint:OnDelete:SET DEFAULT;not null"`
+	Type    db.TagEditType `gorm:"type:text;not null"`
+	NewName db.Text        `gorm:"type:jsonb;default:'{}';not null"`
 }
 type Edge struct {
 	gorm.Model
@@ -49,6 +82,7 @@ type Edge struct {
 	From   Node `gorm:"constraint:OnDelete:CASCADE;not null"`
 	To     Node `gorm:"constraint:OnDelete:CASCADE;not null"`
 	Weight float64
+	Type   db.EdgeType `gorm:"type:text;default:'prerequisite';not null"`
 }
 type EdgeEdit struct {
 	gorm.Model
@@ -59,6 +93,35 @@ type EdgeEdit struct {
 	Type   db.EdgeEditType `gorm:"type:text;not null"`
 	Weight float64
 }
+
+// ResourceData holds the user editable fields of a Resource
+type ResourceData struct {
+	URL              string          `gorm:"not null"`
+	Title            string          `gorm:"not null"`
+	Kind             db.ResourceKind `gorm:"type:text;not null"`
+	Language         string          `gorm:"not null"`
+	EstimatedMinutes *int
+	Difficulty       *db.ResourceDifficulty `gorm:"type:text"`
+}
+type Resource struct {
+	gorm.Model
+	NodeID uint
+	Node   Node `gorm:"constraint:OnDelete:CASCADE;not null"`
+	ResourceData
+	// average of the most recent vote of each user
+	Usefulness float64
+}
+type ResourceEdit struct {
+	gorm.Model
+	ResourceID uint
+	Resource   Resource `gorm:"constraint:OnDelete:CASCADE;not null"`
+	UserID     uint
+	User       User                `gorm:"constraint:OnDelete:SET DEFAULT;not null"`
+	Type       db.ResourceEditType `gorm:"type:text;not null"`
+	ResourceData
+	// only set for ResourceEditTypeVote
+	Vote *float64
+}
 type User struct {
 	gorm.Model
 	Username     string                `gorm:"not null;unique;"`
@@ -79,6 +142,27 @@ type Role struct {
 	Role   db.RoleType `gorm:"index:noDuplicateRolesPerUser,unique;type:text;not null"`
 }
 
+// NodePosition is the position of a node in the most recently saved graph
+// embedding, see db.LayoutDB
+type NodePosition struct {
+	NodeID    uint `gorm:"primaryKey;autoIncrement:false"`
+	Node      Node `gorm:"constraint:OnDelete:CASCADE"`
+	Version   int  `gorm:"not null"`
+	X, Y, Z   float64
+	UpdatedAt time.Time
+}
+
+// NodePin fixes the position of a node in the graph embedding, see
+// db.LayoutDB
+type NodePin struct {
+	NodeID uint `gorm:"primaryKey;autoIncrement:false"`
+	Node   Node `gorm:"constraint:OnDelete:CASCADE"`
+	// UserID is the admin who pinned the node
+	UserID    uint
+	X, Y, Z   float64
+	UpdatedAt time.Time
+}
+
 func makeStringToken() string {
 	rnd := make([]byte, AUTH_TOKEN_LENGTH)
 	n, err := rand.Read(rnd)
@@ -91,6 +175,7 @@ func makeStringToken() string {
 }
 
 func NewPostgresDB(conf db.Config) (db.DB, error) {
+	languages := db.NewLanguageNormalizer(conf)
 	pgConfig := postgres.Config{
 		DSN: fmt.Sprintf("host=%s user=learngraph password=%s dbname=learngraph port=5432 sslmode=disable", conf.PGHost, conf.PGPassword),
 		// Note: we must disable caching when running migrations, while clients are active,
@@ -102,24 +187,57 @@ func NewPostgresDB(conf db.Config) (db.DB, error) {
 		return nil, errors.Wrapf(err, "authentication with DSN: '%v' failed", pgConfig.DSN)
 	}
 	pg := &PostgresDB{
-		db:       db,
-		timeNow:  time.Now,
-		newToken: makeStringToken,
+		db:                db,
+		timeNow:           time.Now,
+		newToken:          makeStringToken,
+		fallbackLanguages: conf.FallbackLanguages,
+		languages:         languages,
 	}
 	return pg.init()
 }
 
 // implements db.DB
 type PostgresDB struct {
-	db       *gorm.DB
-	timeNow  func() time.Time
-	newToken func() string
+	db                *gorm.DB
+	timeNow           func() time.Time
+	newToken          func() string
+	fallbackLanguages []string
+	languages         db.LanguageNormalizer
+}
+
+// convertToDBText normalizes the languages of text, see db.LanguageNormalizer
+func (pg *PostgresDB) convertToDBText(text *model.Text) (db.Text, error) {
+	normalized, err := pg.languages.NormalizeText(text)
+	if err != nil {
+		return nil, err
+	}
+	return db.ConvertToDBText(normalized), nil
+}
+
+// convertToModel returns a converter for the languages requested in ctx, in
+// normalized form to match the stored languages
+func (pg *PostgresDB) convertToModel(ctx context.Context) *ConvertToModel {
+	convert := NewConvertToModel(pg.languages.NormalizeList(middleware.CtxGetLanguages(ctx))...)
+	if len(pg.fallbackLanguages) > 0 {
+		convert.fallbackLanguages = pg.fallbackLanguages
+	}
+	return convert
 }
 
 func (pg *PostgresDB) init() (db.DB, error) {
-	return pg, pg.db.AutoMigrate(
+	if err := pg.db.AutoMigrate(
 		&Node{}, &Edge{}, &NodeEdit{}, &EdgeEdit{}, &AuthenticationToken{}, &User{}, &Role{},
-	)
+		&Tag{}, &TagEdit{}, &Resource{}, &ResourceEdit{}, &TranslationDraft{},
+		&NodePosition{}, &NodePin{},
+	); err != nil {
+		return pg, err
+	}
+	for _, stmt := range append(searchMigrations(), similarMigrations()...) {
+		if err := pg.db.Exec(stmt).Error; err != nil {
+			return pg, errors.Wrap(err, "failed to create search indexes")
+		}
+	}
+	return pg, nil
 }
 
 func removeArangoPrefix(s string) string {
@@ -136,6 +254,14 @@ func (pg *PostgresDB) ReplaceAllDataWith(ctx context.Context, data db.AllData) e
 	err := pg.db.Transaction(func(tx *gorm.DB) error {
 		for _, stmt := range []string{
 			`DROP TABLE IF EXISTS authentication_tokens CASCADE`,
+			`DROP TABLE IF EXISTS translation_drafts CASCADE`,
+			`DROP TABLE IF EXISTS node_positions CASCADE`,
+			`DROP TABLE IF EXISTS node_pins CASCADE`,
+			`DROP TABLE IF EXISTS resource_edits CASCADE`,
+			`DROP TABLE IF EXISTS resources CASCADE`,
+			`DROP TABLE IF EXISTS tag_edits CASCADE`,
+			`DROP TABLE IF EXISTS node_tags CASCADE`,
+			`DROP TABLE IF EXISTS tags CASCADE`,
 			`DROP TABLE IF EXISTS users CASCADE`,
 			`DROP TABLE IF EXISTS edge_edits CASCADE`,
 			`DROP TABLE IF EXISTS edges CASCADE`,
@@ -243,7 +369,7 @@ func (pg *PostgresDB) Graph(ctx context.Context) (*model.Graph, error) {
 		edges []Edge
 	)
 	err := pg.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Find(&nodes).Error; err != nil {
+		if err := tx.Preload("Tags").Find(&nodes).Error; err != nil {
 			return err
 		}
 		if err := tx.Find(&edges).Error; err != nil {
@@ -254,23 +380,79 @@ func (pg *PostgresDB) Graph(ctx context.Context) (*model.Graph, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read graph")
 	}
-	lang := middleware.CtxGetLanguage(ctx)
-	graph := NewConvertToModel(lang).Graph(nodes, edges)
+	nodeIDs := make([]uint, 0, len(nodes))
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	drafts, err := pg.translationDrafts(ctx, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	graph := pg.convertToModel(ctx).WithTranslationDrafts(drafts).Graph(nodes, edges)
 	return graph, nil
 }
 
+func (pg *PostgresDB) Subgraph(ctx context.Context, nodeIDs []string) (*model.Graph, error) {
+	ids := make([]uint, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		ids = append(ids, atoi(id))
+	}
+	var (
+		nodes []Node
+		edges []Edge
+	)
+	if len(ids) > 0 {
+		err := pg.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Preload("Tags").Find(&nodes, ids).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("from_id IN ? AND to_id IN ?", ids, ids).Find(&edges).Error; err != nil {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read subgraph")
+		}
+	}
+	drafts, err := pg.translationDrafts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pg.convertToModel(ctx).WithTranslationDrafts(drafts).Graph(nodes, edges), nil
+}
+
+func (pg *PostgresDB) Edges(ctx context.Context) ([]*model.Edge, error) {
+	edges := []Edge{}
+	if err := pg.db.Find(&edges).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to read edges")
+	}
+	return pg.convertToModel(ctx).Graph(nil, edges).Edges, nil
+}
+
 func (pg *PostgresDB) Node(ctx context.Context, ID string) (*model.Node, error) {
 	node := Node{}
 	if err := pg.db.First(&node).Error; err != nil {
 		return nil, err
 	}
-	lang := middleware.CtxGetLanguage(ctx)
-	return NewConvertToModel(lang).Node(node), nil
+	drafts, err := pg.translationDrafts(ctx, []uint{node.ID})
+	if err != nil {
+		return nil, err
+	}
+	return pg.convertToModel(ctx).WithTranslationDrafts(drafts).Node(node), nil
 }
 
 func (pg *PostgresDB) CreateNode(ctx context.Context, user db.User, description, resources *model.Text) (string, error) {
-	node := Node{Description: db.ConvertToDBText(description), Resources: db.ConvertToDBText(resources)}
-	err := pg.db.Transaction(func(tx *gorm.DB) error {
+	descriptionText, err := pg.convertToDBText(description)
+	if err != nil {
+		return "", err
+	}
+	resourcesText, err := pg.convertToDBText(resources)
+	if err != nil {
+		return "", err
+	}
+	node := Node{Description: descriptionText, Resources: resourcesText}
+	err = pg.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&node).Error; err != nil {
 			return err
 		}
@@ -288,11 +470,12 @@ func (pg *PostgresDB) CreateNode(ctx context.Context, user db.User, description,
 	})
 	return itoa(node.ID), err
 }
-func (pg *PostgresDB) CreateEdge(ctx context.Context, user db.User, from, to string, weight float64) (string, error) {
+func (pg *PostgresDB) CreateEdge(ctx context.Context, user db.User, from, to string, weight float64, edgeType model.EdgeType) (string, error) {
 	edge := Edge{
 		FromID: atoi(from),
 		ToID:   atoi(to),
 		Weight: weight,
+		Type:   db.EdgeType(edgeType),
 	}
 	err := pg.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&edge).Error; err != nil {
@@ -312,13 +495,21 @@ func (pg *PostgresDB) CreateEdge(ctx context.Context, user db.User, from, to str
 	return itoa(edge.ID), err
 }
 func (pg *PostgresDB) EditNode(ctx context.Context, user db.User, nodeID string, description, resources *model.Text) error {
+	descriptionText, err := pg.convertToDBText(description)
+	if err != nil {
+		return err
+	}
+	resourcesText, err := pg.convertToDBText(resources)
+	if err != nil {
+		return err
+	}
 	return pg.db.Transaction(func(tx *gorm.DB) error {
 		node := Node{Model: gorm.Model{ID: atoi(nodeID)}}
 		if err := tx.First(&node).Error; err != nil {
 			return err
 		}
-		node.Description = mergeText(node.Description, db.ConvertToDBText(description))
-		node.Resources = mergeText(node.Resources, db.ConvertToDBText(resources))
+		node.Description = mergeText(node.Description, descriptionText)
+		node.Resources = mergeText(node.Resources, resourcesText)
 		if err := tx.Save(&node).Error; err != nil {
 			return err
 		}
@@ -473,13 +664,16 @@ func makeIsValidTokenFn(pg *PostgresDB, token string) func(at AuthenticationToke
 func (pg *PostgresDB) IsUserAuthenticated(ctx context.Context) (bool, *db.User, error) {
 	token := middleware.CtxGetAuthentication(ctx)
 	user := User{Model: gorm.Model{ID: atoi(middleware.CtxGetUserID(ctx))}}
-	if err := pg.db.Where(&user).Preload("Tokens").First(&user).Error; err != nil {
+	if err := pg.db.Where(&user).Preload("Tokens").Preload("Roles").First(&user).Error; err != nil {
 		return false, nil, nil // no such user
 	}
 	if db.FindFirst(user.Tokens, makeIsValidTokenFn(pg, token)) == nil {
 		return false, nil, nil
 	}
 	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}, Username: user.Username, EMail: user.EMail}
+	for _, role := range user.Roles {
+		dbUser.Roles = append(dbUser.Roles, role.Role)
+	}
 	return true, &dbUser, nil
 }
 
@@ -604,15 +798,14 @@ func (pg *PostgresDB) DeleteAccount(ctx context.Context) error {
 
 func (pg *PostgresDB) NodeEdits(ctx context.Context, ID string) ([]*model.NodeEdit, error) {
 	edits := []NodeEdit{}
-	err := pg.db.Where("node_id = ?", ID).Preload("User").Find(&edits).Error
+	err := pg.db.Where("node_id = ?", ID).Preload("User").Preload("Tag").Find(&edits).Error
 	if len(edits) == 0 {
 		return nil, errors.Errorf("nodeedit for node.id='%s' does not exist", ID)
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query edits")
 	}
-	lang := middleware.CtxGetLanguage(ctx)
-	return NewConvertToModel(lang).NodeEdits(edits), nil
+	return pg.convertToModel(ctx).NodeEdits(edits), nil
 }
 
 func (pg *PostgresDB) EdgeEdits(ctx context.Context, ID string) ([]*model.EdgeEdit, error) {
@@ -623,6 +816,267 @@ func (pg *PostgresDB) EdgeEdits(ctx context.Context, ID string) ([]*model.EdgeEd
 	if len(edits) == 0 {
 		return nil, errors.Errorf("edge with id='%s' does not exist", ID)
 	}
-	lang := middleware.CtxGetLanguage(ctx)
-	return NewConvertToModel(lang).EdgeEdits(edits), nil
+	return pg.convertToModel(ctx).EdgeEdits(edits), nil
+}
+
+func (pg *PostgresDB) Tags(ctx context.Context) ([]*model.Tag, error) {
+	tags := []Tag{}
+	if err := pg.db.Find(&tags).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to query tags")
+	}
+	return pg.convertToModel(ctx).Tags(tags), nil
+}
+
+func (pg *PostgresDB) CreateTag(ctx context.Context, user db.User, name *model.Text) (string, error) {
+	nameText, err := pg.convertToDBText(name)
+	if err != nil {
+		return "", err
+	}
+	tag := Tag{Name: nameText}
+	if len(tag.Name) == 0 {
+		return "", errors.New("tag name must not be empty")
+	}
+	err = pg.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&tag).Error; err != nil {
+			return err
+		}
+		tagedit := TagEdit{
+			TagID:   tag.ID,
+			UserID:  atoi(user.Key),
+			Type:    db.TagEditTypeCreate,
+			NewName: tag.Name,
+		}
+		return tx.Create(&tagedit).Error
+	})
+	return itoa(tag.ID), err
+}
+
+func (pg *PostgresDB) AddNodeTag(ctx context.Context, user db.User, nodeID, tagID string) error {
+	return pg.changeNodeTag(user, nodeID, tagID, db.NodeEditTypeAddTag)
+}
+
+func (pg *PostgresDB) RemoveNodeTag(ctx context.Context, user db.User, nodeID, tagID string) error {
+	return pg.changeNodeTag(user, nodeID, tagID, db.NodeEditTypeRemoveTag)
+}
+
+// changeNodeTag adds or removes the tag from the node depending on editType
+// and records the change as NodeEdit
+func (pg *PostgresDB) changeNodeTag(user db.User, nodeID, tagID string, editType db.NodeEditType) error {
+	if err := pg.db.Transaction(func(tx *gorm.DB) error {
+		node := Node{Model: gorm.Model{ID: atoi(nodeID)}}
+		if err := tx.First(&node).Error; err != nil {
+			return err
+		}
+		tag := Tag{Model: gorm.Model{ID: atoi(tagID)}}
+		if err := tx.First(&tag).Error; err != nil {
+			return err
+		}
+		association := tx.Model(&node).Association("Tags")
+		taggedAssociation := tx.Model(&node).Where("tags.id = ?", tag.ID).Association("Tags")
+		tagged := taggedAssociation.Count()
+		if taggedAssociation.Error != nil {
+			return taggedAssociation.Error
+		}
+		if editType == db.NodeEditTypeAddTag && tagged > 0 {
+			return errors.Errorf("node %s already has tag %s", nodeID, tagID)
+		}
+		if editType == db.NodeEditTypeRemoveTag && tagged == 0 {
+			return errors.Errorf("node %s does not have tag %s", nodeID, tagID)
+		}
+		if editType == db.NodeEditTypeAddTag {
+			if err := association.Append(&tag); err != nil {
+				return err
+			}
+		} else {
+			if err := association.Delete(&tag); err != nil {
+				return err
+			}
+		}
+		nodeedit := NodeEdit{
+			NodeID:         node.ID,
+			UserID:         atoi(user.Key),
+			Type:           editType,
+			NewDescription: node.Description,
+			NewResources:   node.Resources,
+			TagID:          &tag.ID,
+		}
+		return tx.Create(&nodeedit).Error
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+	return nil
+}
+
+// newResourceData validates the user input and converts it to ResourceData,
+// the language is normalized
+func (pg *PostgresDB) newResourceData(input model.ResourceInput) (ResourceData, error) {
+	data := ResourceData{
+		URL:              strings.TrimSpace(input.URL),
+		Title:            strings.TrimSpace(input.Title),
+		Kind:             db.ResourceKind(input.Kind),
+		Language:         input.Language,
+		EstimatedMinutes: input.EstimatedMinutes,
+	}
+	if input.Difficulty != nil {
+		difficulty := db.ResourceDifficulty(*input.Difficulty)
+		data.Difficulty = &difficulty
+	}
+	if !isValidResourceURL(data.URL) {
+		return data, errors.Errorf("invalid resource URL: '%s'", data.URL)
+	}
+	if data.Title == "" {
+		return data, errors.New("resource title must not be empty")
+	}
+	if data.Language == "" {
+		return data, errors.New("resource language must not be empty")
+	}
+	language, err := pg.languages.Normalize(data.Language)
+	if err != nil {
+		return data, err
+	}
+	data.Language = language
+	if data.EstimatedMinutes != nil && *data.EstimatedMinutes < 0 {
+		return data, errors.New("estimated time of a resource must not be negative")
+	}
+	return data, nil
+}
+
+func (pg *PostgresDB) NodeResources(ctx context.Context, nodeID string) ([]*model.Resource, error) {
+	resources := []Resource{}
+	if err := pg.db.Where("node_id = ?", nodeID).Order("usefulness DESC, id").Find(&resources).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to query resources")
+	}
+	return pg.convertToModel(ctx).Resources(resources), nil
+}
+
+func (pg *PostgresDB) CreateResource(ctx context.Context, user db.User, nodeID string, input model.ResourceInput) (string, error) {
+	data, err := pg.newResourceData(input)
+	if err != nil {
+		return "", err
+	}
+	resource := Resource{NodeID: atoi(nodeID), ResourceData: data}
+	err = pg.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Node{}, atoi(nodeID)).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&resource).Error; err != nil {
+			return err
+		}
+		resourceedit := ResourceEdit{
+			ResourceID:   resource.ID,
+			UserID:       atoi(user.Key),
+			Type:         db.ResourceEditTypeCreate,
+			ResourceData: resource.ResourceData,
+		}
+		return tx.Create(&resourceedit).Error
+	})
+	return itoa(resource.ID), err
+}
+
+func (pg *PostgresDB) EditResource(ctx context.Context, user db.User, ID string, input model.ResourceInput) error {
+	data, err := pg.newResourceData(input)
+	if err != nil {
+		return err
+	}
+	return pg.db.Transaction(func(tx *gorm.DB) error {
+		resource := Resource{Model: gorm.Model{ID: atoi(ID)}}
+		if err := tx.First(&resource).Error; err != nil {
+			return err
+		}
+		resource.ResourceData = data
+		if err := tx.Save(&resource).Error; err != nil {
+			return err
+		}
+		resourceedit := ResourceEdit{
+			ResourceID:   resource.ID,
+			UserID:       atoi(user.Key),
+			Type:         db.ResourceEditTypeEdit,
+			ResourceData: resource.ResourceData,
+		}
+		return tx.Create(&resourceedit).Error
+	})
+}
+
+func (pg *PostgresDB) DeleteResource(ctx context.Context, user db.User, ID string) error {
+	if err := pg.db.Transaction(func(tx *gorm.DB) error {
+		var edits int64
+		if err := tx.Model(&ResourceEdit{}).Where("resource_id = ? AND user_id != ?", ID, user.Key).Count(&edits).Error; err != nil {
+			return err
+		}
+		isAdmin, err := isUserAdmin(tx, user.Key)
+		if err != nil {
+			return err
+		}
+		if edits >= 1 && !isAdmin {
+			return errors.New("resource has edits from other users, won't delete")
+		}
+		resource := Resource{Model: gorm.Model{ID: atoi(ID)}}
+		if err := tx.First(&resource).Error; err != nil {
+			return err
+		}
+		resourceedit := ResourceEdit{
+			ResourceID:   resource.ID,
+			UserID:       atoi(user.Key),
+			Type:         db.ResourceEditTypeDelete,
+			ResourceData: resource.ResourceData,
+		}
+		if err := tx.Create(&resourceedit).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&resource).Error
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+	return nil
+}
+
+func (pg *PostgresDB) AddResourceVote(ctx context.Context, user db.User, ID string, value float64) error {
+	if err := db.ValidateVote(value); err != nil {
+		return err
+	}
+	return pg.db.Transaction(func(tx *gorm.DB) error {
+		resource := Resource{Model: gorm.Model{ID: atoi(ID)}}
+		if err := tx.First(&resource).Error; err != nil {
+			return err
+		}
+		resourceedit := ResourceEdit{
+			ResourceID:   resource.ID,
+			UserID:       atoi(user.Key),
+			Type:         db.ResourceEditTypeVote,
+			ResourceData: resource.ResourceData,
+			Vote:         &value,
+		}
+		if err := tx.Create(&resourceedit).Error; err != nil {
+			return err
+		}
+		votes := []ResourceEdit{}
+		query := `
+        WITH RankedVotes AS (
+            SELECT *,
+                -- Assign rank to each vote per user, most recent first
+                ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC) as rownumber
+            FROM resource_edits
+            WHERE resource_id = ? AND type = ?
+        )
+        -- Select only the most recent vote for each user (i.e. rownumber 1)
+        SELECT * FROM RankedVotes WHERE rownumber = 1;
+        `
+		if err := tx.Raw(query, resource.ID, db.ResourceEditTypeVote).Scan(&votes).Error; err != nil {
+			return err
+		}
+		sum := db.Sum(votes, func(edit ResourceEdit) float64 { return *edit.Vote })
+		resource.Usefulness = sum / float64(len(votes))
+		return tx.Save(&resource).Error
+	})
+}
+
+func (pg *PostgresDB) ResourceEdits(ctx context.Context, ID string) ([]*model.ResourceEdit, error) {
+	edits := []ResourceEdit{}
+	if err := pg.db.Where("resource_id = ?", ID).Preload("User").Order("id").Find(&edits).Error; err != nil {
+		return nil, err
+	}
+	if len(edits) == 0 {
+		return nil, errors.Errorf("resource with id='%s' does not exist", ID)
+	}
+	return pg.convertToModel(ctx).ResourceEdits(edits), nil
 }
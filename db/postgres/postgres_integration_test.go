@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/suxatcode/learn-graph-poc-backend/db"
 	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
@@ -163,7 +164,7 @@ func TestPostgresDB_CreateEdge(t *testing.T) {
 			user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
 			assert.NoError(pg.db.Create(&user).Error)
 			// call it
-			id, err := pg.CreateEdge(ctx, db.User{Document: db.Document{Key: itoa(user.ID)}}, fmt.Sprint(A.ID), fmt.Sprint(B.ID), 3.141)
+			id, err := pg.CreateEdge(ctx, db.User{Document: db.Document{Key: itoa(user.ID)}}, fmt.Sprint(A.ID), fmt.Sprint(B.ID), 3.141, model.EdgeTypePartOf)
 			if test.EdgeExists {
 				assert.Error(err)
 				return
@@ -178,6 +179,7 @@ func TestPostgresDB_CreateEdge(t *testing.T) {
 			assert.Equal(3.141, edges[0].Weight)
 			assert.Equal(A.ID, edges[0].FromID)
 			assert.Equal(B.ID, edges[0].ToID)
+			assert.Equal(db.EdgeTypePartOf, edges[0].Type)
 			edgeedits := []EdgeEdit{}
 			assert.NoError(pg.db.Find(&edgeedits).Error)
 			assert.Len(edgeedits, 1)
@@ -370,16 +372,16 @@ func TestPostgresDB_Graph(t *testing.T) {
 			},
 			Edges: []Edge{
 				{Model: gorm.Model{ID: 3}, FromID: 1, ToID: 2, Weight: 5.0},
-				{Model: gorm.Model{ID: 4}, FromID: 2, ToID: 1, Weight: 6.0},
+				{Model: gorm.Model{ID: 4}, FromID: 2, ToID: 1, Weight: 6.0, Type: db.EdgeTypeRelatedTo},
 			},
 			ExpGraph: &model.Graph{
 				Nodes: []*model.Node{
-					{ID: "1", Description: "A"},
-					{ID: "2", Description: "B"},
+					{ID: "1", Description: localized("A", "en")},
+					{ID: "2", Description: localized("B", "en")},
 				},
 				Edges: []*model.Edge{
-					{ID: "3", From: "1", To: "2", Weight: 5.0},
-					{ID: "4", From: "2", To: "1", Weight: 6.0},
+					{ID: "3", From: "1", To: "2", Weight: 5.0, Type: model.EdgeTypePrerequisite},
+					{ID: "4", From: "2", To: "1", Weight: 6.0, Type: model.EdgeTypeRelatedTo},
 				},
 			},
 		},
@@ -401,6 +403,45 @@ func TestPostgresDB_Graph(t *testing.T) {
 	}
 }
 
+func TestPostgresDB_Subgraph(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+	assert := assert.New(t)
+	for _, node := range []Node{
+		{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "A"}},
+		{Model: gorm.Model{ID: 2}, Description: db.Text{"en": "B"}},
+		{Model: gorm.Model{ID: 3}, Description: db.Text{"en": "C"}},
+	} {
+		assert.NoError(pg.db.Create(&node).Error)
+	}
+	for _, edge := range []Edge{
+		{Model: gorm.Model{ID: 4}, FromID: 1, ToID: 2, Weight: 5.0},
+		{Model: gorm.Model{ID: 5}, FromID: 2, ToID: 3, Weight: 6.0},
+	} {
+		assert.NoError(pg.db.Create(&edge).Error)
+	}
+	graph, err := pg.Subgraph(ctx, []string{"1", "2", "99"})
+	assert.NoError(err)
+	assert.Equal(&model.Graph{
+		Nodes: []*model.Node{
+			{ID: "1", Description: localized("A", "en")},
+			{ID: "2", Description: localized("B", "en")},
+		},
+		Edges: []*model.Edge{
+			{ID: "4", From: "1", To: "2", Weight: 5.0, Type: model.EdgeTypePrerequisite},
+		},
+	}, graph, "edge 5 leads outside")
+	graph, err = pg.Subgraph(ctx, []string{})
+	assert.NoError(err)
+	assert.Equal(&model.Graph{}, graph)
+	edges, err := pg.Edges(ctx)
+	assert.NoError(err)
+	assert.Equal([]*model.Edge{
+		{ID: "4", From: "1", To: "2", Weight: 5.0, Type: model.EdgeTypePrerequisite},
+		{ID: "5", From: "2", To: "3", Weight: 6.0, Type: model.EdgeTypePrerequisite},
+	}, edges)
+}
+
 func TestPostgresDB_Node(t *testing.T) {
 	for _, test := range []struct {
 		Name    string
@@ -412,14 +453,14 @@ func TestPostgresDB_Node(t *testing.T) {
 			Nodes: []Node{
 				{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "A"}},
 			},
-			ExpNode: &model.Node{ID: "1", Description: "A"},
+			ExpNode: &model.Node{ID: "1", Description: localized("A", "en")},
 		},
 		{
 			Name: "description & resources",
 			Nodes: []Node{
 				{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "A"}, Resources: db.Text{"en": "B"}},
 			},
-			ExpNode: &model.Node{ID: "1", Description: "A", Resources: strptr("B")},
+			ExpNode: &model.Node{ID: "1", Description: localized("A", "en"), Resources: localized("B", "en")},
 		},
 	} {
 		t.Run(test.Name, func(t *testing.T) {
@@ -436,6 +477,16 @@ func TestPostgresDB_Node(t *testing.T) {
 	}
 }
 
+func TestPostgresDB_Node_RequestLanguagesAreNormalized(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguages(context.Background(), []string{"de-ch", "DE"})
+	assert := assert.New(t)
+	assert.NoError(pg.db.Create(&Node{Model: gorm.Model{ID: 1}, Description: db.Text{"en": "A", "de-CH": "B"}}).Error)
+	node, err := pg.Node(ctx, "1")
+	assert.NoError(err)
+	assert.Equal(&model.Node{ID: "1", Description: localized("B", "de-CH")}, node)
+}
+
 const (
 	passwd1234 = "1234567890"
 	hash1234   = "$2a$10$H8fNtM7CQpT61P3UVy7mDeAjDDMfXakMVk/CyrNhlUUfGi2iRF9oK"
@@ -525,6 +576,19 @@ func TestPostgresDB_IsUserAuthenticated(t *testing.T) {
 			ExpOK:   true,
 			ExpUser: &db.User{Document: db.Document{Key: "5"}, Username: "aaaa", EMail: "a@b"},
 		},
+		{
+			Name:             "auth ok, admin",
+			ContextUserID:    "5",
+			ContextAuthToken: "XXX",
+			PreexistingUsers: []User{{
+				Model:    gorm.Model{ID: 5},
+				Username: "aaaa", PasswordHash: "123", EMail: "a@b",
+				Tokens: []AuthenticationToken{{Token: "XXX", Expiry: TEST_TimeNow.Add(1 * time.Hour)}},
+				Roles:  []Role{{Role: db.RoleAdmin}},
+			}},
+			ExpOK:   true,
+			ExpUser: &db.User{Document: db.Document{Key: "5"}, Username: "aaaa", EMail: "a@b", Roles: []db.RoleType{db.RoleAdmin}},
+		},
 		{
 			Name:             "no matching token found",
 			ContextUserID:    "5",
@@ -1097,7 +1161,7 @@ func TestPostgresDB_NodeEdits(t *testing.T) {
 				{NodeID: 2, UserID: 1, Type: db.NodeEditTypeCreate, NewDescription: db.Text{"en": "bb"}, NewResources: db.Text{"en": "QQ"}},
 			},
 			ExpEdits: []*model.NodeEdit{
-				{Username: "user1", Type: model.NodeEditTypeCreate, NewDescription: "aa", NewResources: strptr("RR")},
+				{Username: "user1", Type: model.NodeEditTypeCreate, NewDescription: localized("aa", "en"), NewResources: localized("RR", "en")},
 			},
 		},
 		{
@@ -1113,9 +1177,9 @@ func TestPostgresDB_NodeEdits(t *testing.T) {
 				{NodeID: 1, UserID: 2, NewDescription: db.Text{"en": "aaaa"}, Type: db.NodeEditTypeEdit},
 			},
 			ExpEdits: []*model.NodeEdit{
-				{Username: "user1", NewDescription: "aa", Type: model.NodeEditTypeCreate},
-				{Username: "user1", NewDescription: "aaa", Type: model.NodeEditTypeEdit},
-				{Username: "user2", NewDescription: "aaaa", Type: model.NodeEditTypeEdit},
+				{Username: "user1", NewDescription: localized("aa", "en"), Type: model.NodeEditTypeCreate},
+				{Username: "user1", NewDescription: localized("aaa", "en"), Type: model.NodeEditTypeEdit},
+				{Username: "user2", NewDescription: localized("aaaa", "en"), Type: model.NodeEditTypeEdit},
 			},
 		},
 		{
@@ -1291,3 +1355,539 @@ func TestPostgresDB_EdgeEdits(t *testing.T) {
 // 	})
 // }
 // }
+
+func TestPostgresDB_NodeTags(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "de")
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}}
+	node := Node{Description: db.Text{"en": "A"}}
+	assert.NoError(pg.db.Create(&node).Error)
+	tagID, err := pg.CreateTag(ctx, dbUser, &model.Text{Translations: []*model.Translation{
+		{Language: "en", Content: "Mathematics"},
+		{Language: "de", Content: "Mathematik"},
+	}})
+	if !assert.NoError(err) {
+		return
+	}
+	tagEdits := []TagEdit{}
+	assert.NoError(pg.db.Find(&tagEdits).Error)
+	assert.Len(tagEdits, 1)
+	tags, err := pg.Tags(ctx)
+	assert.NoError(err)
+	assert.Equal([]*model.Tag{{ID: tagID, Name: "Mathematik"}}, tags)
+
+	assert.NoError(pg.AddNodeTag(ctx, dbUser, itoa(node.ID), tagID))
+	assert.Error(pg.AddNodeTag(ctx, dbUser, itoa(node.ID), tagID), "tag already added")
+	graph, err := pg.Graph(ctx)
+	assert.NoError(err)
+	if assert.Len(graph.Nodes, 1) {
+		assert.Equal([]*model.Tag{{ID: tagID, Name: "Mathematik"}}, graph.Nodes[0].Tags)
+	}
+
+	assert.NoError(pg.RemoveNodeTag(ctx, dbUser, itoa(node.ID), tagID))
+	assert.Error(pg.RemoveNodeTag(ctx, dbUser, itoa(node.ID), tagID), "tag already removed")
+	graph, err = pg.Graph(ctx)
+	assert.NoError(err)
+	if assert.Len(graph.Nodes, 1) {
+		assert.Nil(graph.Nodes[0].Tags)
+	}
+	edits := []NodeEdit{}
+	assert.NoError(pg.db.Where("node_id = ?", node.ID).Order("id").Find(&edits).Error)
+	if assert.Len(edits, 2) {
+		assert.Equal(db.NodeEditTypeAddTag, edits[0].Type)
+		assert.Equal(db.NodeEditTypeRemoveTag, edits[1].Type)
+		assert.Equal(tagID, itoa(*edits[1].TagID))
+	}
+
+	assert.Error(pg.AddNodeTag(ctx, dbUser, itoa(node.ID), "999"), "non-existent tag")
+}
+
+func TestPostgresDB_Resources(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+	assert := assert.New(t)
+	users := []User{
+		{Username: "123", PasswordHash: "000", EMail: "a@b"},
+		{Username: "456", PasswordHash: "000", EMail: "c@d"},
+	}
+	assert.NoError(pg.db.Create(&users).Error)
+	user1 := db.User{Document: db.Document{Key: itoa(users[0].ID)}}
+	user2 := db.User{Document: db.Document{Key: itoa(users[1].ID)}}
+	node := Node{Description: db.Text{"en": "A"}}
+	assert.NoError(pg.db.Create(&node).Error)
+	minutes := 10
+	input := model.ResourceInput{URL: "https://example.com", Title: "Example", Kind: model.ResourceKindVideo, Language: "en", EstimatedMinutes: &minutes}
+
+	_, err := pg.CreateResource(ctx, user1, itoa(node.ID), model.ResourceInput{URL: "not a url", Title: "A", Kind: model.ResourceKindBook, Language: "en"})
+	assert.Error(err, "invalid URL")
+	_, err = pg.CreateResource(ctx, user1, "999", input)
+	assert.Error(err, "non-existent node")
+	_, err = pg.CreateResource(ctx, user1, itoa(node.ID), model.ResourceInput{URL: "https://example.com", Title: "A", Kind: model.ResourceKindBook, Language: "english"})
+	assert.Error(err, "invalid language")
+
+	input.Language = "EN"
+	id, err := pg.CreateResource(ctx, user1, itoa(node.ID), input)
+	if !assert.NoError(err) {
+		return
+	}
+	input.Title = "Better Title"
+	assert.NoError(pg.EditResource(ctx, user1, id, input))
+	assert.Error(pg.AddResourceVote(ctx, user1, id, 0), "vote below range")
+	assert.Error(pg.AddResourceVote(ctx, user1, id, 11), "vote above range")
+	assert.NoError(pg.AddResourceVote(ctx, user1, id, 2))
+	assert.NoError(pg.AddResourceVote(ctx, user2, id, 4))
+	assert.NoError(pg.AddResourceVote(ctx, user2, id, 3))
+	resources, err := pg.NodeResources(ctx, itoa(node.ID))
+	assert.NoError(err)
+	assert.Equal([]*model.Resource{{
+		ID:               id,
+		NodeID:           itoa(node.ID),
+		URL:              "https://example.com",
+		Title:            "Better Title",
+		Kind:             model.ResourceKindVideo,
+		Language:         "en",
+		EstimatedMinutes: &minutes,
+		Usefulness:       2.5, // = (2 + 3) / 2
+	}}, resources)
+
+	assert.Error(pg.DeleteResource(ctx, user1, id), "user2 voted on the resource")
+	assert.NoError(pg.db.Create(&Role{UserID: users[0].ID, Role: db.RoleAdmin}).Error)
+	assert.NoError(pg.DeleteResource(ctx, user1, id))
+	resources, err = pg.NodeResources(ctx, itoa(node.ID))
+	assert.NoError(err)
+	assert.Empty(resources)
+
+	edits, err := pg.ResourceEdits(ctx, id)
+	assert.NoError(err)
+	types := []model.ResourceEditType{}
+	for _, edit := range edits {
+		types = append(types, edit.Type)
+	}
+	assert.Equal([]model.ResourceEditType{
+		model.ResourceEditTypeCreate, model.ResourceEditTypeEdit,
+		model.ResourceEditTypeVote, model.ResourceEditTypeVote, model.ResourceEditTypeVote,
+		model.ResourceEditTypeDelete,
+	}, types)
+}
+
+func TestPostgresDB_MigrateFreeTextResources(t *testing.T) {
+	pg := setupDB(t)
+	ctx := context.Background()
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	nodeID, err := pg.CreateNode(ctx, db.User{Document: db.Document{Key: itoa(user.ID)}},
+		&model.Text{Translations: []*model.Translation{{Language: "en", Content: "A"}}},
+		&model.Text{Translations: []*model.Translation{
+			{Language: "en", Content: "[Video](https://youtu.be/abc) and https://example.com/read"},
+			{Language: "de", Content: "kein Link"},
+		}},
+	)
+	assert.NoError(err)
+	created, err := pg.MigrateFreeTextResources(ctx)
+	assert.NoError(err)
+	assert.Equal(2, created)
+	created, err = pg.MigrateFreeTextResources(ctx)
+	assert.NoError(err)
+	assert.Equal(0, created, "second run should not create duplicates")
+	resources := []Resource{}
+	assert.NoError(pg.db.Where("node_id = ?", nodeID).Order("id").Find(&resources).Error)
+	if assert.Len(resources, 2) {
+		assert.Equal(ResourceData{URL: "https://youtu.be/abc", Title: "Video", Kind: db.ResourceKindVideo, Language: "en"}, resources[0].ResourceData)
+		assert.Equal(ResourceData{URL: "https://example.com/read", Title: "https://example.com/read", Kind: db.ResourceKindArticle, Language: "en"}, resources[1].ResourceData)
+	}
+	edits := []ResourceEdit{}
+	assert.NoError(pg.db.Find(&edits).Error)
+	assert.Len(edits, 2)
+}
+
+func TestPostgresDB_Search(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "de")
+	assert := assert.New(t)
+	tag := Tag{Name: db.Text{"en": "Mathematics"}}
+	assert.NoError(pg.db.Create(&tag).Error)
+	nodes := []Node{
+		{Description: db.Text{"en": "Linear algebra", "de": "Lineare Algebra"}, Tags: []Tag{tag}},
+		{Description: db.Text{"en": "Abstract algebra"}},
+		{Description: db.Text{"en": "Cooking"}, Resources: db.Text{"en": "a book about algebraic recipes"}},
+		{Description: db.Text{"en": "Gardening"}},
+	}
+	assert.NoError(pg.db.Create(&nodes).Error)
+
+	results, err := pg.Search(ctx, "algebra", "de", 10, nil)
+	if !assert.NoError(err) {
+		return
+	}
+	ids := []string{}
+	for _, result := range results {
+		ids = append(ids, result.Node.ID)
+	}
+	assert.Equal([]string{itoa(nodes[0].ID), itoa(nodes[1].ID), itoa(nodes[2].ID)}, ids, "by rank and language preference")
+	assert.Equal("de", results[0].Language)
+	assert.Equal(localized("Lineare Algebra", "de"), results[0].Node.Description)
+	assert.Contains(results[0].Snippet, "<b>Algebra</b>")
+	assert.Equal("en", results[1].Language)
+
+	results, err = pg.Search(ctx, "algebra", "de", 1, nil)
+	assert.NoError(err)
+	assert.Len(results, 1)
+
+	results, err = pg.Search(ctx, "algebra", "DE", 1, nil)
+	assert.NoError(err)
+	if assert.Len(results, 1) {
+		assert.Equal("de", results[0].Language, "language is normalized")
+	}
+	_, err = pg.Search(ctx, "algebra", "german", 1, nil)
+	assert.Error(err, "invalid language")
+
+	results, err = pg.Search(ctx, "algebra", "en", 10, []string{itoa(tag.ID)})
+	assert.NoError(err)
+	if assert.Len(results, 1) {
+		assert.Equal(itoa(nodes[0].ID), results[0].Node.ID)
+	}
+
+	results, err = pg.Search(ctx, "  ", "en", 10, nil)
+	assert.NoError(err)
+	assert.Empty(results)
+}
+
+func TestPostgresDB_SimilarNodes(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+	assert := assert.New(t)
+	nodes := []Node{
+		{Description: db.Text{"en": "Linear algebra"}},
+		{Description: db.Text{"en": "Linear  Algebra I"}},
+		{Description: db.Text{"de": "Linear algebra"}},
+		{Description: db.Text{"en": "Gardening"}},
+	}
+	assert.NoError(pg.db.Create(&nodes).Error)
+
+	text := &model.Text{Translations: []*model.Translation{{Language: "en", Content: "linear algebra"}}}
+	similar, err := pg.SimilarNodes(ctx, text, 0.8, 10)
+	if !assert.NoError(err) {
+		return
+	}
+	if assert.Len(similar, 2) {
+		assert.Equal(itoa(nodes[0].ID), similar[0].Node.ID)
+		assert.Equal(1.0, similar[0].Similarity)
+		assert.Equal(itoa(nodes[1].ID), similar[1].Node.ID)
+	}
+
+	similar, err = pg.SimilarNodes(ctx, text, 0.8, 1)
+	assert.NoError(err)
+	assert.Len(similar, 1)
+
+	text = &model.Text{Translations: []*model.Translation{{Language: "EN", Content: "linear algebra"}}}
+	similar, err = pg.SimilarNodes(ctx, text, 0.8, 10)
+	assert.NoError(err)
+	assert.Len(similar, 2, "language is normalized")
+
+	text = &model.Text{Translations: []*model.Translation{{Language: "english", Content: "linear algebra"}}}
+	_, err = pg.SimilarNodes(ctx, text, 0.8, 10)
+	assert.Error(err, "invalid language")
+}
+
+func TestPostgresDB_TranslateNode(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "de")
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}}
+	node := Node{Description: db.Text{"en": "Algebra"}, Resources: db.Text{"en": "a book"}}
+	assert.NoError(pg.db.Create(&node).Error)
+
+	assert.NoError(pg.TranslateNode(ctx, dbUser, itoa(node.ID), "de", "Algebra (de)", strptr("ein Buch")))
+	assert.NoError(pg.db.First(&node, node.ID).Error)
+	assert.Equal(db.Text{"en": "Algebra", "de": "Algebra (de)"}, node.Description)
+	assert.Equal(db.Text{"en": "a book", "de": "ein Buch"}, node.Resources)
+	edits, err := pg.NodeEdits(ctx, itoa(node.ID))
+	assert.NoError(err)
+	if assert.Len(edits, 1) {
+		assert.Equal(model.NodeEditTypeTranslate, edits[0].Type)
+		assert.Equal(strptr("de"), edits[0].Language)
+		assert.Equal(localized("Algebra (de)", "de"), edits[0].NewDescription)
+	}
+
+	assert.Error(pg.TranslateNode(ctx, dbUser, itoa(node.ID), "de", "again", nil), "existing translation must not be overwritten")
+	assert.Error(pg.TranslateNode(ctx, dbUser, itoa(node.ID), "fr", "", nil), "empty description")
+	assert.Error(pg.TranslateNode(ctx, dbUser, "999", "fr", "Algèbre", nil), "no such node")
+}
+
+func TestPostgresDB_RemoveTranslation(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}}
+	node := Node{
+		Description: db.Text{"en": "Algebra", "de": "Algebra (de)", "english": "Algebra?"},
+		Resources:   db.Text{"en": "a book", "de": "ein Buch"},
+	}
+	assert.NoError(pg.db.Create(&node).Error)
+
+	assert.NoError(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "de", true))
+	assert.NoError(pg.db.First(&node, node.ID).Error)
+	assert.Equal(db.Text{"en": "Algebra", "de": "Algebra (de)", "english": "Algebra?"}, node.Description)
+	assert.Equal(db.Text{"en": "a book"}, node.Resources)
+	assert.Error(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "de", true), "no resources left in language")
+
+	assert.NoError(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "english", false), "invalid language removed by exact key")
+	assert.NoError(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "DE", false), "language is normalized")
+	assert.NoError(pg.db.First(&node, node.ID).Error)
+	assert.Equal(db.Text{"en": "Algebra"}, node.Description)
+	assert.Error(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "fr", false), "no such translation")
+	assert.Error(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "en", false), "last description")
+	assert.NoError(pg.RemoveTranslation(ctx, dbUser, itoa(node.ID), "en", true))
+	assert.NoError(pg.db.First(&node, node.ID).Error)
+	assert.Equal(db.Text{"en": "Algebra"}, node.Description)
+	assert.Nil(node.Resources)
+
+	edits, err := pg.NodeEdits(ctx, itoa(node.ID))
+	assert.NoError(err)
+	if assert.Len(edits, 4) {
+		for _, edit := range edits {
+			assert.Equal(model.NodeEditTypeRemoveTranslation, edit.Type)
+		}
+		assert.Equal(strptr("de"), edits[0].Language)
+		assert.Equal(strptr("en"), edits[3].Language)
+	}
+}
+
+func TestPostgresDB_TranslationStatus(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+	assert := assert.New(t)
+	nodes := []Node{
+		{Description: db.Text{"en": "A", "de": "A"}, Resources: db.Text{"en": "R", "de": "R"}},
+		{Description: db.Text{"en": "B"}},
+		{Description: db.Text{"en": "C"}},
+		{Description: db.Text{"en": "D", "de": "D"}, Resources: db.Text{"en": "R"}},
+		{Description: db.Text{"en": "E", "de": "E"}},
+	}
+	assert.NoError(pg.db.Create(&nodes).Error)
+	edges := []Edge{
+		{FromID: nodes[0].ID, ToID: nodes[2].ID, Weight: 5},
+		{FromID: nodes[1].ID, ToID: nodes[0].ID, Weight: 1},
+	}
+	assert.NoError(pg.db.Create(&edges).Error)
+
+	status, err := pg.TranslationStatus(ctx, "de", 0, 10)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("de", status.Language)
+	assert.Equal(5, status.NodeCount)
+	assert.Equal(60.0, status.DescriptionPercentage)
+	assert.Equal(20.0, status.ResourcesPercentage)
+	assert.Equal(3, status.UntranslatedNodeCount)
+	ids := []string{}
+	for _, node := range status.UntranslatedNodes {
+		ids = append(ids, node.ID)
+	}
+	assert.Equal([]string{itoa(nodes[2].ID), itoa(nodes[1].ID), itoa(nodes[3].ID)}, ids, "ordered by sum of edge weights")
+
+	status, err = pg.TranslationStatus(ctx, "de", 1, 1)
+	assert.NoError(err)
+	if assert.Len(status.UntranslatedNodes, 1) {
+		assert.Equal(itoa(nodes[1].ID), status.UntranslatedNodes[0].ID)
+	}
+	assert.Equal(3, status.UntranslatedNodeCount)
+
+	status, err = pg.TranslationStatus(ctx, "DE", 0, 10)
+	assert.NoError(err)
+	if assert.NotNil(status) {
+		assert.Equal("de", status.Language, "language is normalized")
+		assert.Equal(3, status.UntranslatedNodeCount)
+	}
+	_, err = pg.TranslationStatus(ctx, "german", 0, 10)
+	assert.Error(err, "invalid language")
+}
+
+func TestPostgresDB_TranslationDrafts(t *testing.T) {
+	pg := setupDB(t)
+	ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "de")
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}}
+	node := Node{Description: db.Text{"en": "Algebra"}}
+	assert.NoError(pg.db.Create(&node).Error)
+
+	description, resources, err := pg.NodeTexts(ctx, itoa(node.ID))
+	assert.NoError(err)
+	assert.Equal(db.Text{"en": "Algebra"}, description)
+	assert.Nil(resources)
+
+	draft := &model.TranslationDraft{Language: "de", Description: "Algebra?", Machine: true, Provider: "dictionary"}
+	assert.NoError(pg.SaveTranslationDraft(ctx, itoa(node.ID), draft))
+	draft.Description = "Algebra (de)"
+	assert.NoError(pg.SaveTranslationDraft(ctx, itoa(node.ID), draft), "draft is replaced")
+	assert.Error(pg.SaveTranslationDraft(ctx, itoa(node.ID), &model.TranslationDraft{Language: "en", Description: "x"}), "language exists")
+	drafts, err := pg.TranslationDrafts(ctx, itoa(node.ID))
+	assert.NoError(err)
+	if assert.Len(drafts, 1) {
+		assert.Equal("Algebra (de)", drafts[0].Description)
+		assert.True(drafts[0].Machine)
+	}
+
+	graph, err := pg.Graph(ctx)
+	assert.NoError(err)
+	assert.Equal(localizedFallback("Algebra", "en"), graph.Nodes[0].Description, "drafts are opt-in")
+	graph, err = pg.Graph(middleware.TestingCtxNewWithMachineTranslations(ctx))
+	assert.NoError(err)
+	assert.Equal(&model.LocalizedString{Text: "Algebra (de)", Language: "de", IsMachineTranslation: true}, graph.Nodes[0].Description)
+
+	assert.NoError(pg.PromoteTranslationDraft(ctx, dbUser, itoa(node.ID), "de"))
+	assert.NoError(pg.db.First(&node, node.ID).Error)
+	assert.Equal(db.Text{"en": "Algebra", "de": "Algebra (de)"}, node.Description)
+	drafts, err = pg.TranslationDrafts(ctx, itoa(node.ID))
+	assert.NoError(err)
+	assert.Empty(drafts, "promoted draft is removed")
+	edits, err := pg.NodeEdits(ctx, itoa(node.ID))
+	assert.NoError(err)
+	if assert.Len(edits, 1) {
+		assert.Equal(model.NodeEditTypeTranslate, edits[0].Type)
+	}
+	assert.Error(pg.PromoteTranslationDraft(ctx, dbUser, itoa(node.ID), "fr"), "no such draft")
+}
+
+func TestPostgresDB_PromoteTranslationDraft_NormalizesLanguage(t *testing.T) {
+	pg := setupDB(t)
+	pg.languages = db.LanguageNormalizer{CollapseRegionalVariants: true}
+	ctx := context.Background()
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}}
+	node := Node{Description: db.Text{"en": "Algebra"}}
+	assert.NoError(pg.db.Create(&node).Error)
+
+	draft := &model.TranslationDraft{Language: "de-DE", Description: "Algebra (de)", Machine: true, Provider: "dictionary"}
+	assert.NoError(pg.SaveTranslationDraft(ctx, itoa(node.ID), draft))
+	assert.Error(pg.PromoteTranslationDraft(ctx, dbUser, itoa(node.ID), "german"), "invalid language")
+	assert.NoError(pg.PromoteTranslationDraft(ctx, dbUser, itoa(node.ID), "de-DE"))
+	assert.NoError(pg.db.First(&node, node.ID).Error)
+	assert.Equal(db.Text{"en": "Algebra", "de": "Algebra (de)"}, node.Description)
+}
+
+func TestPostgresDB_CreateNode_InvalidLanguage(t *testing.T) {
+	pg := setupDB(t)
+	pg.languages = db.LanguageNormalizer{CollapseRegionalVariants: true}
+	ctx := context.Background()
+	assert := assert.New(t)
+	user := User{Username: "123", PasswordHash: "000", EMail: "a@b"}
+	assert.NoError(pg.db.Create(&user).Error)
+	dbUser := db.User{Document: db.Document{Key: itoa(user.ID)}}
+
+	description := &model.Text{Translations: []*model.Translation{{Language: "english", Content: "A"}}}
+	_, err := pg.CreateNode(ctx, dbUser, description, nil)
+	assert.True(errors.Is(err, db.ErrInvalidLanguage), "expected ErrInvalidLanguage, got '%v'", err)
+
+	description = &model.Text{Translations: []*model.Translation{{Language: "EN-gb", Content: "A"}}}
+	id, err := pg.CreateNode(ctx, dbUser, description, nil)
+	if !assert.NoError(err) {
+		return
+	}
+	node := Node{}
+	assert.NoError(pg.db.First(&node, atoi(id)).Error)
+	assert.Equal(db.Text{"en": "A"}, node.Description)
+}
+
+func TestPostgresDB_NormalizeLanguages(t *testing.T) {
+	pg := setupDB(t)
+	pg.languages = db.LanguageNormalizer{CollapseRegionalVariants: true}
+	ctx := context.Background()
+	assert := assert.New(t)
+	nodes := []Node{
+		{Description: db.Text{"en": "A"}},
+		{Description: db.Text{"EN": "B", "German": "B (de)"}, Resources: db.Text{"de-AT": "R"}},
+		{Description: db.Text{"en": "C", "klingonish": "C?"}},
+	}
+	assert.NoError(pg.db.Create(&nodes).Error)
+
+	res, err := pg.NormalizeLanguages(ctx, map[string]string{"german": "de"}, false)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(1, res.ChangedNodes)
+	assert.Equal(map[uint][]string{nodes[2].ID: {"klingonish"}}, res.InvalidLanguages)
+	assert.NoError(pg.db.First(&nodes[1], nodes[1].ID).Error)
+	assert.Equal(db.Text{"en": "B", "de": "B (de)"}, nodes[1].Description)
+	assert.Equal(db.Text{"de": "R"}, nodes[1].Resources)
+
+	res, err = pg.NormalizeLanguages(ctx, nil, true)
+	assert.NoError(err)
+	assert.Equal(1, res.ChangedNodes)
+	assert.NoError(pg.db.First(&nodes[2], nodes[2].ID).Error)
+	assert.Equal(db.Text{"en": "C"}, nodes[2].Description)
+
+	invalid := Node{Description: db.Text{"klingonish": "D"}, Resources: db.Text{"EN": "R"}}
+	assert.NoError(pg.db.Create(&invalid).Error)
+	_, err = pg.NormalizeLanguages(ctx, nil, true)
+	assert.Error(err, "would leave a node without description")
+	assert.NoError(pg.db.First(&invalid, invalid.ID).Error)
+	assert.Equal(db.Text{"klingonish": "D"}, invalid.Description)
+	assert.Equal(db.Text{"EN": "R"}, invalid.Resources, "nothing changed")
+}
+
+func TestPostgresDB_NodePositions(t *testing.T) {
+	pg := setupDB(t)
+	ctx := context.Background()
+	assert := assert.New(t)
+	layout, err := pg.NodePositions(ctx)
+	assert.NoError(err)
+	assert.Nil(layout, "no layout saved yet")
+
+	nodes := []Node{{Description: db.Text{"en": "A"}}, {Description: db.Text{"en": "B"}}}
+	assert.NoError(pg.db.Create(&nodes).Error)
+	assert.NoError(pg.SaveNodePositions(ctx, &db.Layout{Version: 1, Positions: map[string]model.Vector{
+		itoa(nodes[0].ID): {X: 1, Y: 2},
+		itoa(nodes[1].ID): {X: 3, Y: 4},
+	}}))
+	assert.NoError(pg.SaveNodePositions(ctx, &db.Layout{Version: 2, Positions: map[string]model.Vector{
+		itoa(nodes[0].ID): {X: 5, Y: 6, Z: 7},
+		"999":             {X: 8, Y: 9},
+	}}), "unknown nodes are skipped")
+	layout, err = pg.NodePositions(ctx)
+	assert.NoError(err)
+	assert.Equal(&db.Layout{Version: 2, Positions: map[string]model.Vector{
+		itoa(nodes[0].ID): {X: 5, Y: 6, Z: 7},
+	}}, layout, "layout is replaced")
+}
+
+func TestPostgresDB_NodePins(t *testing.T) {
+	pg := setupDB(t)
+	ctx := context.Background()
+	assert := assert.New(t)
+	pins, err := pg.NodePins(ctx)
+	assert.NoError(err)
+	assert.Empty(pins)
+
+	nodes := []Node{{Description: db.Text{"en": "A"}}, {Description: db.Text{"en": "B"}}}
+	assert.NoError(pg.db.Create(&nodes).Error)
+	admin := db.User{Document: db.Document{Key: "1"}}
+	assert.NoError(pg.PinNode(ctx, admin, itoa(nodes[0].ID), model.Vector{X: 1, Y: 2}))
+	assert.NoError(pg.PinNode(ctx, admin, itoa(nodes[1].ID), model.Vector{X: 3, Y: 4}))
+	assert.NoError(pg.PinNode(ctx, admin, itoa(nodes[0].ID), model.Vector{X: 5, Y: 6, Z: 7}), "pin is moved")
+	assert.Error(pg.PinNode(ctx, admin, "999", model.Vector{X: 1}), "unknown node")
+	pins, err = pg.NodePins(ctx)
+	assert.NoError(err)
+	assert.Equal(map[string]model.Vector{
+		itoa(nodes[0].ID): {X: 5, Y: 6, Z: 7},
+		itoa(nodes[1].ID): {X: 3, Y: 4},
+	}, pins)
+
+	assert.NoError(pg.UnpinNode(ctx, admin, itoa(nodes[1].ID)))
+	assert.Error(pg.UnpinNode(ctx, admin, itoa(nodes[1].ID)), "not pinned anymore")
+	assert.Error(pg.UnpinNode(ctx, admin, "999"), "unknown node")
+	pins, err = pg.NodePins(ctx)
+	assert.NoError(err)
+	assert.Equal(map[string]model.Vector{itoa(nodes[0].ID): {X: 5, Y: 6, Z: 7}}, pins)
+}
@@ -20,8 +20,16 @@ func TESTONLY_SetupAndCleanup(t *testing.T) *PostgresDB {
 	pg.db.Exec(`DROP TABLE IF EXISTS edge_edits CASCADE`)
 	pg.db.Exec(`DROP TABLE IF EXISTS edges CASCADE`)
 	pg.db.Exec(`DROP TABLE IF EXISTS node_edits CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS translation_drafts CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS node_positions CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS node_pins CASCADE`)
 	pg.db.Exec(`DROP TABLE IF EXISTS nodes CASCADE`)
 	pg.db.Exec(`DROP TABLE IF EXISTS roles CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS resource_edits CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS resources CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS tag_edits CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS node_tags CASCADE`)
+	pg.db.Exec(`DROP TABLE IF EXISTS tags CASCADE`)
 	pgdb, err = NewPostgresDB(TESTONLY_Config)
 	assert.NoError(err)
 	pg = pgdb.(*PostgresDB)
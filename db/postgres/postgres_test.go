@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
 )
 
 func TestMakeStringToken(t *testing.T) {
@@ -16,3 +17,11 @@ func TestMakeStringToken(t *testing.T) {
 func strptr(s string) *string {
 	return &s
 }
+
+func localized(text, language string) *model.LocalizedString {
+	return &model.LocalizedString{Text: text, Language: language}
+}
+
+func localizedFallback(text, language string) *model.LocalizedString {
+	return &model.LocalizedString{Text: text, Language: language, IsFallback: true}
+}
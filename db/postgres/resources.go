@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"gorm.io/gorm"
+)
+
+var (
+	markdownLinkRegex = regexp.MustCompile(`\[([^\]]*)\]\((https?://[^\s)]+)\)`)
+	bareURLRegex      = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+	videoHosts        = []string{"youtube.com", "youtu.be", "vimeo.com"}
+)
+
+func isValidResourceURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// guessResourceKind returns ResourceKindVideo for well known video platforms
+// and ResourceKindArticle otherwise
+func guessResourceKind(s string) db.ResourceKind {
+	u, err := url.Parse(s)
+	if err != nil {
+		return db.ResourceKindArticle
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	if db.Contains(videoHosts, host) {
+		return db.ResourceKindVideo
+	}
+	return db.ResourceKindArticle
+}
+
+// extractResources finds all URLs in the free-text resources of a single
+// language. Markdown links use their link text as title, bare URLs use the URL
+// itself.
+func extractResources(language, text string) []ResourceData {
+	resources := []ResourceData{}
+	add := func(link, title string) {
+		link = strings.TrimRight(link, ".,;:!?")
+		if !isValidResourceURL(link) || db.ContainsP(resources, link, func(r ResourceData) string { return r.URL }) {
+			return
+		}
+		title = strings.TrimSpace(title)
+		if title == "" {
+			title = link
+		}
+		resources = append(resources, ResourceData{
+			URL:      link,
+			Title:    title,
+			Kind:     guessResourceKind(link),
+			Language: language,
+		})
+	}
+	for _, match := range markdownLinkRegex.FindAllStringSubmatch(text, -1) {
+		add(match[2], match[1])
+	}
+	for _, match := range bareURLRegex.FindAllString(markdownLinkRegex.ReplaceAllString(text, ""), -1) {
+		add(match, "")
+	}
+	return resources
+}
+
+// MigrateFreeTextResources creates a Resource for every URL found in the
+// free-text Node.Resources. Resources with an already existing URL and
+// language for the same node are skipped, thus it is safe to run this
+// multiple times. The creation is recorded in the history of the resource as
+// done by the creator of the node.
+// Returns the number of created resources.
+func (pg *PostgresDB) MigrateFreeTextResources(ctx context.Context) (int, error) {
+	created := 0
+	err := pg.db.Transaction(func(tx *gorm.DB) error {
+		nodes := []Node{}
+		if err := tx.Where("resources IS NOT NULL").Find(&nodes).Error; err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			creator := NodeEdit{}
+			hasCreator := tx.Where("node_id = ? AND type = ?", node.ID, db.NodeEditTypeCreate).First(&creator).Error == nil
+			languages := make([]string, 0, len(node.Resources))
+			for language := range node.Resources {
+				languages = append(languages, language)
+			}
+			sort.Strings(languages)
+			for _, language := range languages {
+				for _, data := range extractResources(language, node.Resources[language]) {
+					var existing int64
+					if err := tx.Model(&Resource{}).
+						Where("node_id = ? AND url = ? AND language = ?", node.ID, data.URL, data.Language).
+						Count(&existing).Error; err != nil {
+						return err
+					}
+					if existing > 0 {
+						continue
+					}
+					resource := Resource{NodeID: node.ID, ResourceData: data}
+					if err := tx.Create(&resource).Error; err != nil {
+						return err
+					}
+					created += 1
+					if !hasCreator {
+						continue
+					}
+					resourceedit := ResourceEdit{
+						ResourceID:   resource.ID,
+						UserID:       creator.UserID,
+						Type:         db.ResourceEditTypeCreate,
+						ResourceData: data,
+					}
+					if err := tx.Create(&resourceedit).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to migrate free-text resources")
+	}
+	return created, nil
+}
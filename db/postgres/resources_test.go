@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+)
+
+func TestExtractResources(t *testing.T) {
+	for _, test := range []struct {
+		Name string
+		Text string
+		Exp  []ResourceData
+	}{
+		{
+			Name: "no URLs",
+			Text: "read a book about it",
+			Exp:  []ResourceData{},
+		},
+		{
+			Name: "markdown link",
+			Text: "- [Intro to Graphs](https://example.com/graphs)",
+			Exp: []ResourceData{
+				{URL: "https://example.com/graphs", Title: "Intro to Graphs", Kind: db.ResourceKindArticle, Language: "en"},
+			},
+		},
+		{
+			Name: "bare URLs, trailing punctuation & duplicates",
+			Text: "see https://www.youtube.com/watch?v=123. and http://example.com/a, or https://www.youtube.com/watch?v=123",
+			Exp: []ResourceData{
+				{URL: "https://www.youtube.com/watch?v=123", Title: "https://www.youtube.com/watch?v=123", Kind: db.ResourceKindVideo, Language: "en"},
+				{URL: "http://example.com/a", Title: "http://example.com/a", Kind: db.ResourceKindArticle, Language: "en"},
+			},
+		},
+		{
+			Name: "markdown link and same bare URL",
+			Text: "[A](https://a.org) https://a.org",
+			Exp: []ResourceData{
+				{URL: "https://a.org", Title: "A", Kind: db.ResourceKindArticle, Language: "en"},
+			},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Exp, extractResources("en", test.Text))
+		})
+	}
+}
+
+func TestIsValidResourceURL(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(isValidResourceURL("https://example.com/a?b=c"))
+	assert.True(isValidResourceURL("http://example.com"))
+	assert.False(isValidResourceURL("ftp://example.com"))
+	assert.False(isValidResourceURL("example.com"))
+	assert.False(isValidResourceURL(""))
+}
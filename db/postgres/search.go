@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+// LanguageToSearchDictionary maps languages to the PostgreSQL text search
+// configuration used for stemming, languages not listed here use 'simple'.
+var LanguageToSearchDictionary = map[string]string{
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fr": "french",
+	"it": "italian",
+}
+
+// searchDictionarySQL returns a SQL expression, which selects the text search
+// configuration for the language in column languageColumn
+func searchDictionarySQL(languageColumn string) string {
+	languages := make([]string, 0, len(LanguageToSearchDictionary))
+	for language := range LanguageToSearchDictionary {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	b := strings.Builder{}
+	b.WriteString("CASE " + languageColumn)
+	for _, language := range languages {
+		fmt.Fprintf(&b, " WHEN '%s' THEN '%s'::regconfig", language, LanguageToSearchDictionary[language])
+	}
+	b.WriteString(" ELSE 'simple'::regconfig END")
+	return b.String()
+}
+
+// searchAnyDictionaryQuerySQL returns a SQL expression, which matches the
+// query in the named argument queryArg with any text search configuration
+func searchAnyDictionaryQuerySQL(queryArg string) string {
+	dictionaries := make([]string, 0, len(LanguageToSearchDictionary)+1)
+	for _, dictionary := range LanguageToSearchDictionary {
+		dictionaries = append(dictionaries, dictionary)
+	}
+	sort.Strings(dictionaries)
+	dictionaries = append(dictionaries, "simple")
+	queries := make([]string, 0, len(dictionaries))
+	for _, dictionary := range dictionaries {
+		queries = append(queries, fmt.Sprintf("websearch_to_tsquery('%s', @%s)", dictionary, queryArg))
+	}
+	return "(" + strings.Join(queries, " || ") + ")"
+}
+
+// searchDocumentFunctionSQL creates node_search_document(description,
+// resources), the text search document of a node over all its languages.
+// It is immutable, s.t. the GIN index of searchIndexSQL can be built on it.
+func searchDocumentFunctionSQL() string {
+	return `
+    CREATE OR REPLACE FUNCTION node_search_document(description jsonb, resources jsonb) RETURNS tsvector
+    LANGUAGE plpgsql IMMUTABLE AS $$
+    DECLARE
+        document tsvector := ''::tsvector;
+        dictionary regconfig;
+        entry record;
+    BEGIN
+        FOR entry IN SELECT key, value FROM jsonb_each_text(description) LOOP
+            dictionary := ` + searchDictionarySQL("entry.key") + `;
+            document := document
+                || setweight(to_tsvector(dictionary, entry.value), 'A')
+                || setweight(to_tsvector(dictionary, COALESCE(resources->>entry.key, '')), 'B');
+        END LOOP;
+        RETURN document;
+    END
+    $$`
+}
+
+const searchIndexSQL = `CREATE INDEX IF NOT EXISTS idx_nodes_search_document ON nodes USING GIN (node_search_document(description, resources))`
+
+// searchMigrations creates the function and index needed by Search
+func searchMigrations() []string {
+	return []string{searchDocumentFunctionSQL(), searchIndexSQL}
+}
+
+// SearchLanguagePenalty weighs the language preference against the rank of a
+// match: the score of a match is its rank divided by 1 + SearchLanguagePenalty
+// times the position of its language in the preferred languages.
+var SearchLanguagePenalty = 0.5
+
+// searchPrioritySQL returns a SQL expression, which ranks the language in
+// column languageColumn by its position in languages. The languages are
+// passed as named arguments, which are added to args.
+func searchPrioritySQL(languageColumn string, languages []string, args map[string]interface{}) string {
+	b := strings.Builder{}
+	b.WriteString("CASE " + languageColumn)
+	for i, language := range languages {
+		arg := fmt.Sprintf("language%d", i)
+		args[arg] = language
+		fmt.Fprintf(&b, " WHEN @%s THEN %d", arg, i)
+	}
+	fmt.Fprintf(&b, " ELSE %d END", len(languages))
+	return b.String()
+}
+
+type searchMatch struct {
+	NodeID   uint
+	Language string
+	Rank     float64
+	Snippet  string
+}
+
+// Search searches the descriptions and resources of all nodes in every
+// language. Matches are scored by their rank and the preference of their
+// language: the requested language first, then the languages of the request,
+// then the fallback languages, then any other language (see
+// ConvertToModel.getTranslationOrFallback). For each node only the best
+// scored language is returned.
+func (pg *PostgresDB) Search(ctx context.Context, query, language string, limit int, tags []string) ([]*model.SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return []*model.SearchResult{}, nil
+	}
+	if language != "" {
+		normalized, err := pg.languages.Normalize(language)
+		if err != nil {
+			return nil, err
+		}
+		language = normalized
+	}
+	convert := pg.convertToModel(ctx)
+	preferred := (&ConvertToModel{
+		languages:         append([]string{language}, convert.languages...),
+		fallbackLanguages: convert.fallbackLanguages,
+	}).translationOrder(nil)
+	args := map[string]interface{}{
+		"query":   query,
+		"limit":   limit,
+		"penalty": SearchLanguagePenalty,
+	}
+	tagFilter := ""
+	if len(tags) > 0 {
+		tagFilter = "AND nodes.id IN (SELECT node_id FROM node_tags WHERE tag_id IN @tags)"
+		args["tags"] = tags
+	}
+	sql := `
+    WITH candidates AS (
+        -- uses the index idx_nodes_search_document
+        SELECT * FROM nodes
+        WHERE nodes.deleted_at IS NULL ` + tagFilter + `
+            AND node_search_document(nodes.description, nodes.resources) @@ ` + searchAnyDictionaryQuerySQL("query") + `
+    ), texts AS (
+        SELECT nodes.id AS node_id, description.key AS language,
+            description.value AS description,
+            COALESCE(nodes.resources->>description.key, '') AS resources,
+            ` + searchPrioritySQL("description.key", preferred, args) + ` AS priority,
+            ` + searchDictionarySQL("description.key") + ` AS dictionary
+        FROM candidates AS nodes, jsonb_each_text(nodes.description) AS description
+    ), documents AS (
+        SELECT *,
+            setweight(to_tsvector(dictionary, description), 'A') || setweight(to_tsvector(dictionary, resources), 'B') AS document,
+            websearch_to_tsquery(dictionary, @query) AS query
+        FROM texts
+    ), scores AS (
+        SELECT *, ts_rank(document, query) / (1 + @penalty * priority) AS score
+        FROM documents
+        WHERE document @@ query
+    ), matches AS (
+        -- keep only the best scored language per node
+        SELECT DISTINCT ON (node_id) node_id, language, score,
+            ts_headline(dictionary, description || ' ' || resources, query) AS snippet
+        FROM scores
+        ORDER BY node_id, score DESC, language
+    )
+    SELECT node_id, language, score AS rank, snippet FROM matches
+    ORDER BY score DESC, node_id
+    LIMIT @limit
+    `
+	matches := []searchMatch{}
+	if err := pg.db.Raw(sql, args).Scan(&matches).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to search nodes")
+	}
+	nodeIDs := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		nodeIDs = append(nodeIDs, match.NodeID)
+	}
+	nodes := []Node{}
+	if len(nodeIDs) > 0 {
+		if err := pg.db.Preload("Tags").Find(&nodes, nodeIDs).Error; err != nil {
+			return nil, errors.Wrap(err, "failed to fetch matched nodes")
+		}
+	}
+	lookup := make(map[uint]Node, len(nodes))
+	for _, node := range nodes {
+		lookup[node.ID] = node
+	}
+	results := make([]*model.SearchResult, 0, len(matches))
+	for _, match := range matches {
+		node := convert.Node(lookup[match.NodeID])
+		if node == nil {
+			continue
+		}
+		results = append(results, &model.SearchResult{
+			Node:     node,
+			Language: match.Language,
+			Rank:     match.Rank,
+			Snippet:  match.Snippet,
+		})
+	}
+	return results, nil
+}
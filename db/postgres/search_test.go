@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchDictionarySQL(t *testing.T) {
+	assert.Equal(t,
+		"CASE lang WHEN 'de' THEN 'german'::regconfig WHEN 'en' THEN 'english'::regconfig WHEN 'es' THEN 'spanish'::regconfig WHEN 'fr' THEN 'french'::regconfig WHEN 'it' THEN 'italian'::regconfig ELSE 'simple'::regconfig END",
+		searchDictionarySQL("lang"),
+	)
+}
+
+func TestSearchAnyDictionaryQuerySQL(t *testing.T) {
+	assert.Equal(t,
+		"(websearch_to_tsquery('english', @q) || websearch_to_tsquery('french', @q) || websearch_to_tsquery('german', @q) || websearch_to_tsquery('italian', @q) || websearch_to_tsquery('spanish', @q) || websearch_to_tsquery('simple', @q))",
+		searchAnyDictionaryQuerySQL("q"),
+	)
+}
+
+func TestSearchPrioritySQL(t *testing.T) {
+	args := map[string]interface{}{}
+	assert.Equal(t,
+		"CASE lang WHEN @language0 THEN 0 WHEN @language1 THEN 1 ELSE 2 END",
+		searchPrioritySQL("lang", []string{"de", "en"}, args),
+	)
+	assert.Equal(t, map[string]interface{}{"language0": "de", "language1": "en"}, args)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"gorm.io/gorm"
+)
+
+// similarMigrations creates the extension, function and index needed by
+// SimilarNodes: node_description_text(description) joins the descriptions of
+// a node in all languages, its trigram index finds candidates by
+// word_similarity, which is an upper bound of the similarity to each of the
+// descriptions.
+func similarMigrations() []string {
+	return []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`
+        CREATE OR REPLACE FUNCTION node_description_text(description jsonb) RETURNS text
+        LANGUAGE sql IMMUTABLE AS $$
+            SELECT COALESCE(string_agg(value, ' ' ORDER BY key), '') FROM jsonb_each_text(description)
+        $$`,
+		`CREATE INDEX IF NOT EXISTS idx_nodes_description_trgm ON nodes USING GIN (node_description_text(description) gin_trgm_ops)`,
+	}
+}
+
+type similarMatch struct {
+	NodeID     uint
+	Similarity float64
+}
+
+// SimilarNodes returns the nodes with a description of trigram similarity
+// (see pg_trgm) of at least threshold in one of the languages of description,
+// most similar first.
+func (pg *PostgresDB) SimilarNodes(ctx context.Context, description *model.Text, threshold float64, limit int) ([]*model.SimilarNode, error) {
+	text, err := pg.convertToDBText(description)
+	if err != nil {
+		return nil, err
+	}
+	if len(text) == 0 {
+		return []*model.SimilarNode{}, nil
+	}
+	languages := make([]string, 0, len(text))
+	for language := range text {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	args := map[string]interface{}{
+		"threshold": threshold,
+		"limit":     limit,
+	}
+	inputs := make([]string, 0, len(languages))
+	for i, language := range languages {
+		args[fmt.Sprintf("language%d", i)] = language
+		args[fmt.Sprintf("content%d", i)] = text[language]
+		inputs = append(inputs, fmt.Sprintf("(CAST(@language%d AS text), CAST(@content%d AS text))", i, i))
+	}
+	sql := `
+    WITH input(language, content) AS (VALUES ` + strings.Join(inputs, ", ") + `)
+    SELECT nodes.id AS node_id,
+        MAX(similarity(nodes.description->>input.language, input.content)) AS similarity
+    FROM nodes JOIN input ON nodes.description->>input.language IS NOT NULL
+    WHERE nodes.deleted_at IS NULL
+        -- uses the index idx_nodes_description_trgm
+        AND input.content <% node_description_text(nodes.description)
+    GROUP BY nodes.id
+    HAVING MAX(similarity(nodes.description->>input.language, input.content)) >= @threshold
+    ORDER BY similarity DESC, nodes.id
+    LIMIT @limit
+    `
+	matches := []similarMatch{}
+	if err := pg.db.Transaction(func(tx *gorm.DB) error {
+		// the operator <% matches with a word_similarity of at least this
+		// setting, it is reset at the end of the transaction
+		if err := tx.Exec(`SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)`, fmt.Sprint(threshold)).Error; err != nil {
+			return err
+		}
+		return tx.Raw(sql, args).Scan(&matches).Error
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to query similar nodes")
+	}
+	nodeIDs := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		nodeIDs = append(nodeIDs, match.NodeID)
+	}
+	nodes := []Node{}
+	if len(nodeIDs) > 0 {
+		if err := pg.db.Find(&nodes, nodeIDs).Error; err != nil {
+			return nil, errors.Wrap(err, "failed to fetch similar nodes")
+		}
+	}
+	lookup := make(map[uint]Node, len(nodes))
+	for _, node := range nodes {
+		lookup[node.ID] = node
+	}
+	convert := pg.convertToModel(ctx)
+	similar := make([]*model.SimilarNode, 0, len(matches))
+	for _, match := range matches {
+		if node := convert.Node(lookup[match.NodeID]); node != nil {
+			similar = append(similar, &model.SimilarNode{Node: node, Similarity: match.Similarity})
+		}
+	}
+	return similar, nil
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"github.com/suxatcode/learn-graph-poc-backend/middleware"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (pg *PostgresDB) TranslateNode(ctx context.Context, user db.User, nodeID, language, description string, resources *string) error {
+	language, err := pg.languages.Normalize(language)
+	if err != nil {
+		return err
+	}
+	return pg.db.Transaction(func(tx *gorm.DB) error {
+		return translateNode(tx, user, nodeID, language, description, resources)
+	})
+}
+
+// translateNode adds a translation to a node and removes all drafts for this
+// language
+func translateNode(tx *gorm.DB, user db.User, nodeID, language, description string, resources *string) error {
+	if language == "" || description == "" {
+		return errors.New("language and description must not be empty")
+	}
+	node := Node{Model: gorm.Model{ID: atoi(nodeID)}}
+	if err := tx.First(&node).Error; err != nil {
+		return err
+	}
+	if _, ok := node.Description[language]; ok {
+		return fmt.Errorf("node %s already has a description in language '%s'", nodeID, language)
+	}
+	node.Description = mergeText(node.Description, db.Text{language: description})
+	if resources != nil && *resources != "" {
+		node.Resources = mergeText(node.Resources, db.Text{language: *resources})
+	}
+	if err := tx.Save(&node).Error; err != nil {
+		return err
+	}
+	nodeedit := NodeEdit{
+		NodeID:         node.ID,
+		UserID:         atoi(user.Key),
+		Type:           db.NodeEditTypeTranslate,
+		NewDescription: node.Description,
+		NewResources:   node.Resources,
+		Language:       &language,
+	}
+	if err := tx.Create(&nodeedit).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("node_id = ? AND language = ?", node.ID, language).Delete(&TranslationDraft{}).Error
+}
+
+func (pg *PostgresDB) RemoveTranslation(ctx context.Context, user db.User, nodeID, language string, resourcesOnly bool) error {
+	return pg.db.Transaction(func(tx *gorm.DB) error {
+		node := Node{Model: gorm.Model{ID: atoi(nodeID)}}
+		if err := tx.First(&node).Error; err != nil {
+			return err
+		}
+		// invalid languages stored before normalization can only be removed
+		// by their exact key
+		if _, inDescription := node.Description[language]; !inDescription {
+			if _, inResources := node.Resources[language]; !inResources {
+				normalized, err := pg.languages.Normalize(language)
+				if err != nil {
+					return err
+				}
+				language = normalized
+			}
+		}
+		_, inDescription := node.Description[language]
+		_, inResources := node.Resources[language]
+		if resourcesOnly && !inResources {
+			return fmt.Errorf("node %s has no resources in language '%s'", nodeID, language)
+		}
+		if !resourcesOnly && !inDescription && !inResources {
+			return fmt.Errorf("node %s has no translation in language '%s'", nodeID, language)
+		}
+		if !resourcesOnly && inDescription && len(node.Description) == 1 {
+			return fmt.Errorf("language '%s' holds the last description of node %s", language, nodeID)
+		}
+		if !resourcesOnly {
+			node.Description = removeLanguage(node.Description, language)
+		}
+		node.Resources = removeLanguage(node.Resources, language)
+		if err := tx.Save(&node).Error; err != nil {
+			return err
+		}
+		nodeedit := NodeEdit{
+			NodeID:         node.ID,
+			UserID:         atoi(user.Key),
+			Type:           db.NodeEditTypeRemoveTranslation,
+			NewDescription: node.Description,
+			NewResources:   node.Resources,
+			Language:       &language,
+		}
+		return tx.Create(&nodeedit).Error
+	})
+}
+
+func (pg *PostgresDB) NodeTexts(ctx context.Context, nodeID string) (db.Text, db.Text, error) {
+	node := Node{Model: gorm.Model{ID: atoi(nodeID)}}
+	if err := pg.db.First(&node).Error; err != nil {
+		return nil, nil, err
+	}
+	return node.Description, node.Resources, nil
+}
+
+func (pg *PostgresDB) SaveTranslationDraft(ctx context.Context, nodeID string, draft *model.TranslationDraft) error {
+	if draft.Description == "" {
+		return errors.New("description must not be empty")
+	}
+	language, err := pg.languages.Normalize(draft.Language)
+	if err != nil {
+		return err
+	}
+	return pg.db.Transaction(func(tx *gorm.DB) error {
+		node := Node{Model: gorm.Model{ID: atoi(nodeID)}}
+		if err := tx.First(&node).Error; err != nil {
+			return err
+		}
+		if _, ok := node.Description[language]; ok {
+			return fmt.Errorf("node %s already has a description in language '%s'", nodeID, language)
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "node_id"}, {Name: "language"}},
+			DoUpdates: clause.AssignmentColumns([]string{"description", "resources", "machine", "provider", "updated_at"}),
+		}).Create(&TranslationDraft{
+			NodeID:      node.ID,
+			Language:    language,
+			Description: draft.Description,
+			Resources:   draft.Resources,
+			Machine:     draft.Machine,
+			Provider:    draft.Provider,
+		}).Error
+	})
+}
+
+func (pg *PostgresDB) TranslationDrafts(ctx context.Context, nodeID string) ([]*model.TranslationDraft, error) {
+	drafts := []TranslationDraft{}
+	if err := pg.db.Where("node_id = ?", atoi(nodeID)).Order("language").Find(&drafts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to fetch translation drafts")
+	}
+	return pg.convertToModel(ctx).TranslationDrafts(drafts), nil
+}
+
+func (pg *PostgresDB) PromoteTranslationDraft(ctx context.Context, user db.User, nodeID, language string) error {
+	language, err := pg.languages.Normalize(language)
+	if err != nil {
+		return err
+	}
+	return pg.db.Transaction(func(tx *gorm.DB) error {
+		draft := TranslationDraft{}
+		if err := tx.Where("node_id = ? AND language = ?", atoi(nodeID), language).First(&draft).Error; err != nil {
+			return errors.Wrapf(err, "no draft in language '%s' for node %s", language, nodeID)
+		}
+		return translateNode(tx, user, nodeID, language, draft.Description, draft.Resources)
+	})
+}
+
+// translationDrafts returns the drafts of nodes, if the request opted in to
+// machine translations
+func (pg *PostgresDB) translationDrafts(ctx context.Context, nodeIDs []uint) ([]TranslationDraft, error) {
+	drafts := []TranslationDraft{}
+	if !middleware.CtxGetMachineTranslations(ctx) || len(nodeIDs) == 0 {
+		return drafts, nil
+	}
+	if err := pg.db.Where("node_id IN ?", nodeIDs).Find(&drafts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to fetch translation drafts")
+	}
+	return drafts, nil
+}
+
+type translationCounts struct {
+	Nodes        int
+	Descriptions int
+	Resources    int
+	Untranslated int
+}
+
+// a node is untranslated, if it lacks a description in the language or has
+// resources in other languages only
+const untranslatedNodeSQL = `(nodes.description->>@language IS NULL
+    OR (jsonb_typeof(nodes.resources) = 'object' AND nodes.resources <> '{}'::jsonb AND nodes.resources->>@language IS NULL))`
+
+func (pg *PostgresDB) TranslationStatus(ctx context.Context, language string, offset, limit int) (*model.TranslationStatus, error) {
+	language, err := pg.languages.Normalize(language)
+	if err != nil {
+		return nil, err
+	}
+	args := map[string]interface{}{
+		"language": language,
+		"offset":   offset,
+		"limit":    limit,
+	}
+	counts := translationCounts{}
+	query := `
+    SELECT COUNT(*) AS nodes,
+        COUNT(*) FILTER (WHERE nodes.description->>@language IS NOT NULL) AS descriptions,
+        COUNT(*) FILTER (WHERE nodes.resources->>@language IS NOT NULL) AS resources,
+        COUNT(*) FILTER (WHERE ` + untranslatedNodeSQL + `) AS untranslated
+    FROM nodes
+    WHERE nodes.deleted_at IS NULL
+    `
+	if err := pg.db.Raw(query, args).Scan(&counts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to count translations")
+	}
+	nodeIDs := []uint{}
+	query = `
+    SELECT nodes.id
+    FROM nodes
+    LEFT JOIN edges ON (edges.from_id = nodes.id OR edges.to_id = nodes.id) AND edges.deleted_at IS NULL
+    WHERE nodes.deleted_at IS NULL AND ` + untranslatedNodeSQL + `
+    GROUP BY nodes.id
+    ORDER BY COALESCE(SUM(edges.weight), 0) DESC, COUNT(edges.id) DESC, nodes.id
+    OFFSET @offset LIMIT @limit
+    `
+	if err := pg.db.Raw(query, args).Scan(&nodeIDs).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to fetch untranslated nodes")
+	}
+	nodes := []Node{}
+	if len(nodeIDs) > 0 {
+		if err := pg.db.Preload("Tags").Find(&nodes, nodeIDs).Error; err != nil {
+			return nil, errors.Wrap(err, "failed to fetch untranslated nodes")
+		}
+	}
+	lookup := make(map[uint]Node, len(nodes))
+	for _, node := range nodes {
+		lookup[node.ID] = node
+	}
+	convert := pg.convertToModel(ctx)
+	status := &model.TranslationStatus{
+		Language:              language,
+		NodeCount:             counts.Nodes,
+		UntranslatedNodeCount: counts.Untranslated,
+		UntranslatedNodes:     make([]*model.Node, 0, len(nodeIDs)),
+	}
+	if counts.Nodes > 0 {
+		status.DescriptionPercentage = 100.0 * float64(counts.Descriptions) / float64(counts.Nodes)
+		status.ResourcesPercentage = 100.0 * float64(counts.Resources) / float64(counts.Nodes)
+	}
+	for _, id := range nodeIDs {
+		if node := convert.Node(lookup[id]); node != nil {
+			status.UntranslatedNodes = append(status.UntranslatedNodes, node)
+		}
+	}
+	return status, nil
+}
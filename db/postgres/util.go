@@ -32,3 +32,18 @@ func mergeText(a, b db.Text) db.Text {
 	}
 	return r
 }
+
+// removeLanguage returns a copy of text without language, or nil if nothing
+// remains
+func removeLanguage(text db.Text, language string) db.Text {
+	r := make(db.Text, len(text))
+	for key, value := range text {
+		if key != language {
+			r[key] = value
+		}
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,19 @@
+package db
+
+import "github.com/pkg/errors"
+
+var ErrInvalidVote = errors.New("invalid vote")
+
+// range of the votes on the usefulness of resources
+const (
+	MinVote = 1.0
+	MaxVote = 10.0
+)
+
+// ValidateVote returns ErrInvalidVote if value is not within [MinVote, MaxVote]
+func ValidateVote(value float64) error {
+	if !(MinVote <= value && value <= MaxVote) {
+		return errors.Wrapf(ErrInvalidVote, "%v is not within [%v, %v]", value, MinVote, MaxVote)
+	}
+	return nil
+}
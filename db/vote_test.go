@@ -0,0 +1,34 @@
+package db
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVote(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Inp    float64
+		ExpErr bool
+	}{
+		{Name: "minimum", Inp: MinVote},
+		{Name: "maximum", Inp: MaxVote},
+		{Name: "in between", Inp: 4.5},
+		{Name: "below minimum", Inp: 0, ExpErr: true},
+		{Name: "above maximum", Inp: 10.5, ExpErr: true},
+		{Name: "NaN", Inp: math.NaN(), ExpErr: true},
+		{Name: "infinity", Inp: math.Inf(1), ExpErr: true},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			err := ValidateVote(test.Inp)
+			if test.ExpErr {
+				assert.True(t, errors.Is(err, ErrInvalidVote), "got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
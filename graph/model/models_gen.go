@@ -19,10 +19,11 @@ type CreateUserResult struct {
 }
 
 type Edge struct {
-	ID     string  `json:"id"`
-	From   string  `json:"from"`
-	To     string  `json:"to"`
-	Weight float64 `json:"weight"`
+	ID     string   `json:"id"`
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Weight float64  `json:"weight"`
+	Type   EdgeType `json:"type"`
 }
 
 type EdgeEdit struct {
@@ -33,8 +34,34 @@ type EdgeEdit struct {
 }
 
 type Graph struct {
-	Nodes []*Node `json:"nodes,omitempty"`
-	Edges []*Edge `json:"edges,omitempty"`
+	Nodes        []*Node       `json:"nodes,omitempty"`
+	Edges        []*Edge       `json:"edges,omitempty"`
+	LayoutStatus *LayoutStatus `json:"layoutStatus,omitempty"`
+}
+
+type GraphOverview struct {
+	Nodes []*OverviewNode `json:"nodes"`
+	Edges []*OverviewEdge `json:"edges"`
+}
+
+type LayoutStats struct {
+	FinishedAt            time.Time `json:"finishedAt"`
+	Iterations            int       `json:"iterations"`
+	TotalTimeMilliseconds int       `json:"totalTimeMilliseconds"`
+	Converged             bool      `json:"converged"`
+	TimedOut              bool      `json:"timedOut"`
+	Energy                float64   `json:"energy"`
+	AverageDisplacement   float64   `json:"averageDisplacement"`
+	MaxDisplacement       float64   `json:"maxDisplacement"`
+	EdgeLengthStress      float64   `json:"edgeLengthStress"`
+	EdgeCrossings         float64   `json:"edgeCrossings"`
+}
+
+type LocalizedString struct {
+	Text                 string `json:"text"`
+	Language             string `json:"language"`
+	IsFallback           bool   `json:"isFallback"`
+	IsMachineTranslation bool   `json:"isMachineTranslation"`
 }
 
 type LoginAuthentication struct {
@@ -54,27 +81,104 @@ type Mutation struct {
 }
 
 type Node struct {
-	ID          string  `json:"id"`
-	Description string  `json:"description"`
-	Resources   *string `json:"resources,omitempty"`
-	Position    *Vector `json:"position,omitempty"`
+	ID          string           `json:"id"`
+	Description *LocalizedString `json:"description"`
+	Resources   *LocalizedString `json:"resources,omitempty"`
+	Position    *Vector          `json:"position,omitempty"`
+	Tags        []*Tag           `json:"tags,omitempty"`
+	Cluster     *int             `json:"cluster,omitempty"`
 }
 
 type NodeEdit struct {
-	Username       string       `json:"username"`
-	Type           NodeEditType `json:"type"`
-	NewDescription string       `json:"newDescription"`
-	NewResources   *string      `json:"newResources,omitempty"`
-	UpdatedAt      time.Time    `json:"updatedAt"`
+	Username       string           `json:"username"`
+	Type           NodeEditType     `json:"type"`
+	NewDescription *LocalizedString `json:"newDescription"`
+	NewResources   *LocalizedString `json:"newResources,omitempty"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+	Tag            *Tag             `json:"tag,omitempty"`
+	Language       *string          `json:"language,omitempty"`
+}
+
+type OverviewEdge struct {
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Weight    float64 `json:"weight"`
+	EdgeCount int     `json:"edgeCount"`
+}
+
+type OverviewNode struct {
+	ID             string  `json:"id"`
+	NodeCount      int     `json:"nodeCount"`
+	Position       *Vector `json:"position,omitempty"`
+	Cluster        *int    `json:"cluster,omitempty"`
+	Representative *Node   `json:"representative"`
 }
 
 type Query struct {
 }
 
+type RectInput struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type Resource struct {
+	ID               string              `json:"id"`
+	NodeID           string              `json:"nodeID"`
+	URL              string              `json:"url"`
+	Title            string              `json:"title"`
+	Kind             ResourceKind        `json:"kind"`
+	Language         string              `json:"language"`
+	EstimatedMinutes *int                `json:"estimatedMinutes,omitempty"`
+	Difficulty       *ResourceDifficulty `json:"difficulty,omitempty"`
+	Usefulness       float64             `json:"usefulness"`
+}
+
+type ResourceEdit struct {
+	Username         string              `json:"username"`
+	Type             ResourceEditType    `json:"type"`
+	UpdatedAt        time.Time           `json:"updatedAt"`
+	URL              string              `json:"url"`
+	Title            string              `json:"title"`
+	Kind             ResourceKind        `json:"kind"`
+	Language         string              `json:"language"`
+	EstimatedMinutes *int                `json:"estimatedMinutes,omitempty"`
+	Difficulty       *ResourceDifficulty `json:"difficulty,omitempty"`
+	Vote             *float64            `json:"vote,omitempty"`
+}
+
+type ResourceInput struct {
+	URL              string              `json:"url"`
+	Title            string              `json:"title"`
+	Kind             ResourceKind        `json:"kind"`
+	Language         string              `json:"language"`
+	EstimatedMinutes *int                `json:"estimatedMinutes,omitempty"`
+	Difficulty       *ResourceDifficulty `json:"difficulty,omitempty"`
+}
+
+type SearchResult struct {
+	Node     *Node   `json:"node"`
+	Language string  `json:"language"`
+	Rank     float64 `json:"rank"`
+	Snippet  string  `json:"snippet"`
+}
+
+type SimilarNode struct {
+	Node       *Node   `json:"node"`
+	Similarity float64 `json:"similarity"`
+}
+
 type Status struct {
 	Message string `json:"Message"`
 }
 
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 type Text struct {
 	Translations []*Translation `json:"translations"`
 }
@@ -84,12 +188,36 @@ type Translation struct {
 	Content  string `json:"content"`
 }
 
+type TranslationDraft struct {
+	Language    string    `json:"language"`
+	Description string    `json:"description"`
+	Resources   *string   `json:"resources,omitempty"`
+	Machine     bool      `json:"machine"`
+	Provider    string    `json:"provider"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type TranslationStatus struct {
+	Language              string  `json:"language"`
+	NodeCount             int     `json:"nodeCount"`
+	DescriptionPercentage float64 `json:"descriptionPercentage"`
+	ResourcesPercentage   float64 `json:"resourcesPercentage"`
+	UntranslatedNodeCount int     `json:"untranslatedNodeCount"`
+	UntranslatedNodes     []*Node `json:"untranslatedNodes"`
+}
+
 type Vector struct {
 	X float64 `json:"x"`
 	Y float64 `json:"y"`
 	Z float64 `json:"z"`
 }
 
+type VectorInput struct {
+	X float64  `json:"x"`
+	Y float64  `json:"y"`
+	Z *float64 `json:"z,omitempty"`
+}
+
 type EdgeEditType string
 
 const (
@@ -131,21 +259,115 @@ func (e EdgeEditType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type EdgeType string
+
+const (
+	EdgeTypePrerequisite EdgeType = "prerequisite"
+	EdgeTypeRelatedTo    EdgeType = "relatedTo"
+	EdgeTypePartOf       EdgeType = "partOf"
+)
+
+var AllEdgeType = []EdgeType{
+	EdgeTypePrerequisite,
+	EdgeTypeRelatedTo,
+	EdgeTypePartOf,
+}
+
+func (e EdgeType) IsValid() bool {
+	switch e {
+	case EdgeTypePrerequisite, EdgeTypeRelatedTo, EdgeTypePartOf:
+		return true
+	}
+	return false
+}
+
+func (e EdgeType) String() string {
+	return string(e)
+}
+
+func (e *EdgeType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EdgeType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EdgeType", str)
+	}
+	return nil
+}
+
+func (e EdgeType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LayoutStatus string
+
+const (
+	LayoutStatusFresh     LayoutStatus = "fresh"
+	LayoutStatusStale     LayoutStatus = "stale"
+	LayoutStatusComputing LayoutStatus = "computing"
+)
+
+var AllLayoutStatus = []LayoutStatus{
+	LayoutStatusFresh,
+	LayoutStatusStale,
+	LayoutStatusComputing,
+}
+
+func (e LayoutStatus) IsValid() bool {
+	switch e {
+	case LayoutStatusFresh, LayoutStatusStale, LayoutStatusComputing:
+		return true
+	}
+	return false
+}
+
+func (e LayoutStatus) String() string {
+	return string(e)
+}
+
+func (e *LayoutStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LayoutStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LayoutStatus", str)
+	}
+	return nil
+}
+
+func (e LayoutStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type NodeEditType string
 
 const (
-	NodeEditTypeCreate NodeEditType = "create"
-	NodeEditTypeEdit   NodeEditType = "edit"
+	NodeEditTypeCreate            NodeEditType = "create"
+	NodeEditTypeEdit              NodeEditType = "edit"
+	NodeEditTypeAddTag            NodeEditType = "addTag"
+	NodeEditTypeRemoveTag         NodeEditType = "removeTag"
+	NodeEditTypeTranslate         NodeEditType = "translate"
+	NodeEditTypeRemoveTranslation NodeEditType = "removeTranslation"
 )
 
 var AllNodeEditType = []NodeEditType{
 	NodeEditTypeCreate,
 	NodeEditTypeEdit,
+	NodeEditTypeAddTag,
+	NodeEditTypeRemoveTag,
+	NodeEditTypeTranslate,
+	NodeEditTypeRemoveTranslation,
 }
 
 func (e NodeEditType) IsValid() bool {
 	switch e {
-	case NodeEditTypeCreate, NodeEditTypeEdit:
+	case NodeEditTypeCreate, NodeEditTypeEdit, NodeEditTypeAddTag, NodeEditTypeRemoveTag, NodeEditTypeTranslate, NodeEditTypeRemoveTranslation:
 		return true
 	}
 	return false
@@ -171,3 +393,136 @@ func (e *NodeEditType) UnmarshalGQL(v interface{}) error {
 func (e NodeEditType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type ResourceDifficulty string
+
+const (
+	ResourceDifficultyBeginner     ResourceDifficulty = "beginner"
+	ResourceDifficultyIntermediate ResourceDifficulty = "intermediate"
+	ResourceDifficultyAdvanced     ResourceDifficulty = "advanced"
+)
+
+var AllResourceDifficulty = []ResourceDifficulty{
+	ResourceDifficultyBeginner,
+	ResourceDifficultyIntermediate,
+	ResourceDifficultyAdvanced,
+}
+
+func (e ResourceDifficulty) IsValid() bool {
+	switch e {
+	case ResourceDifficultyBeginner, ResourceDifficultyIntermediate, ResourceDifficultyAdvanced:
+		return true
+	}
+	return false
+}
+
+func (e ResourceDifficulty) String() string {
+	return string(e)
+}
+
+func (e *ResourceDifficulty) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ResourceDifficulty(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ResourceDifficulty", str)
+	}
+	return nil
+}
+
+func (e ResourceDifficulty) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ResourceEditType string
+
+const (
+	ResourceEditTypeCreate ResourceEditType = "create"
+	ResourceEditTypeEdit   ResourceEditType = "edit"
+	ResourceEditTypeDelete ResourceEditType = "delete"
+	ResourceEditTypeVote   ResourceEditType = "vote"
+)
+
+var AllResourceEditType = []ResourceEditType{
+	ResourceEditTypeCreate,
+	ResourceEditTypeEdit,
+	ResourceEditTypeDelete,
+	ResourceEditTypeVote,
+}
+
+func (e ResourceEditType) IsValid() bool {
+	switch e {
+	case ResourceEditTypeCreate, ResourceEditTypeEdit, ResourceEditTypeDelete, ResourceEditTypeVote:
+		return true
+	}
+	return false
+}
+
+func (e ResourceEditType) String() string {
+	return string(e)
+}
+
+func (e *ResourceEditType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ResourceEditType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ResourceEditType", str)
+	}
+	return nil
+}
+
+func (e ResourceEditType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ResourceKind string
+
+const (
+	ResourceKindVideo    ResourceKind = "video"
+	ResourceKindArticle  ResourceKind = "article"
+	ResourceKindBook     ResourceKind = "book"
+	ResourceKindExercise ResourceKind = "exercise"
+)
+
+var AllResourceKind = []ResourceKind{
+	ResourceKindVideo,
+	ResourceKindArticle,
+	ResourceKindBook,
+	ResourceKindExercise,
+}
+
+func (e ResourceKind) IsValid() bool {
+	switch e {
+	case ResourceKindVideo, ResourceKindArticle, ResourceKindBook, ResourceKindExercise:
+		return true
+	}
+	return false
+}
+
+func (e ResourceKind) String() string {
+	return string(e)
+}
+
+func (e *ResourceKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ResourceKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ResourceKind", str)
+	}
+	return nil
+}
+
+func (e ResourceKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
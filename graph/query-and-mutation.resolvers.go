@@ -14,13 +14,13 @@ import (
 )
 
 // CreateNode is the resolver for the createNode field.
-func (r *mutationResolver) CreateNode(ctx context.Context, description model.Text, resources *model.Text) (*model.CreateEntityResult, error) {
-	return r.Ctrl.CreateNode(ctx, description, resources)
+func (r *mutationResolver) CreateNode(ctx context.Context, description model.Text, resources *model.Text, force *bool) (*model.CreateEntityResult, error) {
+	return r.Ctrl.CreateNode(ctx, description, resources, force)
 }
 
 // CreateEdge is the resolver for the createEdge field.
-func (r *mutationResolver) CreateEdge(ctx context.Context, from string, to string, weight float64) (*model.CreateEntityResult, error) {
-	return r.Ctrl.CreateEdge(ctx, from, to, weight)
+func (r *mutationResolver) CreateEdge(ctx context.Context, from string, to string, weight float64, typeArg *model.EdgeType) (*model.CreateEntityResult, error) {
+	return r.Ctrl.CreateEdge(ctx, from, to, weight, typeArg)
 }
 
 // EditNode is the resolver for the editNode field.
@@ -28,6 +28,26 @@ func (r *mutationResolver) EditNode(ctx context.Context, id string, description
 	return r.Ctrl.EditNode(ctx, id, description, resources)
 }
 
+// TranslateNode is the resolver for the translateNode field.
+func (r *mutationResolver) TranslateNode(ctx context.Context, id string, language string, description string, resources *string) (*model.Status, error) {
+	return r.Ctrl.TranslateNode(ctx, id, language, description, resources)
+}
+
+// RemoveTranslation is the resolver for the removeTranslation field.
+func (r *mutationResolver) RemoveTranslation(ctx context.Context, id string, language string, resourcesOnly *bool) (*model.Status, error) {
+	return r.Ctrl.RemoveTranslation(ctx, id, language, resourcesOnly)
+}
+
+// CreateTranslationDrafts is the resolver for the createTranslationDrafts field.
+func (r *mutationResolver) CreateTranslationDrafts(ctx context.Context, nodeID string, languages []string) (*model.Status, error) {
+	return r.Ctrl.CreateTranslationDrafts(ctx, nodeID, languages)
+}
+
+// PromoteTranslationDraft is the resolver for the promoteTranslationDraft field.
+func (r *mutationResolver) PromoteTranslationDraft(ctx context.Context, nodeID string, language string) (*model.Status, error) {
+	return r.Ctrl.PromoteTranslationDraft(ctx, nodeID, language)
+}
+
 // SubmitVote is the resolver for the submitVote field.
 func (r *mutationResolver) SubmitVote(ctx context.Context, id string, value float64) (*model.Status, error) {
 	return r.Ctrl.SubmitVote(ctx, id, value)
@@ -43,6 +63,51 @@ func (r *mutationResolver) DeleteEdge(ctx context.Context, id string) (*model.St
 	return r.Ctrl.DeleteEdge(ctx, id)
 }
 
+// CreateTag is the resolver for the createTag field.
+func (r *mutationResolver) CreateTag(ctx context.Context, name model.Text) (*model.CreateEntityResult, error) {
+	return r.Ctrl.CreateTag(ctx, name)
+}
+
+// AddTagToNode is the resolver for the addTagToNode field.
+func (r *mutationResolver) AddTagToNode(ctx context.Context, nodeID string, tagID string) (*model.Status, error) {
+	return r.Ctrl.AddTagToNode(ctx, nodeID, tagID)
+}
+
+// RemoveTagFromNode is the resolver for the removeTagFromNode field.
+func (r *mutationResolver) RemoveTagFromNode(ctx context.Context, nodeID string, tagID string) (*model.Status, error) {
+	return r.Ctrl.RemoveTagFromNode(ctx, nodeID, tagID)
+}
+
+// CreateResource is the resolver for the createResource field.
+func (r *mutationResolver) CreateResource(ctx context.Context, nodeID string, resource model.ResourceInput) (*model.CreateEntityResult, error) {
+	return r.Ctrl.CreateResource(ctx, nodeID, resource)
+}
+
+// EditResource is the resolver for the editResource field.
+func (r *mutationResolver) EditResource(ctx context.Context, id string, resource model.ResourceInput) (*model.Status, error) {
+	return r.Ctrl.EditResource(ctx, id, resource)
+}
+
+// DeleteResource is the resolver for the deleteResource field.
+func (r *mutationResolver) DeleteResource(ctx context.Context, id string) (*model.Status, error) {
+	return r.Ctrl.DeleteResource(ctx, id)
+}
+
+// SubmitResourceVote is the resolver for the submitResourceVote field.
+func (r *mutationResolver) SubmitResourceVote(ctx context.Context, id string, value float64) (*model.Status, error) {
+	return r.Ctrl.SubmitResourceVote(ctx, id, value)
+}
+
+// PinNode is the resolver for the pinNode field.
+func (r *mutationResolver) PinNode(ctx context.Context, id string, position model.VectorInput) (*model.Status, error) {
+	return r.Ctrl.PinNode(ctx, id, position)
+}
+
+// UnpinNode is the resolver for the unpinNode field.
+func (r *mutationResolver) UnpinNode(ctx context.Context, id string) (*model.Status, error) {
+	return r.Ctrl.UnpinNode(ctx, id)
+}
+
 // CreateUserWithEMail is the resolver for the createUserWithEMail field.
 func (r *mutationResolver) CreateUserWithEMail(ctx context.Context, username string, password string, email string) (*model.CreateUserResult, error) {
 	result, err := r.Db.CreateUserWithEMail(ctx, username, password, email)
@@ -98,8 +163,18 @@ func (r *mutationResolver) DeleteAccount(ctx context.Context) (*model.Status, er
 }
 
 // Graph is the resolver for the graph field.
-func (r *queryResolver) Graph(ctx context.Context) (*model.Graph, error) {
-	return r.Ctrl.Graph(ctx)
+func (r *queryResolver) Graph(ctx context.Context, edgeTypes []model.EdgeType, tags []string) (*model.Graph, error) {
+	return r.Ctrl.Graph(ctx, edgeTypes, tags)
+}
+
+// GraphOverview is the resolver for the graphOverview field.
+func (r *queryResolver) GraphOverview(ctx context.Context, level int) (*model.GraphOverview, error) {
+	return r.Ctrl.GraphOverview(ctx, level)
+}
+
+// GraphViewport is the resolver for the graphViewport field.
+func (r *queryResolver) GraphViewport(ctx context.Context, rect model.RectInput, zoom *float64) (*model.Graph, error) {
+	return r.Ctrl.GraphViewport(ctx, rect, zoom)
 }
 
 // Resources is the resolver for the resources field.
@@ -122,6 +197,46 @@ func (r *queryResolver) EdgeEdits(ctx context.Context, edgeID string) ([]*model.
 	return r.Ctrl.EdgeEdits(ctx, edgeID)
 }
 
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context) ([]*model.Tag, error) {
+	return r.Ctrl.Tags(ctx)
+}
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, language *string, limit *int, tags []string) ([]*model.SearchResult, error) {
+	return r.Ctrl.Search(ctx, query, language, limit, tags)
+}
+
+// SimilarNodes is the resolver for the similarNodes field.
+func (r *queryResolver) SimilarNodes(ctx context.Context, description string, language *string) ([]*model.SimilarNode, error) {
+	return r.Ctrl.SimilarNodes(ctx, description, language)
+}
+
+// NodeResources is the resolver for the nodeResources field.
+func (r *queryResolver) NodeResources(ctx context.Context, nodeID string) ([]*model.Resource, error) {
+	return r.Ctrl.NodeResources(ctx, nodeID)
+}
+
+// ResourceEdits is the resolver for the resourceEdits field.
+func (r *queryResolver) ResourceEdits(ctx context.Context, resourceID string) ([]*model.ResourceEdit, error) {
+	return r.Ctrl.ResourceEdits(ctx, resourceID)
+}
+
+// TranslationStatus is the resolver for the translationStatus field.
+func (r *queryResolver) TranslationStatus(ctx context.Context, language string, offset *int, limit *int) (*model.TranslationStatus, error) {
+	return r.Ctrl.TranslationStatus(ctx, language, offset, limit)
+}
+
+// TranslationDrafts is the resolver for the translationDrafts field.
+func (r *queryResolver) TranslationDrafts(ctx context.Context, nodeID string) ([]*model.TranslationDraft, error) {
+	return r.Ctrl.TranslationDrafts(ctx, nodeID)
+}
+
+// LayoutStats is the resolver for the layoutStats field.
+func (r *queryResolver) LayoutStats(ctx context.Context) (*model.LayoutStats, error) {
+	return r.Ctrl.LayoutStats(ctx)
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
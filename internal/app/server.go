@@ -9,6 +9,7 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/caarlos0/env/v6"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/suxatcode/learn-graph-poc-backend/db"
@@ -17,6 +18,7 @@ import (
 	"github.com/suxatcode/learn-graph-poc-backend/graph/generated"
 	"github.com/suxatcode/learn-graph-poc-backend/internal/controller"
 	"github.com/suxatcode/learn-graph-poc-backend/middleware"
+	"github.com/suxatcode/learn-graph-poc-backend/translation"
 )
 
 const defaultPort = "8080"
@@ -49,7 +51,7 @@ func RetryAtIntervals(fn func() error, intervals []time.Duration) {
 	}
 }
 
-func graphHandler(conf db.Config) (http.Handler, db.DB) {
+func graphHandler(conf db.Config) (http.Handler, db.DB, error) {
 	var (
 		backend db.DB
 		err     error
@@ -66,14 +68,26 @@ func graphHandler(conf db.Config) (http.Handler, db.DB) {
 		5 * time.Second,
 		10 * time.Second,
 	})
-	ctrl := controller.NewController(backend, controller.NewLayouter())
+	translator, err := translation.NewTranslatorFromConfig(translation.GetEnvConfig())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid translation configuration")
+	}
+	layouterConf, err := controller.GetLayouterEnvConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid layout configuration")
+	}
+	layouter, err := controller.NewLayouter(backend, layouterConf)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid layout configuration")
+	}
+	ctrl := controller.NewController(backend, layouter).WithTranslator(translator).WithLanguageNormalizer(db.NewLanguageNormalizer(conf)).WithLayoutTimeout(layouterConf.Timeout)
 	go ctrl.PeriodicGraphEmbeddingComputation(context.Background())
 	return middleware.AddAll(handler.NewDefaultServer(
 		generated.NewExecutableSchema(generated.Config{Resolvers: &graph.Resolver{
 			Db:   backend, /*TODO(skep): to be removed once all calls go through controller*/
 			Ctrl: ctrl,
 		}}),
-	)), backend
+	)), backend, nil
 }
 
 func runGQLServer() {
@@ -99,7 +113,10 @@ func runGQLServer() {
 	}
 	dbconf := db.GetEnvConfig()
 	log.Info().Msgf("Config: %#v", dbconf)
-	graphQLhandler, _ := graphHandler(dbconf)
+	graphQLhandler, _, err := graphHandler(dbconf)
+	if err != nil {
+		log.Fatal().Msgf("%v", err)
+	}
 	handler.Handle("/query", graphQLhandler)
 	server := http.Server{
 		Addr:         ":" + port,
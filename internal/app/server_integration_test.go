@@ -242,7 +242,10 @@ func TestGraphQLHandlers(t *testing.T) {
 		//},
 	} {
 		t.Run(test.Name, func(t *testing.T) {
-			handler, _ := graphHandler(postgres.TESTONLY_Config)
+			handler, _, err := graphHandler(postgres.TESTONLY_Config)
+			if !assert.NoError(t, err) {
+				return
+			}
 			postgres.TESTONLY_SetupAndCleanup(t)
 			s := httptest.NewServer(handler)
 			defer s.Close()
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"github.com/suxatcode/learn-graph-poc-backend/layout"
+)
+
+// ClusterResolution controls the size of the clusters, higher values yield
+// more and smaller clusters, see layout.Communities
+var ClusterResolution = 1.0
+
+// clusterGraph returns node ID → cluster of densely connected nodes, edges
+// pull their nodes together by their voted weight
+func clusterGraph(g *model.Graph) map[string]int {
+	lookup := make(map[string]int, len(g.Nodes))
+	for i, node := range g.Nodes {
+		lookup[node.ID] = i
+	}
+	edges := make([]*layout.Edge, 0, len(g.Edges))
+	for _, edge := range g.Edges {
+		from, okFrom := lookup[edge.From]
+		to, okTo := lookup[edge.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		edges = append(edges, &layout.Edge{Source: from, Target: to, Value: edge.Weight})
+	}
+	communities := layout.Communities(len(g.Nodes), edges, ClusterResolution)
+	clusters := make(map[string]int, len(g.Nodes))
+	for i, node := range g.Nodes {
+		clusters[node.ID] = communities[i]
+	}
+	return clusters
+}
+
+// updateClusters recomputes the clusters of all nodes and assigns them to g
+func (c *Controller) updateClusters(g *model.Graph) {
+	clusters := clusterGraph(g)
+	c.clustersLock.Lock()
+	c.clusters = clusters
+	c.clustersLock.Unlock()
+	c.assignClusters(g)
+}
+
+// assignClusters sets the cluster of each node of g known from the last
+// computation, new nodes have none until the next one
+func (c *Controller) assignClusters(g *model.Graph) {
+	c.clustersLock.RLock()
+	defer c.clustersLock.RUnlock()
+	for _, node := range g.Nodes {
+		if cluster, exists := c.clusters[node.ID]; exists {
+			cluster := cluster
+			node.Cluster = &cluster
+		}
+	}
+}
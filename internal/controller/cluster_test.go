@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+func TestClusterGraph(t *testing.T) {
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}, {ID: "f"}, {ID: "g"}},
+		Edges: []*model.Edge{
+			{From: "a", To: "b", Weight: 5},
+			{From: "b", To: "c", Weight: 5},
+			{From: "c", To: "a", Weight: 5},
+			{From: "d", To: "e", Weight: 5},
+			{From: "e", To: "f", Weight: 5},
+			{From: "f", To: "d", Weight: 5},
+			{From: "c", To: "d", Weight: 1},
+			{From: "a", To: "unknown", Weight: 5},
+		},
+	}
+	assert.Equal(t, map[string]int{"a": 0, "b": 0, "c": 0, "d": 1, "e": 1, "f": 1, "g": 2}, clusterGraph(g))
+}
+
+func TestController_assignClusters(t *testing.T) {
+	c := &Controller{}
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "a"}, {ID: "b"}},
+		Edges: []*model.Edge{{From: "a", To: "b", Weight: 1}},
+	}
+	c.updateClusters(g)
+	assert.Equal(t, []*model.Node{{ID: "a", Cluster: intPtr(0)}, {ID: "b", Cluster: intPtr(0)}}, g.Nodes)
+
+	withNewNode := &model.Graph{Nodes: []*model.Node{{ID: "a"}, {ID: "new"}}}
+	c.assignClusters(withNewNode)
+	assert.Equal(t, []*model.Node{{ID: "a", Cluster: intPtr(0)}, {ID: "new"}}, withNewNode.Nodes)
+}
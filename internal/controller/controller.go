@@ -3,38 +3,84 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/suxatcode/learn-graph-poc-backend/db"
 	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"github.com/suxatcode/learn-graph-poc-backend/layout"
 	"github.com/suxatcode/learn-graph-poc-backend/middleware"
+	"github.com/suxatcode/learn-graph-poc-backend/translation"
 )
 
 const (
 	AuthNeededForGraphDataChangeMsg = `only logged in user may create graph data`
+	AdminNeededMsg                  = `only admins may access this`
+	// CreateNode refuses to create a node, if an existing node has a
+	// description at least this similar, unless forced
+	DuplicateNodeSimilarity   = 0.85
+	SimilarNodesMinSimilarity = 0.5
+	SimilarNodesLimit         = 10
 )
 
 var (
 	AuthNeededForGraphDataChangeErr    = errors.New(AuthNeededForGraphDataChangeMsg)
 	AuthNeededForGraphDataChangeStatus = &model.Status{Message: AuthNeededForGraphDataChangeMsg}
 	AuthNeededForGraphDataChangeResult = &model.CreateEntityResult{Status: AuthNeededForGraphDataChangeStatus}
+	AdminNeededErr                     = errors.New(AdminNeededMsg)
 )
 
 type Controller struct {
 	db           db.DB
 	layouter     Layouter
+	translator   *translation.Translator
+	languages    db.LanguageNormalizer
 	graphChanges chan time.Time
+	// layoutTimeout limits a single run of the graph embedding computation
+	layoutTimeout time.Duration
+	// layoutStats of the last graph embedding computation, nil if none
+	// finished yet
+	layoutStats     *model.LayoutStats
+	layoutStatsLock sync.RWMutex
+	// clusters maps node IDs to the cluster computed with the last graph
+	// embedding
+	clusters     map[string]int
+	clustersLock sync.RWMutex
 }
 
 func NewController(newdb db.DB, newlayouter Layouter) *Controller {
 	return &Controller{
 		db: newdb, layouter: newlayouter,
-		graphChanges: make(chan time.Time, 1),
+		translator:    translation.NewTranslator(translation.NoopProvider{}, nil),
+		graphChanges:  make(chan time.Time, 1),
+		layoutTimeout: time.Minute * 5,
 	}
 }
 
-func (c *Controller) CreateNode(ctx context.Context, description model.Text, resources *model.Text) (*model.CreateEntityResult, error) {
+// WithLayoutTimeout limits a single run of the graph embedding computation
+func (c *Controller) WithLayoutTimeout(timeout time.Duration) *Controller {
+	c.layoutTimeout = timeout
+	return c
+}
+
+// WithTranslator sets the translator used for machine translated drafts
+func (c *Controller) WithTranslator(translator *translation.Translator) *Controller {
+	c.translator = translator
+	return c
+}
+
+// WithLanguageNormalizer sets the normalizer for languages passed to the
+// controller, it should match the one of the DB
+func (c *Controller) WithLanguageNormalizer(languages db.LanguageNormalizer) *Controller {
+	c.languages = languages
+	return c
+}
+
+func (c *Controller) CreateNode(ctx context.Context, description model.Text, resources *model.Text, force *bool) (*model.CreateEntityResult, error) {
 	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
 	if err != nil || !authenticated || user == nil {
 		if err != nil {
@@ -44,6 +90,24 @@ func (c *Controller) CreateNode(ctx context.Context, description model.Text, res
 		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
 		return AuthNeededForGraphDataChangeResult, AuthNeededForGraphDataChangeErr
 	}
+	if force == nil || !*force {
+		similar, err := c.db.SimilarNodes(ctx, &description, DuplicateNodeSimilarity, SimilarNodesLimit)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		if len(similar) > 0 {
+			ids := make([]string, 0, len(similar))
+			for _, s := range similar {
+				ids = append(ids, s.Node.ID)
+			}
+			res := &model.CreateEntityResult{Status: &model.Status{
+				Message: fmt.Sprintf("similar nodes exist (IDs: %s), use force to create the node anyway", strings.Join(ids, ", ")),
+			}}
+			log.Ctx(ctx).Debug().Msgf("CreateNode() -> %v", res)
+			return res, nil
+		}
+	}
 	id, err := c.db.CreateNode(ctx, *user, &description, resources)
 	if err != nil {
 		log.Ctx(ctx).Error().Msgf("%v", err)
@@ -55,7 +119,7 @@ func (c *Controller) CreateNode(ctx context.Context, description model.Text, res
 	return res, nil
 }
 
-func (c *Controller) CreateEdge(ctx context.Context, from string, to string, weight float64) (*model.CreateEntityResult, error) {
+func (c *Controller) CreateEdge(ctx context.Context, from string, to string, weight float64, edgeType *model.EdgeType) (*model.CreateEntityResult, error) {
 	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
 	if err != nil || !authenticated || user == nil {
 		if err != nil {
@@ -65,7 +129,11 @@ func (c *Controller) CreateEdge(ctx context.Context, from string, to string, wei
 		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
 		return AuthNeededForGraphDataChangeResult, AuthNeededForGraphDataChangeErr
 	}
-	ID, err := c.db.CreateEdge(ctx, *user, from, to, weight)
+	if edgeType == nil {
+		prerequisite := model.EdgeTypePrerequisite
+		edgeType = &prerequisite
+	}
+	ID, err := c.db.CreateEdge(ctx, *user, from, to, weight, *edgeType)
 	if err != nil {
 		log.Ctx(ctx).Error().Msgf("%v", err)
 		return nil, err
@@ -96,6 +164,167 @@ func (c *Controller) EditNode(ctx context.Context, id string, description model.
 	return nil, nil
 }
 
+func (c *Controller) TranslateNode(ctx context.Context, id string, language string, description string, resources *string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.TranslateNode(ctx, *user, id, language, description, resources)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("TranslateNode() -> %v", nil)
+	return nil, nil
+}
+
+func (c *Controller) RemoveTranslation(ctx context.Context, id string, language string, resourcesOnly *bool) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.RemoveTranslation(ctx, *user, id, language, resourcesOnly != nil && *resourcesOnly)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("RemoveTranslation() -> %v", nil)
+	return nil, nil
+}
+
+const (
+	// CreateTranslationDrafts refuses longer lists of languages, to limit the
+	// requests to the translation provider
+	TranslationDraftsMaxLanguages = 20
+	// number of drafts requested from the translation provider at once
+	TranslationDraftsConcurrency = 4
+)
+
+// CreateTranslationDrafts creates machine translated drafts for each of the
+// languages, which the node has no description in yet. Up to
+// TranslationDraftsConcurrency languages are translated concurrently.
+func (c *Controller) CreateTranslationDrafts(ctx context.Context, nodeID string, languages []string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	if len(languages) > TranslationDraftsMaxLanguages {
+		err := fmt.Errorf("at most %d languages can be translated at once, got %d", TranslationDraftsMaxLanguages, len(languages))
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	description, resources, err := c.db.NodeTexts(ctx, nodeID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	missing := []string{}
+	for _, language := range languages {
+		language, err := c.languages.Normalize(language)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		if _, ok := description[language]; ok || db.Contains(missing, language) {
+			continue
+		}
+		missing = append(missing, language)
+	}
+	drafts := make([]*translation.Draft, len(missing))
+	errs := make([]error, len(missing))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for worker := 0; worker < TranslationDraftsConcurrency && worker < len(missing); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				drafts[i], errs[i] = c.translator.Draft(ctx, description, resources, missing[i])
+			}
+		}()
+	}
+	for i := range missing {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	created := 0
+	for i, language := range missing {
+		draft, err := drafts[i], errs[i]
+		if errors.Is(err, translation.ErrNotSupported) {
+			log.Ctx(ctx).Warn().Msgf("no machine translation for node %s to '%s': %v", nodeID, language, err)
+			continue
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		err = c.db.SaveTranslationDraft(ctx, nodeID, &model.TranslationDraft{
+			Language:    draft.Language,
+			Description: draft.Description,
+			Resources:   draft.Resources,
+			Machine:     true,
+			Provider:    draft.Provider,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		created++
+	}
+	if created == 0 {
+		status := &model.Status{Message: "no translation drafts created"}
+		log.Ctx(ctx).Debug().Msgf("CreateTranslationDrafts() -> %v", status)
+		return status, nil
+	}
+	log.Ctx(ctx).Debug().Msgf("CreateTranslationDrafts() -> %d drafts", created)
+	return nil, nil
+}
+
+func (c *Controller) PromoteTranslationDraft(ctx context.Context, nodeID string, language string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.PromoteTranslationDraft(ctx, *user, nodeID, language)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("PromoteTranslationDraft() -> %v", nil)
+	return nil, nil
+}
+
+func (c *Controller) TranslationDrafts(ctx context.Context, nodeID string) ([]*model.TranslationDraft, error) {
+	drafts, err := c.db.TranslationDrafts(ctx, nodeID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("TranslationDrafts() -> %d drafts", len(drafts))
+	return drafts, nil
+}
+
 func (c *Controller) SubmitVote(ctx context.Context, id string, value float64) (*model.Status, error) {
 	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
 	if err != nil || !authenticated || user == nil {
@@ -112,20 +341,53 @@ func (c *Controller) SubmitVote(ctx context.Context, id string, value float64) (
 		return nil, err
 	}
 	log.Ctx(ctx).Debug().Msgf("SubmitVote() -> %v", nil)
+	c.graphChanged()
 	return nil, nil
 }
 
-func (c *Controller) Graph(ctx context.Context) (*model.Graph, error) {
+// Graph returns the whole graph, if edgeTypes is non-empty only edges of
+// these types are returned, if tags is non-empty only nodes with at least one
+// of these tag IDs (and the edges between them) are returned.
+func (c *Controller) Graph(ctx context.Context, edgeTypes []model.EdgeType, tags []string) (*model.Graph, error) {
 	g, err := c.db.Graph(ctx)
 	if err != nil || g == nil {
 		log.Ctx(ctx).Error().Msgf("%v | graph=%v", err, g)
 	} else if g != nil {
+		c.assignClusters(g)
 		c.layouter.GetNodePositions(ctx, g)
+		filterEdgesByType(g, edgeTypes)
+		filterNodesByTag(g, tags)
 		log.Ctx(ctx).Debug().Msgf("Graph() returns %d nodes and %d edges", len(g.Nodes), len(g.Edges))
 	}
 	return g, err
 }
 
+func filterEdgesByType(g *model.Graph, edgeTypes []model.EdgeType) {
+	if len(edgeTypes) == 0 || g.Edges == nil {
+		return
+	}
+	g.Edges = db.FindAll(g.Edges, func(edge *model.Edge) bool {
+		return db.Contains(edgeTypes, edge.Type)
+	})
+}
+
+func filterNodesByTag(g *model.Graph, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	keep := map[string]bool{}
+	for _, node := range g.Nodes {
+		for _, tag := range node.Tags {
+			if db.Contains(tags, tag.ID) {
+				keep[node.ID] = true
+				break
+			}
+		}
+	}
+	g.Nodes = db.FindAll(g.Nodes, func(node *model.Node) bool { return keep[node.ID] })
+	g.Edges = db.FindAll(g.Edges, func(edge *model.Edge) bool { return keep[edge.From] && keep[edge.To] })
+}
+
 func (c *Controller) DeleteNode(ctx context.Context, id string) (*model.Status, error) {
 	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
 	if err != nil || !authenticated || user == nil {
@@ -186,6 +448,244 @@ func (c *Controller) EdgeEdits(ctx context.Context, id string) ([]*model.EdgeEdi
 	return edits, nil
 }
 
+func (c *Controller) Tags(ctx context.Context) ([]*model.Tag, error) {
+	tags, err := c.db.Tags(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("Tags() -> %d tags", len(tags))
+	return tags, nil
+}
+
+func (c *Controller) CreateTag(ctx context.Context, name model.Text) (*model.CreateEntityResult, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeResult, AuthNeededForGraphDataChangeErr
+	}
+	id, err := c.db.CreateTag(ctx, *user, &name)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	res := &model.CreateEntityResult{ID: id}
+	log.Ctx(ctx).Debug().Msgf("CreateTag() -> %v", res)
+	return res, nil
+}
+
+func (c *Controller) AddTagToNode(ctx context.Context, nodeID, tagID string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.AddNodeTag(ctx, *user, nodeID, tagID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("AddTagToNode() -> %v", nil)
+	return nil, nil
+}
+
+func (c *Controller) RemoveTagFromNode(ctx context.Context, nodeID, tagID string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.RemoveNodeTag(ctx, *user, nodeID, tagID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("RemoveTagFromNode() -> %v", nil)
+	return nil, nil
+}
+
+func (c *Controller) NodeResources(ctx context.Context, nodeID string) ([]*model.Resource, error) {
+	resources, err := c.db.NodeResources(ctx, nodeID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("NodeResources() -> %v", resources)
+	return resources, nil
+}
+
+func (c *Controller) ResourceEdits(ctx context.Context, id string) ([]*model.ResourceEdit, error) {
+	edits, err := c.db.ResourceEdits(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("ResourceEdits() -> %v", edits)
+	return edits, nil
+}
+
+func (c *Controller) CreateResource(ctx context.Context, nodeID string, resource model.ResourceInput) (*model.CreateEntityResult, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeResult, AuthNeededForGraphDataChangeErr
+	}
+	id, err := c.db.CreateResource(ctx, *user, nodeID, resource)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	res := &model.CreateEntityResult{ID: id}
+	log.Ctx(ctx).Debug().Msgf("CreateResource() -> %v", res)
+	return res, nil
+}
+
+func (c *Controller) EditResource(ctx context.Context, id string, resource model.ResourceInput) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.EditResource(ctx, *user, id, resource)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("EditResource() -> %v", nil)
+	return nil, nil
+}
+
+func (c *Controller) DeleteResource(ctx context.Context, id string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.DeleteResource(ctx, *user, id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("DeleteResource() -> %v", nil)
+	return nil, nil
+}
+
+func (c *Controller) SubmitResourceVote(ctx context.Context, id string, value float64) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil || !authenticated || user == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') not authenticated", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return AuthNeededForGraphDataChangeStatus, AuthNeededForGraphDataChangeErr
+	}
+	err = c.db.AddResourceVote(ctx, *user, id, value)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("SubmitResourceVote() -> %v", nil)
+	return nil, nil
+}
+
+// SimilarNodes returns nodes with a description similar to description,
+// language defaults to the language of the request.
+func (c *Controller) SimilarNodes(ctx context.Context, description string, language *string) ([]*model.SimilarNode, error) {
+	lang := middleware.CtxGetLanguage(ctx)
+	if language != nil && *language != "" {
+		lang = *language
+	}
+	text := &model.Text{Translations: []*model.Translation{{Language: lang, Content: description}}}
+	similar, err := c.db.SimilarNodes(ctx, text, SimilarNodesMinSimilarity, SimilarNodesLimit)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("SimilarNodes(%s) -> %d nodes", description, len(similar))
+	return similar, nil
+}
+
+const (
+	SearchDefaultLimit = 20
+	SearchMaxLimit     = 100
+)
+
+// Search performs a full text search, language defaults to the language of
+// the request.
+func (c *Controller) Search(ctx context.Context, query string, language *string, limit *int, tags []string) ([]*model.SearchResult, error) {
+	lang := middleware.CtxGetLanguage(ctx)
+	if language != nil && *language != "" {
+		lang = *language
+	}
+	n := SearchDefaultLimit
+	if limit != nil && *limit > 0 {
+		n = *limit
+	}
+	if n > SearchMaxLimit {
+		n = SearchMaxLimit
+	}
+	results, err := c.db.Search(ctx, query, lang, n, tags)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("Search(%s) -> %d results", query, len(results))
+	return results, nil
+}
+
+const (
+	TranslationStatusDefaultLimit = 20
+	TranslationStatusMaxLimit     = 100
+)
+
+// TranslationStatus returns the translation coverage of language and a page
+// of the untranslated nodes.
+func (c *Controller) TranslationStatus(ctx context.Context, language string, offset *int, limit *int) (*model.TranslationStatus, error) {
+	n := TranslationStatusDefaultLimit
+	if limit != nil && *limit > 0 {
+		n = *limit
+	}
+	if n > TranslationStatusMaxLimit {
+		n = TranslationStatusMaxLimit
+	}
+	skip := 0
+	if offset != nil && *offset > 0 {
+		skip = *offset
+	}
+	status, err := c.db.TranslationStatus(ctx, language, skip, n)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("TranslationStatus(%s) -> %d untranslated nodes", language, status.UntranslatedNodeCount)
+	return status, nil
+}
+
 // PeriodicGraphEmbeddingComputation periodically calls c.layouter.Reload() to
 // re-compute the graph embedding.
 func (c *Controller) PeriodicGraphEmbeddingComputation(ctx context.Context) {
@@ -196,8 +696,91 @@ func (c *Controller) PeriodicGraphEmbeddingComputation(ctx context.Context) {
 	//defer ticker.Stop()
 	//trigger := ticker.C
 	trigger := c.graphChanges
-	singleRunTimeout := time.Minute * 5 // TODO(skep): pass it as config to controller, to configure it via env variables!
-	c.periodicGraphEmbeddingComputation(ctx, trigger, singleRunTimeout)
+	c.periodicGraphEmbeddingComputation(ctx, trigger, c.layoutTimeout)
+}
+
+func (c *Controller) setLayoutStats(stats layout.Stats) {
+	c.layoutStatsLock.Lock()
+	defer c.layoutStatsLock.Unlock()
+	c.layoutStats = &model.LayoutStats{
+		FinishedAt:            time.Now(),
+		Iterations:            stats.Iterations,
+		TotalTimeMilliseconds: int(stats.TotalTime.Milliseconds()),
+		Converged:             stats.Converged,
+		TimedOut:              stats.TimedOut,
+		Energy:                stats.Energy,
+		AverageDisplacement:   stats.AverageDisplacement,
+		MaxDisplacement:       stats.MaxDisplacement,
+		EdgeLengthStress:      stats.EdgeLengthStress,
+		EdgeCrossings:         stats.EdgeCrossings,
+	}
+}
+
+// LayoutStats returns statistics of the last graph embedding computation to
+// admins.
+func (c *Controller) LayoutStats(ctx context.Context) (*model.LayoutStats, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	if !authenticated || user == nil || !user.HasRole(db.RoleAdmin) {
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') is not an admin", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return nil, AdminNeededErr
+	}
+	c.layoutStatsLock.RLock()
+	defer c.layoutStatsLock.RUnlock()
+	log.Ctx(ctx).Debug().Msgf("LayoutStats() -> %v", c.layoutStats)
+	return c.layoutStats, nil
+}
+
+// PinNode fixes the position of a node in the graph embedding, only admins
+// may pin nodes.
+func (c *Controller) PinNode(ctx context.Context, id string, position model.VectorInput) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	if !authenticated || user == nil || !user.HasRole(db.RoleAdmin) {
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') is not an admin", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return nil, AdminNeededErr
+	}
+	pos := model.Vector{X: position.X, Y: position.Y}
+	if position.Z != nil {
+		pos.Z = *position.Z
+	}
+	if !isFinite(pos.X, pos.Y, pos.Z) {
+		return nil, fmt.Errorf("invalid position %v", pos)
+	}
+	if err := c.db.PinNode(ctx, *user, id, pos); err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	c.graphChanged()
+	log.Ctx(ctx).Debug().Msgf("PinNode() -> %v", nil)
+	return nil, nil
+}
+
+// UnpinNode lets the graph embedding place a pinned node again, only admins
+// may unpin nodes.
+func (c *Controller) UnpinNode(ctx context.Context, id string) (*model.Status, error) {
+	authenticated, user, err := c.db.IsUserAuthenticated(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	if !authenticated || user == nil || !user.HasRole(db.RoleAdmin) {
+		log.Ctx(ctx).Error().Msgf("user '%s' (token '%s') is not an admin", middleware.CtxGetUserID(ctx), middleware.CtxGetAuthentication(ctx))
+		return nil, AdminNeededErr
+	}
+	if err := c.db.UnpinNode(ctx, *user, id); err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	c.graphChanged()
+	log.Ctx(ctx).Debug().Msgf("UnpinNode() -> %v", nil)
+	return nil, nil
 }
 
 func (c *Controller) graphChanged() {
@@ -216,11 +799,13 @@ func (c *Controller) periodicGraphEmbeddingComputation(ctx context.Context, trig
 		return g
 	}
 	reload := func(ctx context.Context, g *model.Graph) {
+		c.updateClusters(g)
 		stats := c.layouter.Reload(ctx, g)
 		if stats.Iterations == 0 {
 			// no graph embedding happened, probably nothing new to compute
 			return
 		}
+		c.setLayoutStats(stats)
 		log.Info().Msgf(
 			"periodic graph layout computaton finished: stats{iterations: %d, time: %d ms}",
 			stats.Iterations,
@@ -248,3 +833,12 @@ func (c *Controller) periodicGraphEmbeddingComputation(ctx context.Context, trig
 		}
 	}
 }
+
+func isFinite(values ...float64) bool {
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"bytes"
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/suxatcode/learn-graph-poc-backend/db"
 	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
 	"github.com/suxatcode/learn-graph-poc-backend/layout"
+	"github.com/suxatcode/learn-graph-poc-backend/middleware"
+	"github.com/suxatcode/learn-graph-poc-backend/translation"
 )
 
 var (
@@ -25,12 +28,17 @@ func TestController_CreateNode(t *testing.T) {
 		MockExpectations func(context.Context, db.MockDB)
 		ExpectRes        *model.CreateEntityResult
 		ExpectErr        bool
+		ExpectNoChange   bool
 		Description      model.Text
+		Force            *bool
 	}{
 		{
 			Name: "user authenticated, node created",
 			MockExpectations: func(ctx context.Context, mock db.MockDB) {
 				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().SimilarNodes(ctx, &model.Text{Translations: []*model.Translation{
+					{Language: "en", Content: "ok"},
+				}}, DuplicateNodeSimilarity, SimilarNodesLimit).Return([]*model.SimilarNode{}, nil)
 				mock.EXPECT().CreateNode(ctx, user444, &model.Text{Translations: []*model.Translation{
 					{Language: "en", Content: "ok"},
 				}}, nil).Return("123", nil)
@@ -40,6 +48,32 @@ func TestController_CreateNode(t *testing.T) {
 			}},
 			ExpectRes: &model.CreateEntityResult{ID: "123", Status: nil},
 		},
+		{
+			Name: "similar node exists, no node created",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().SimilarNodes(ctx, gomock.Any(), DuplicateNodeSimilarity, SimilarNodesLimit).Return([]*model.SimilarNode{
+					{Node: &model.Node{ID: "7", Description: &model.LocalizedString{Text: "Ok", Language: "en"}}, Similarity: 1.0},
+				}, nil)
+			},
+			Description: model.Text{Translations: []*model.Translation{
+				{Language: "en", Content: "ok"},
+			}},
+			ExpectRes:      &model.CreateEntityResult{Status: &model.Status{Message: "similar nodes exist (IDs: 7), use force to create the node anyway"}},
+			ExpectNoChange: true,
+		},
+		{
+			Name: "similar node exists, node created with force",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().CreateNode(ctx, user444, gomock.Any(), nil).Return("123", nil)
+			},
+			Description: model.Text{Translations: []*model.Translation{
+				{Language: "en", Content: "ok"},
+			}},
+			Force:     boolPtr(true),
+			ExpectRes: &model.CreateEntityResult{ID: "123", Status: nil},
+		},
 		{
 			Name: "user not authenticated, no node created",
 			MockExpectations: func(ctx context.Context, mock db.MockDB) {
@@ -56,12 +90,15 @@ func TestController_CreateNode(t *testing.T) {
 			ctx := context.Background()
 			test.MockExpectations(ctx, *db)
 			c := NewController(db, nil)
-			id, err := c.CreateNode(ctx, test.Description, nil)
+			id, err := c.CreateNode(ctx, test.Description, nil, test.Force)
 			assert := assert.New(t)
 			assert.Equal(test.ExpectRes, id)
 			if test.ExpectErr {
 				assert.Error(err)
 				assert.Equal(0, countChannel(c.graphChanges))
+			} else if test.ExpectNoChange {
+				assert.NoError(err)
+				assert.Equal(0, countChannel(c.graphChanges))
 			} else {
 				assert.NoError(err)
 				assert.Equal(1, countChannel(c.graphChanges))
@@ -76,13 +113,23 @@ func TestController_CreateEdge(t *testing.T) {
 		MockExpectations func(context.Context, db.MockDB)
 		ExpectRes        *model.CreateEntityResult
 		ExpectErr        bool
+		EdgeType         *model.EdgeType
 	}{
 		{
 			Name: "user authenticated, edge created",
 			MockExpectations: func(ctx context.Context, mock db.MockDB) {
 				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
-				mock.EXPECT().CreateEdge(ctx, user444, "1", "2", 42.42).Return("123", nil)
+				mock.EXPECT().CreateEdge(ctx, user444, "1", "2", 42.42, model.EdgeTypePrerequisite).Return("123", nil)
+			},
+			ExpectRes: &model.CreateEntityResult{ID: "123", Status: nil},
+		},
+		{
+			Name: "user authenticated, edge with explicit type created",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().CreateEdge(ctx, user444, "1", "2", 42.42, model.EdgeTypeRelatedTo).Return("123", nil)
 			},
+			EdgeType:  edgeTypePtr(model.EdgeTypeRelatedTo),
 			ExpectRes: &model.CreateEntityResult{ID: "123", Status: nil},
 		},
 		{
@@ -101,7 +148,7 @@ func TestController_CreateEdge(t *testing.T) {
 			ctx := context.Background()
 			test.MockExpectations(ctx, *db)
 			c := NewController(db, nil)
-			id, err := c.CreateEdge(ctx, "1", "2", 42.42)
+			id, err := c.CreateEdge(ctx, "1", "2", 42.42, test.EdgeType)
 			assert := assert.New(t)
 			assert.Equal(test.ExpectRes, id)
 			if test.ExpectErr {
@@ -169,6 +216,249 @@ func TestController_EditNode(t *testing.T) {
 	}
 }
 
+func TestController_TranslateNode(t *testing.T) {
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.Status
+		ExpectErr        bool
+	}{
+		{
+			Name: "user authenticated, node translated",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().TranslateNode(ctx, user444, "123", "de", "Algebra", nil).Return(nil)
+			},
+		},
+		{
+			Name: "user authenticated, node already translated",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().TranslateNode(ctx, user444, "123", "de", "Algebra", nil).Return(errors.New("already translated"))
+			},
+			ExpectErr: true,
+		},
+		{
+			Name: "user not authenticated, node not translated",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectErr: true,
+			ExpectRes: AuthNeededForGraphDataChangeStatus,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			status, err := c.TranslateNode(ctx, "123", "de", "Algebra", nil)
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, status)
+			if test.ExpectErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestController_RemoveTranslation(t *testing.T) {
+	for _, test := range []struct {
+		Name             string
+		ResourcesOnly    *bool
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.Status
+		ExpectErr        bool
+	}{
+		{
+			Name: "user authenticated, translation removed",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().RemoveTranslation(ctx, user444, "123", "de", false).Return(nil)
+			},
+		},
+		{
+			Name:          "user authenticated, resources removed",
+			ResourcesOnly: boolPtr(true),
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().RemoveTranslation(ctx, user444, "123", "de", true).Return(nil)
+			},
+		},
+		{
+			Name: "user authenticated, last description not removed",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().RemoveTranslation(ctx, user444, "123", "de", false).Return(errors.New("last description"))
+			},
+			ExpectErr: true,
+		},
+		{
+			Name: "user not authenticated, translation not removed",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectErr: true,
+			ExpectRes: AuthNeededForGraphDataChangeStatus,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			status, err := c.RemoveTranslation(ctx, "123", "de", test.ResourcesOnly)
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, status)
+			if test.ExpectErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestController_CreateTranslationDrafts(t *testing.T) {
+	translator := translation.NewTranslator(translation.DictionaryProvider{
+		"en": {"de": {"Algebra": "Algebra (de)"}, "fr": {"Algebra": "Algèbre"}},
+	}, []string{"en"})
+	for _, test := range []struct {
+		Name             string
+		Languages        []string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.Status
+		ExpectErr        bool
+	}{
+		{
+			Name:      "drafts for missing languages created",
+			Languages: []string{"de", "fr", "en"},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().NodeTexts(ctx, "1").Return(db.Text{"en": "Algebra"}, nil, nil)
+				mock.EXPECT().SaveTranslationDraft(ctx, "1", &model.TranslationDraft{Language: "de", Description: "Algebra (de)", Machine: true, Provider: "dictionary"}).Return(nil)
+				mock.EXPECT().SaveTranslationDraft(ctx, "1", &model.TranslationDraft{Language: "fr", Description: "Algèbre", Machine: true, Provider: "dictionary"}).Return(nil)
+			},
+		},
+		{
+			Name:      "languages are normalized",
+			Languages: []string{"DE", "de", "EN"},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().NodeTexts(ctx, "1").Return(db.Text{"en": "Algebra"}, nil, nil)
+				mock.EXPECT().SaveTranslationDraft(ctx, "1", &model.TranslationDraft{Language: "de", Description: "Algebra (de)", Machine: true, Provider: "dictionary"}).Return(nil)
+			},
+		},
+		{
+			Name:      "invalid language",
+			Languages: []string{"de", "german"},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().NodeTexts(ctx, "1").Return(db.Text{"en": "Algebra"}, nil, nil)
+			},
+			ExpectErr: true,
+		},
+		{
+			Name:      "more languages than concurrent translations",
+			Languages: []string{"ja", "de", "ko", "it", "es", "fr"},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().NodeTexts(ctx, "1").Return(db.Text{"en": "Algebra"}, nil, nil)
+				gomock.InOrder(
+					mock.EXPECT().SaveTranslationDraft(ctx, "1", &model.TranslationDraft{Language: "de", Description: "Algebra (de)", Machine: true, Provider: "dictionary"}).Return(nil),
+					mock.EXPECT().SaveTranslationDraft(ctx, "1", &model.TranslationDraft{Language: "fr", Description: "Algèbre", Machine: true, Provider: "dictionary"}).Return(nil),
+				)
+			},
+		},
+		{
+			Name:      "too many languages",
+			Languages: make([]string, TranslationDraftsMaxLanguages+1),
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+			},
+			ExpectErr: true,
+		},
+		{
+			Name:      "unsupported languages are skipped",
+			Languages: []string{"ja"},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().NodeTexts(ctx, "1").Return(db.Text{"en": "Algebra"}, nil, nil)
+			},
+			ExpectRes: &model.Status{Message: "no translation drafts created"},
+		},
+		{
+			Name:      "user not authenticated",
+			Languages: []string{"de"},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectRes: AuthNeededForGraphDataChangeStatus,
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *mock)
+			c := NewController(mock, nil).WithTranslator(translator)
+			status, err := c.CreateTranslationDrafts(ctx, "1", test.Languages)
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, status)
+			if test.ExpectErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestController_PromoteTranslationDraft(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := db.NewMockDB(ctrl)
+	ctx := context.Background()
+	mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+	mock.EXPECT().PromoteTranslationDraft(ctx, user444, "1", "de").Return(nil)
+	c := NewController(mock, nil)
+	status, err := c.PromoteTranslationDraft(ctx, "1", "de")
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestController_TranslationStatus(t *testing.T) {
+	five, negative, tooMany := 5, -1, 1000
+	for _, test := range []struct {
+		Name      string
+		Offset    *int
+		Limit     *int
+		ExpOffset int
+		ExpLimit  int
+	}{
+		{Name: "defaults", ExpOffset: 0, ExpLimit: TranslationStatusDefaultLimit},
+		{Name: "explicit offset & limit", Offset: &five, Limit: &five, ExpOffset: 5, ExpLimit: 5},
+		{Name: "negative offset is ignored", Offset: &negative, ExpOffset: 0, ExpLimit: TranslationStatusDefaultLimit},
+		{Name: "limit is capped", Limit: &tooMany, ExpOffset: 0, ExpLimit: TranslationStatusMaxLimit},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			expected := &model.TranslationStatus{Language: "de", NodeCount: 2, DescriptionPercentage: 50.0}
+			mock.EXPECT().TranslationStatus(ctx, "de", test.ExpOffset, test.ExpLimit).Return(expected, nil)
+			c := NewController(mock, nil)
+			status, err := c.TranslationStatus(ctx, "de", test.Offset, test.Limit)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, status)
+		})
+	}
+}
+
 func TestController_EditNode_ShouldAlwaysLogOnError(t *testing.T) {
 	for _, test := range []struct {
 		Name             string
@@ -270,11 +560,11 @@ func TestController_SubmitVote(t *testing.T) {
 			assert.Equal(test.ExpectRes, status)
 			if test.ExpectErr {
 				assert.Error(err)
+				assert.Equal(0, countChannel(c.graphChanges))
 			} else {
 				assert.NoError(err)
+				assert.Equal(1, countChannel(c.graphChanges))
 			}
-			// XXX(skep): should we re-run layout on votes? no..
-			// assert.Equal(1, countChannel(c.graphChanges))
 		})
 	}
 }
@@ -347,6 +637,250 @@ func TestController_DeleteEdge(t *testing.T) {
 	}
 }
 
+func TestController_CreateTag(t *testing.T) {
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.CreateEntityResult
+		ExpectErr        bool
+	}{
+		{
+			Name: "user authenticated, tag created",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().CreateTag(ctx, user444, &model.Text{Translations: []*model.Translation{
+					{Language: "en", Content: "Mathematics"},
+				}}).Return("7", nil)
+			},
+			ExpectRes: &model.CreateEntityResult{ID: "7"},
+		},
+		{
+			Name: "user not authenticated, no tag created",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectRes: AuthNeededForGraphDataChangeResult,
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			res, err := c.CreateTag(ctx, model.Text{Translations: []*model.Translation{
+				{Language: "en", Content: "Mathematics"},
+			}})
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, res)
+			if !test.ExpectErr {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+}
+
+func TestController_AddTagToNode(t *testing.T) {
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.Status
+		ExpectErr        bool
+	}{
+		{
+			Name: "user authenticated, tag added",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().AddNodeTag(ctx, user444, "123", "7").Return(nil)
+			},
+		},
+		{
+			Name: "db error",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().AddNodeTag(ctx, user444, "123", "7").Return(errors.New("no such tag"))
+			},
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			status, err := c.AddTagToNode(ctx, "123", "7")
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, status)
+			if !test.ExpectErr {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+}
+
+func TestController_RemoveTagFromNode(t *testing.T) {
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.Status
+		ExpectErr        bool
+	}{
+		{
+			Name: "user authenticated, tag removed",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().RemoveNodeTag(ctx, user444, "123", "7").Return(nil)
+			},
+		},
+		{
+			Name: "user not authenticated, no tag removed",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectRes: AuthNeededForGraphDataChangeStatus,
+			ExpectErr: true,
+		},
+		{
+			Name: "db error",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().RemoveNodeTag(ctx, user444, "123", "7").Return(errors.New("node 123 does not have tag 7"))
+			},
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			status, err := c.RemoveTagFromNode(ctx, "123", "7")
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, status)
+			if !test.ExpectErr {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+}
+
+func TestController_CreateResource(t *testing.T) {
+	input := model.ResourceInput{URL: "https://example.com", Title: "Example", Kind: model.ResourceKindArticle, Language: "en"}
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectRes        *model.CreateEntityResult
+		ExpectErr        bool
+	}{
+		{
+			Name: "user authenticated, resource created",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+				mock.EXPECT().CreateResource(ctx, user444, "123", input).Return("9", nil)
+			},
+			ExpectRes: &model.CreateEntityResult{ID: "9"},
+		},
+		{
+			Name: "user not authenticated, no resource created",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectRes: AuthNeededForGraphDataChangeResult,
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			res, err := c.CreateResource(ctx, "123", input)
+			assert := assert.New(t)
+			assert.Equal(test.ExpectRes, res)
+			if !test.ExpectErr {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+}
+
+func TestController_SubmitResourceVote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := db.NewMockDB(ctrl)
+	ctx := context.Background()
+	mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+	mock.EXPECT().AddResourceVote(ctx, user444, "9", 4.0).Return(nil)
+	c := NewController(mock, nil)
+	status, err := c.SubmitResourceVote(ctx, "9", 4.0)
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestController_Search(t *testing.T) {
+	de, five, tooMany := "de", 5, 1000
+	for _, test := range []struct {
+		Name        string
+		Language    *string
+		Limit       *int
+		ExpLanguage string
+		ExpLimit    int
+	}{
+		{Name: "defaults", ExpLanguage: "en", ExpLimit: SearchDefaultLimit},
+		{Name: "explicit language & limit", Language: &de, Limit: &five, ExpLanguage: "de", ExpLimit: 5},
+		{Name: "limit is capped", Limit: &tooMany, ExpLanguage: "en", ExpLimit: SearchMaxLimit},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := db.NewMockDB(ctrl)
+			ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+			expected := []*model.SearchResult{{Node: &model.Node{ID: "1"}, Language: test.ExpLanguage}}
+			mock.EXPECT().Search(ctx, "algebra", test.ExpLanguage, test.ExpLimit, []string{"7"}).Return(expected, nil)
+			c := NewController(mock, nil)
+			results, err := c.Search(ctx, "algebra", test.Language, test.Limit, []string{"7"})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, results)
+		})
+	}
+}
+
+func TestController_SimilarNodes(t *testing.T) {
+	de := "de"
+	for _, test := range []struct {
+		Name        string
+		Language    *string
+		ExpLanguage string
+	}{
+		{Name: "language from request", ExpLanguage: "en"},
+		{Name: "explicit language", Language: &de, ExpLanguage: "de"},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := db.NewMockDB(ctrl)
+			ctx := middleware.TestingCtxNewWithLanguage(context.Background(), "en")
+			expected := []*model.SimilarNode{{Node: &model.Node{ID: "1"}, Similarity: 0.9}}
+			mock.EXPECT().SimilarNodes(ctx, &model.Text{Translations: []*model.Translation{
+				{Language: test.ExpLanguage, Content: "algebra"},
+			}}, SimilarNodesMinSimilarity, SimilarNodesLimit).Return(expected, nil)
+			c := NewController(mock, nil)
+			similar, err := c.SimilarNodes(ctx, "algebra", test.Language)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, similar)
+		})
+	}
+}
+
 func TestController_NodeEdits(t *testing.T) {
 	for _, test := range []struct {
 		Name             string
@@ -430,6 +964,8 @@ func TestController_Graph(t *testing.T) {
 		ExpectGraph      *model.Graph
 		ExpectRes        *model.Status
 		ExpectErr        bool
+		EdgeTypes        []model.EdgeType
+		Tags             []string
 	}{
 		{
 			Name:        "assume added positions",
@@ -447,6 +983,49 @@ func TestController_Graph(t *testing.T) {
 				)
 			},
 		},
+		{
+			Name:      "filter by edge type",
+			EdgeTypes: []model.EdgeType{model.EdgeTypePrerequisite},
+			ExpectGraph: &model.Graph{
+				Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
+				Edges: []*model.Edge{{ID: "3", From: "1", To: "2", Type: model.EdgeTypePrerequisite}},
+			},
+			MockExpectations: func(ctx context.Context, mockDB db.MockDB, mockLayouter MockLayouter) {
+				mockDB.EXPECT().Graph(ctx).Return(&model.Graph{
+					Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
+					Edges: []*model.Edge{
+						{ID: "3", From: "1", To: "2", Type: model.EdgeTypePrerequisite},
+						{ID: "4", From: "2", To: "1", Type: model.EdgeTypeRelatedTo},
+					},
+				}, nil)
+				mockLayouter.EXPECT().GetNodePositions(ctx, gomock.Any())
+			},
+		},
+		{
+			Name: "filter by tag",
+			Tags: []string{"7"},
+			ExpectGraph: &model.Graph{
+				Nodes: []*model.Node{
+					{ID: "1", Tags: []*model.Tag{{ID: "7", Name: "Math"}}},
+					{ID: "2", Tags: []*model.Tag{{ID: "8", Name: "Physics"}, {ID: "7", Name: "Math"}}},
+				},
+				Edges: []*model.Edge{{ID: "4", From: "1", To: "2"}},
+			},
+			MockExpectations: func(ctx context.Context, mockDB db.MockDB, mockLayouter MockLayouter) {
+				mockDB.EXPECT().Graph(ctx).Return(&model.Graph{
+					Nodes: []*model.Node{
+						{ID: "1", Tags: []*model.Tag{{ID: "7", Name: "Math"}}},
+						{ID: "2", Tags: []*model.Tag{{ID: "8", Name: "Physics"}, {ID: "7", Name: "Math"}}},
+						{ID: "3", Tags: []*model.Tag{{ID: "8", Name: "Physics"}}},
+					},
+					Edges: []*model.Edge{
+						{ID: "4", From: "1", To: "2"},
+						{ID: "5", From: "2", To: "3"},
+					},
+				}, nil)
+				mockLayouter.EXPECT().GetNodePositions(ctx, gomock.Any())
+			},
+		},
 	} {
 		t.Run(test.Name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
@@ -455,7 +1034,7 @@ func TestController_Graph(t *testing.T) {
 			ctx := context.Background()
 			test.MockExpectations(ctx, *db, *l)
 			c := NewController(db, l)
-			graph, err := c.Graph(ctx)
+			graph, err := c.Graph(ctx, test.EdgeTypes, test.Tags)
 			assert := assert.New(t)
 			if test.ExpectErr {
 				assert.Error(err)
@@ -478,18 +1057,18 @@ func TestController_periodicGraphEmbeddingComputation(t *testing.T) {
 		{
 			Name: "should run layout on startup",
 			MockExpectations: func(ctx context.Context, mockDB db.MockDB, mockLayouter MockLayouter) {
-				mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{}, {}}}, nil)
-				mockLayouter.EXPECT().Reload(gomock.Any(), &model.Graph{Nodes: []*model.Node{{}, {}}}).Return(layout.Stats{Iterations: 5})
+				mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}, nil)
+				mockLayouter.EXPECT().Reload(gomock.Any(), &model.Graph{Nodes: []*model.Node{{ID: "1", Cluster: intPtr(0)}, {ID: "2", Cluster: intPtr(1)}}}).Return(layout.Stats{Iterations: 5})
 			},
 		},
 		{
 			Name: "should run layout on trigger call",
 			MockExpectations: func(ctx context.Context, mockDB db.MockDB, mockLayouter MockLayouter) {
-				mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{}, {}}}, nil)
-				mockLayouter.EXPECT().Reload(gomock.Any(), &model.Graph{Nodes: []*model.Node{{}, {}}}).Return(layout.Stats{Iterations: 5})
+				mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}, nil)
+				mockLayouter.EXPECT().Reload(gomock.Any(), &model.Graph{Nodes: []*model.Node{{ID: "1", Cluster: intPtr(0)}, {ID: "2", Cluster: intPtr(1)}}}).Return(layout.Stats{Iterations: 5})
 				// 2nd call
-				mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{}, {}}}, nil)
-				mockLayouter.EXPECT().Reload(gomock.Any(), &model.Graph{Nodes: []*model.Node{{}, {}}}).Return(layout.Stats{Iterations: 5})
+				mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}, nil)
+				mockLayouter.EXPECT().Reload(gomock.Any(), &model.Graph{Nodes: []*model.Node{{ID: "1", Cluster: intPtr(0)}, {ID: "2", Cluster: intPtr(1)}}}).Return(layout.Stats{Iterations: 5})
 			},
 			Setup: func(trigger chan time.Time) {
 				trigger <- time.UnixMilli(7)
@@ -514,6 +1093,110 @@ func TestController_periodicGraphEmbeddingComputation(t *testing.T) {
 	}
 }
 
+func TestController_LayoutStats(t *testing.T) {
+	admin := db.User{Document: db.Document{Key: "1"}, Roles: []db.RoleType{db.RoleAdmin}}
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		Stats            *layout.Stats
+		ExpectRes        *model.LayoutStats
+		ExpectErr        error
+	}{
+		{
+			Name: "admin, no layout yet",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+			},
+		},
+		{
+			Name: "admin, stats of last layout",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+			},
+			Stats: &layout.Stats{
+				Iterations: 5, TotalTime: 2 * time.Second, Converged: true, Energy: 0.5,
+				AverageDisplacement: 1, MaxDisplacement: 2,
+				Quality: layout.Quality{EdgeLengthStress: 0.25, EdgeCrossings: 3},
+			},
+			ExpectRes: &model.LayoutStats{
+				Iterations: 5, TotalTimeMilliseconds: 2000, Converged: true, Energy: 0.5,
+				AverageDisplacement: 1, MaxDisplacement: 2, EdgeLengthStress: 0.25, EdgeCrossings: 3,
+			},
+		},
+		{
+			Name: "user is not an admin",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+			},
+			Stats:     &layout.Stats{Iterations: 5},
+			ExpectErr: AdminNeededErr,
+		},
+		{
+			Name: "user not authenticated",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectErr: AdminNeededErr,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			if test.Stats != nil {
+				c.setLayoutStats(*test.Stats)
+			}
+			stats, err := c.LayoutStats(ctx)
+			assert := assert.New(t)
+			assert.Equal(test.ExpectErr, err)
+			if test.ExpectRes == nil {
+				assert.Nil(stats)
+				return
+			}
+			if assert.NotNil(stats) {
+				assert.WithinDuration(time.Now(), stats.FinishedAt, time.Minute)
+				stats.FinishedAt = time.Time{}
+				assert.Equal(test.ExpectRes, stats)
+			}
+		})
+	}
+}
+
+func TestController_periodicGraphEmbeddingComputation_recordsStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDB(ctrl)
+	l := NewMockLayouter(ctrl)
+	mockDB.EXPECT().Graph(gomock.Any()).Return(&model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}, nil)
+	l.EXPECT().Reload(gomock.Any(), gomock.Any()).Return(layout.Stats{Iterations: 7, TimedOut: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // stop after the initial layout
+	c := NewController(mockDB, l)
+	c.periodicGraphEmbeddingComputation(ctx, make(chan time.Time), time.Second)
+	assert := assert.New(t)
+	if assert.NotNil(c.layoutStats) {
+		assert.Equal(7, c.layoutStats.Iterations)
+		assert.True(c.layoutStats.TimedOut)
+	}
+}
+
+func edgeTypePtr(t model.EdgeType) *model.EdgeType {
+	return &t
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
 func countChannel(ch <-chan time.Time) int {
 	i := 0
 	for {
@@ -539,3 +1222,131 @@ func TestController_graphChanged(t *testing.T) {
 	c.graphChanged()
 	assert.Equal(t, 1, countChannel(c.graphChanges))
 }
+
+func TestController_PinNode(t *testing.T) {
+	admin := db.User{Document: db.Document{Key: "1"}, Roles: []db.RoleType{db.RoleAdmin}}
+	z := 3.0
+	for _, test := range []struct {
+		Name             string
+		Position         model.VectorInput
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectErr        bool
+		ExpectChange     bool
+	}{
+		{
+			Name:     "admin, 2D position",
+			Position: model.VectorInput{X: -500, Y: 300},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+				mock.EXPECT().PinNode(gomock.Any(), admin, "4", model.Vector{X: -500, Y: 300}).Return(nil)
+			},
+			ExpectChange: true,
+		},
+		{
+			Name:     "admin, 3D position",
+			Position: model.VectorInput{X: 1, Y: 2, Z: &z},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+				mock.EXPECT().PinNode(gomock.Any(), admin, "4", model.Vector{X: 1, Y: 2, Z: 3}).Return(nil)
+			},
+			ExpectChange: true,
+		},
+		{
+			Name:     "invalid position",
+			Position: model.VectorInput{X: math.NaN(), Y: 2},
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+			},
+			ExpectErr: true,
+		},
+		{
+			Name: "db error",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+				mock.EXPECT().PinNode(gomock.Any(), admin, "4", model.Vector{}).Return(errors.New("node not found"))
+			},
+			ExpectErr: true,
+		},
+		{
+			Name: "user is not an admin",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+			},
+			ExpectErr: true,
+		},
+		{
+			Name: "user not authenticated",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(false, nil, nil)
+			},
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			status, err := c.PinNode(ctx, "4", test.Position)
+			assert := assert.New(t)
+			assert.Nil(status)
+			assert.Equal(test.ExpectErr, err != nil, "error: %v", err)
+			if test.ExpectChange {
+				assert.Equal(1, countChannel(c.graphChanges))
+			} else {
+				assert.Equal(0, countChannel(c.graphChanges))
+			}
+		})
+	}
+}
+
+func TestController_UnpinNode(t *testing.T) {
+	admin := db.User{Document: db.Document{Key: "1"}, Roles: []db.RoleType{db.RoleAdmin}}
+	errNotPinned := errors.New("node '4' is not pinned")
+	for _, test := range []struct {
+		Name             string
+		MockExpectations func(context.Context, db.MockDB)
+		ExpectErr        error
+	}{
+		{
+			Name: "admin",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+				mock.EXPECT().UnpinNode(gomock.Any(), admin, "4").Return(nil)
+			},
+		},
+		{
+			Name: "node not pinned",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &admin, nil)
+				mock.EXPECT().UnpinNode(gomock.Any(), admin, "4").Return(errNotPinned)
+			},
+			ExpectErr: errNotPinned,
+		},
+		{
+			Name: "user is not an admin",
+			MockExpectations: func(ctx context.Context, mock db.MockDB) {
+				mock.EXPECT().IsUserAuthenticated(gomock.Any()).Return(true, &user444, nil)
+			},
+			ExpectErr: AdminNeededErr,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := db.NewMockDB(ctrl)
+			ctx := context.Background()
+			test.MockExpectations(ctx, *db)
+			c := NewController(db, nil)
+			status, err := c.UnpinNode(ctx, "4")
+			assert := assert.New(t)
+			assert.Nil(status)
+			assert.Equal(test.ExpectErr, err)
+			if test.ExpectErr != nil {
+				assert.Equal(0, countChannel(c.graphChanges), "nothing changed")
+			} else {
+				assert.Equal(1, countChannel(c.graphChanges))
+			}
+		})
+	}
+}
@@ -48,6 +48,20 @@ func (mr *MockLayouterMockRecorder) GetNodePositions(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodePositions", reflect.TypeOf((*MockLayouter)(nil).GetNodePositions), arg0, arg1)
 }
 
+// NodePositions mocks base method.
+func (m *MockLayouter) NodePositions(arg0 context.Context) map[string]model.Vector {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodePositions", arg0)
+	ret0, _ := ret[0].(map[string]model.Vector)
+	return ret0
+}
+
+// NodePositions indicates an expected call of NodePositions.
+func (mr *MockLayouterMockRecorder) NodePositions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodePositions", reflect.TypeOf((*MockLayouter)(nil).NodePositions), arg0)
+}
+
 // Reload mocks base method.
 func (m *MockLayouter) Reload(arg0 context.Context, arg1 *model.Graph) layout.Stats {
 	m.ctrl.T.Helper()
@@ -2,9 +2,15 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"runtime"
+	"sync"
+	"time"
 
+	"github.com/caarlos0/env/v6"
+	"github.com/quartercastle/vector"
 	"github.com/rs/zerolog/log"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
 	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
 	"github.com/suxatcode/learn-graph-poc-backend/layout"
 )
@@ -15,31 +21,266 @@ import (
 //go:generate mockgen -destination layout_mock.go -package controller . Layouter
 type Layouter interface {
 	// GetNodePositions assigns node possitions from a past graph embedding
-	// run. This is a quick call, that does no perform graph embedding.
+	// run and sets the layout status of the graph. This is a quick call, that
+	// does no perform graph embedding and never waits for one.
 	GetNodePositions(context.Context, *model.Graph)
+	// NodePositions returns node ID → position from a past graph embedding
+	// run, without reading the graph. Nodes added since are missing.
+	NodePositions(context.Context) map[string]model.Vector
 	// Reload re-runs graph embedding. This is a synchronous call and will
 	// take some time.
 	Reload(context.Context, *model.Graph) layout.Stats
 }
 
-// NewLayouter returns an implementation of the Layouter interface.
-func NewLayouter() Layouter {
-	return NewForceSimulationLayouter()
+const (
+	LayoutAlgorithmForce        = "force"
+	LayoutAlgorithmHierarchical = "hierarchical"
+	LayoutAlgorithmRadial       = "radial"
+	LayoutAlgorithmStress       = "stress"
+)
+
+type LayouterConfig struct {
+	// one of LayoutAlgorithm*
+	Algorithm string `env:"LAYOUT_ALGORITHM" envDefault:"force"`
+	// dimensions of the graph embedding, 2 or 3, only used by the force
+	// simulation
+	Dimensions int `env:"LAYOUT_DIMENSIONS" envDefault:"2"`
+	// ID of the center node of the radial layout, defaults to the node with
+	// the most edges
+	RadialRoot string `env:"LAYOUT_RADIAL_ROOT"`
+	// EdgeWeights maps edge weights to the force simulation
+	EdgeWeights EdgeWeightLayoutConfig
+	// Timeout of a single graph embedding computation
+	Timeout time.Duration `env:"LAYOUT_TIMEOUT" envDefault:"5m"`
+	// CompleteSimulation computes the layout after changes to the graph, its
+	// Dimensions and DirectionalStrength are set by the fields above
+	CompleteSimulation layout.ForceSimulationConfig `envPrefix:"LAYOUT_COMPLETE_"`
+	// QuickSimulation places new nodes until the next complete simulation
+	QuickSimulation layout.ForceSimulationConfig `envPrefix:"LAYOUT_QUICK_"`
+}
+
+// DefaultLayouterConfig returns the configuration used if no environment
+// variables are set.
+func DefaultLayouterConfig() LayouterConfig {
+	conf := LayouterConfig{
+		CompleteSimulation: DefaultCompleteSimulationConfig,
+		QuickSimulation:    DefaultQuickSimulationConfig,
+	}
+	env.Parse(&conf, env.Options{Environment: map[string]string{}})
+	return conf
+}
+
+// Validate reports configuration values, which would break the layout.
+func (conf LayouterConfig) Validate() error {
+	switch conf.Algorithm {
+	case "", LayoutAlgorithmForce, LayoutAlgorithmHierarchical, LayoutAlgorithmRadial, LayoutAlgorithmStress:
+	default:
+		return fmt.Errorf("unknown layout algorithm '%s'", conf.Algorithm)
+	}
+	if conf.Dimensions != 2 && conf.Dimensions != 3 {
+		return fmt.Errorf("layout dimensions must be 2 or 3, got %d", conf.Dimensions)
+	}
+	if conf.Timeout <= 0 {
+		return fmt.Errorf("layout timeout must be positive, got %v", conf.Timeout)
+	}
+	if err := conf.EdgeWeights.Validate(); err != nil {
+		return err
+	}
+	for _, simulation := range []struct {
+		name string
+		conf layout.ForceSimulationConfig
+	}{
+		{"complete simulation", conf.CompleteSimulation},
+		{"quick simulation", conf.QuickSimulation},
+	} {
+		if err := simulation.conf.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", simulation.name, err)
+		}
+		if simulation.conf.Dimensions != 0 && simulation.conf.Dimensions != conf.Dimensions {
+			return fmt.Errorf("%s: dimensions are set by the layout dimensions, got %d", simulation.name, simulation.conf.Dimensions)
+		}
+		if simulation.conf.DirectionalStrength != 0 && simulation.conf.DirectionalStrength != conf.EdgeWeights.DirectionalStrength {
+			return fmt.Errorf("%s: directional strength is set by the edge weights, got %v", simulation.name, simulation.conf.DirectionalStrength)
+		}
+	}
+	return nil
+}
+
+// EdgeWeightLayoutConfig maps the voted weight of an edge to the force model:
+// weights are normalized to [0,1] by MinWeight and MaxWeight and interpolate
+// the spring strength and ideal length of the edge linearly.
+type EdgeWeightLayoutConfig struct {
+	// range of the voted edge weights
+	MinWeight float64 `env:"LAYOUT_EDGE_MIN_WEIGHT" envDefault:"1"`
+	MaxWeight float64 `env:"LAYOUT_EDGE_MAX_WEIGHT" envDefault:"10"`
+	// spring strength of the lowest and highest weight, scaled by the weight
+	// of the edge type
+	MinStrength float64 `env:"LAYOUT_EDGE_MIN_STRENGTH" envDefault:"0.5"`
+	MaxStrength float64 `env:"LAYOUT_EDGE_MAX_STRENGTH" envDefault:"2"`
+	// ideal length of the lowest and highest weight, zero means nodes are
+	// pulled together until they touch
+	LengthAtMinWeight float64 `env:"LAYOUT_EDGE_LENGTH_AT_MIN_WEIGHT" envDefault:"0"`
+	LengthAtMaxWeight float64 `env:"LAYOUT_EDGE_LENGTH_AT_MAX_WEIGHT" envDefault:"0"`
+	// DirectionalStrength pushes prerequisites above the topics they unlock,
+	// zero disables it
+	DirectionalStrength float64 `env:"LAYOUT_DIRECTIONAL_STRENGTH" envDefault:"0"`
+}
+
+var DefaultEdgeWeightLayoutConfig = EdgeWeightLayoutConfig{
+	MinWeight:   1.0,
+	MaxWeight:   10.0,
+	MinStrength: 0.5,
+	MaxStrength: 2.0,
+}
+
+func (conf EdgeWeightLayoutConfig) Validate() error {
+	if conf.MaxWeight < conf.MinWeight {
+		return fmt.Errorf("edge weight range [%v,%v] is empty", conf.MinWeight, conf.MaxWeight)
+	}
+	if conf.MinStrength <= 0 || conf.MaxStrength <= 0 {
+		// a strength of zero means unset to layout.NewGraph
+		return fmt.Errorf("edge strengths must be positive, got %v and %v", conf.MinStrength, conf.MaxStrength)
+	}
+	if conf.LengthAtMinWeight < 0 || conf.LengthAtMaxWeight < 0 {
+		return fmt.Errorf("edge lengths must not be negative, got %v and %v", conf.LengthAtMinWeight, conf.LengthAtMaxWeight)
+	}
+	if conf.DirectionalStrength < 0 {
+		return fmt.Errorf("directional strength must not be negative, got %v", conf.DirectionalStrength)
+	}
+	return nil
+}
+
+// normalize maps weight to [0,1]
+func (conf EdgeWeightLayoutConfig) normalize(weight float64) float64 {
+	if conf.MaxWeight <= conf.MinWeight {
+		return 1.0
+	}
+	return clamp((weight-conf.MinWeight)/(conf.MaxWeight-conf.MinWeight), 0, 1)
+}
+
+func (conf EdgeWeightLayoutConfig) strength(weight float64) float64 {
+	return conf.MinStrength + (conf.MaxStrength-conf.MinStrength)*conf.normalize(weight)
+}
+
+func (conf EdgeWeightLayoutConfig) length(weight float64) float64 {
+	return conf.LengthAtMinWeight + (conf.LengthAtMaxWeight-conf.LengthAtMinWeight)*conf.normalize(weight)
+}
+
+func clamp(in, lo, hi float64) float64 {
+	if in < lo {
+		return lo
+	}
+	if in > hi {
+		return hi
+	}
+	return in
+}
+
+// GetLayouterEnvConfig returns DefaultLayouterConfig overwritten by the
+// environment variables set.
+func GetLayouterEnvConfig() (LayouterConfig, error) {
+	conf := DefaultLayouterConfig()
+	err := env.Parse(&conf)
+	return conf, err
+}
+
+// NewLayouter returns the implementation of the Layouter interface selected
+// by conf.Algorithm. The force simulation persists its positions to store.
+func NewLayouter(store db.LayoutDB, conf LayouterConfig) (Layouter, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	switch conf.Algorithm {
+	case LayoutAlgorithmForce, "":
+		return NewForceSimulationLayouter().
+			WithSimulationConfig(conf.CompleteSimulation, conf.QuickSimulation).
+			WithDimensions(conf.Dimensions).
+			WithEdgeWeights(conf.EdgeWeights).
+			WithStore(store), nil
+	case LayoutAlgorithmHierarchical:
+		return NewHierarchicalLayouter(layout.DefaultLayeredConfig), nil
+	case LayoutAlgorithmRadial:
+		return NewRadialLayouter(conf.RadialRoot, layout.DefaultRadialConfig), nil
+	case LayoutAlgorithmStress:
+		return NewStressLayouter(layout.DefaultStressConfig), nil
+	}
+	return nil, fmt.Errorf("unknown layout algorithm '%s'", conf.Algorithm)
 }
 
 // implements Layouter
 // Idea:
 //   - run a completeSimulation when layout changes to the graph happen, and
-//   - run a quickSimulation on every request IFF the current layout is missing
-//     some node/edge.
+//   - run a quickSimulation in the background IFF a request finds the current
+//     layout missing some node/edge, until then new nodes are placed next to
+//     their neighbors.
+//   - persist positions after each completeSimulation and restore them on the
+//     first Reload, so that requests are answered without waiting for it.
 type ForceSimulationLayouter struct {
 	completeSimulation *layout.ForceSimulation
 	simulationState    *simulationState
 	quickSimulation    *layout.ForceSimulation
-	// GetNodePositions will always wait for this channel and is closed after
-	// the first Reload()
-	waitForInitialLayout chan bool
-	initialLayoutDone    bool
+	// quickState is the result of the last quickSimulation, it is only valid
+	// as long as quickBase is the current simulationState
+	quickState, quickBase *simulationState
+	// lock guards simulationState, quickState, quickBase, computing and placing
+	lock sync.RWMutex
+	// computing is set while Reload runs a completeSimulation
+	computing bool
+	// placing is set while a quickSimulation runs in the background
+	placing bool
+	// placements tracks the background quickSimulations
+	placements        sync.WaitGroup
+	initialLayoutDone bool
+	// edgeTypeWeights scales the attraction of an edge by its type
+	edgeTypeWeights map[model.EdgeType]float64
+	// edgeWeights maps the voted weight of an edge to the force model
+	edgeWeights EdgeWeightLayoutConfig
+	// incremental controls recomputations of an existing layout
+	incremental IncrementalLayoutConfig
+	// store persists the layout, may be nil
+	store db.LayoutDB
+	// version of the current layout, incremented by every completeSimulation
+	version int
+}
+
+// SaveLayoutTimeout limits persisting positions after a completeSimulation
+var SaveLayoutTimeout = time.Second * 30
+
+// QuickSimulationTimeout limits placing new nodes in the background
+var QuickSimulationTimeout = time.Minute
+
+// DefaultEdgeTypeWeights are the weights used for the attraction of the
+// different edge types in the graph embedding. Prerequisites and sub-topics
+// pull harder than loosely related topics.
+var DefaultEdgeTypeWeights = map[model.EdgeType]float64{
+	model.EdgeTypePrerequisite: 1.0,
+	model.EdgeTypePartOf:       1.0,
+	model.EdgeTypeRelatedTo:    0.5,
+}
+
+// IncrementalLayoutConfig keeps recomputations close to the previous layout,
+// so that users keep their orientation on the map.
+type IncrementalLayoutConfig struct {
+	// nodes within ReheatHops edges of a changed node move freely
+	ReheatHops int
+	// mobility of all other nodes, see layout.Node.Mobility
+	Mobility float64
+	// MaxDisplacement limits the movement of each previously positioned node
+	// per recomputation
+	MaxDisplacement float64
+	// new nodes are placed within this radius around their positioned
+	// neighbors
+	NewNodeRadius float64
+	// if more than this fraction of nodes changed, all nodes move freely
+	FullRelayoutRatio float64
+}
+
+var DefaultIncrementalLayoutConfig = IncrementalLayoutConfig{
+	ReheatHops:        1,
+	Mobility:          0.1,
+	MaxDisplacement:   200.0,
+	NewNodeRadius:     100.0,
+	FullRelayoutRatio: 0.5,
 }
 
 type simulationState struct {
@@ -47,36 +288,91 @@ type simulationState struct {
 	ledges                  []*layout.Edge
 	modelToLayoutNodeLookup map[string]int
 	modelToLayoutEdgeLookup map[string]int
+	// edgeWeights holds the weight of each model edge used for the layout
+	edgeWeights map[string]float64
+	// pins holds the positions of pinned nodes used for the layout
+	pins map[string]model.Vector
+}
+
+// DefaultCompleteSimulationConfig configures the simulation computing the
+// layout after changes to the graph.
+var DefaultCompleteSimulationConfig = layout.ForceSimulationConfig{
+	InitialLayout:                   layout.InitialLayoutCircle,
+	Rect:                            layout.Rect{X: -1000.0, Y: -500.0, Width: 2000.0, Height: 1000.0},
+	ScreenMultiplierToClampPosition: 100,
+	FrameTime:                       1.0,
+	MinDistanceBeweenNodes:          100.0,
+	AlphaInit:                       1.0,
+	AlphaDecay:                      0.005,
+	AlphaTarget:                     0.10,
+	RepulsionMultiplier:             10.0,                 // default: 10.0
+	Parallelization:                 runtime.NumCPU() * 2, // x2, since distribution of nodes is not balanced
+	Gravity:                         true,
+	GravityStrength:                 0.1,
 }
 
+// DefaultQuickSimulationConfig configures the simulation placing new nodes
+// until the next complete simulation: it cools down much faster.
+var DefaultQuickSimulationConfig = func() layout.ForceSimulationConfig {
+	conf := DefaultCompleteSimulationConfig
+	conf.AlphaInit = 10.0
+	conf.AlphaDecay = 0.5
+	conf.AlphaTarget = 1
+	conf.InitialLayout = layout.InitialLayoutRandom // TODO(skep): create InitialLayoutCloseToFirstEdgeFound
+	return conf
+}()
+
 func NewForceSimulationLayouter() *ForceSimulationLayouter {
-	max_y := 1000.0
-	rect := layout.Rect{X: -max_y, Y: -max_y / 2, Width: max_y * 2, Height: max_y}
-	config := layout.ForceSimulationConfig{
-		InitialLayout:                   layout.InitialLayoutCircle,
-		Rect:                            rect,
-		ScreenMultiplierToClampPosition: 100,
-		FrameTime:                       1.0,
-		MinDistanceBeweenNodes:          100.0,
-		AlphaInit:                       1.0,
-		AlphaDecay:                      0.005,
-		AlphaTarget:                     0.10,
-		RepulsionMultiplier:             10.0,                 // default: 10.0
-		Parallelization:                 runtime.NumCPU() * 2, // x2, since distribution of nodes is not balanced
-		Gravity:                         true,
-		GravityStrength:                 0.1,
-	}
-	configQuickSim := config
-	configQuickSim.AlphaInit = 10.0
-	configQuickSim.AlphaDecay = 0.5
-	configQuickSim.AlphaTarget = 1
-	configQuickSim.InitialLayout = layout.InitialLayoutRandom // TODO(skep): create InitialLayoutCloseToFirstEdgeFound
 	return &ForceSimulationLayouter{
-		completeSimulation:   layout.NewForceSimulation(config),
-		simulationState:      &simulationState{},
-		quickSimulation:      layout.NewForceSimulation(configQuickSim),
-		waitForInitialLayout: make(chan bool, 1),
+		completeSimulation: layout.NewForceSimulation(DefaultCompleteSimulationConfig),
+		simulationState:    &simulationState{},
+		quickSimulation:    layout.NewForceSimulation(DefaultQuickSimulationConfig),
+		edgeTypeWeights:    DefaultEdgeTypeWeights,
+		edgeWeights:        DefaultEdgeWeightLayoutConfig,
+		incremental:        DefaultIncrementalLayoutConfig,
+	}
+}
+
+// WithSimulationConfig replaces the configuration of the complete and the
+// quick simulation
+func (l *ForceSimulationLayouter) WithSimulationConfig(complete, quick layout.ForceSimulationConfig) *ForceSimulationLayouter {
+	l.completeSimulation.ApplyConfig(complete)
+	l.quickSimulation.ApplyConfig(quick)
+	return l
+}
+
+// WithDimensions switches the simulations to a 2D or 3D embedding
+func (l *ForceSimulationLayouter) WithDimensions(dimensions int) *ForceSimulationLayouter {
+	for _, simulation := range []*layout.ForceSimulation{l.completeSimulation, l.quickSimulation} {
+		conf := simulation.Config()
+		conf.Dimensions = dimensions
+		if dimensions == 3 && conf.InitialLayout == layout.InitialLayoutCircle {
+			// nodes initialized in a plane would stay in that plane
+			conf.InitialLayout = layout.InitialLayoutSphere
+		}
+		simulation.ApplyConfig(conf)
+	}
+	return l
+}
+
+// WithEdgeWeights sets the mapping of edge weights to the force model, an
+// empty config keeps the default.
+func (l *ForceSimulationLayouter) WithEdgeWeights(conf EdgeWeightLayoutConfig) *ForceSimulationLayouter {
+	if conf == (EdgeWeightLayoutConfig{}) {
+		return l
+	}
+	l.edgeWeights = conf
+	for _, simulation := range []*layout.ForceSimulation{l.completeSimulation, l.quickSimulation} {
+		simConf := simulation.Config()
+		simConf.DirectionalStrength = conf.DirectionalStrength
+		simulation.ApplyConfig(simConf)
 	}
+	return l
+}
+
+func (l *ForceSimulationLayouter) WithStore(store db.LayoutDB) *ForceSimulationLayouter {
+	l.store = store
+	return l
 }
 
 func getMissingNodesAndEdges(s *simulationState, g *model.Graph) ([]*model.Node, []*model.Edge) {
@@ -119,75 +415,448 @@ func copyState(s *simulationState) *simulationState {
 	for k, v := range s.modelToLayoutEdgeLookup {
 		p.modelToLayoutEdgeLookup[k] = v
 	}
+	if s.edgeWeights != nil {
+		p.edgeWeights = make(map[string]float64, len(s.edgeWeights))
+		for k, v := range s.edgeWeights {
+			p.edgeWeights[k] = v
+		}
+	}
+	if s.pins != nil {
+		p.pins = make(map[string]model.Vector, len(s.pins))
+		for k, v := range s.pins {
+			p.pins[k] = v
+		}
+	}
 	return &p
 }
 
 func (l *ForceSimulationLayouter) GetNodePositions(ctx context.Context, g *model.Graph) {
-	select {
-	case <-l.waitForInitialLayout:
+	base, s, computing := l.currentState()
+	missing := []*model.Node{}
+	for _, node := range g.Nodes {
+		idx, exists := s.modelToLayoutNodeLookup[node.ID]
+		if !exists {
+			missing = append(missing, node)
+			continue
+		}
+		pos := s.lnodes[idx].Pos
+		node.Position = &model.Vector{X: pos.X(), Y: pos.Y(), Z: pos.Z()}
 	}
-	s := l.simulationState
+	placeNearNeighbors(g, missing)
 	missingNodes, missingEdges := getMissingNodesAndEdges(s, g)
 	if len(missingNodes) > 0 || len(missingEdges) > 0 {
-		// use a copy of the state for the quick simulation
-		s = copyState(l.simulationState)
-		for _, node := range s.lnodes {
-			node.IsPinned = true
-		}
-		newNodes, _ := appendNodesAndEdges(s, missingNodes, missingEdges)
+		l.placeInBackground(base, s, missingNodes, missingEdges)
+	}
+	missingNodes, missingEdges = getMissingNodesAndEdges(base, g)
+	g.LayoutStatus = layoutStatus(computing, base.modelToLayoutNodeLookup != nil && len(missingNodes) == 0 && len(missingEdges) == 0)
+}
+
+func (l *ForceSimulationLayouter) NodePositions(ctx context.Context) map[string]model.Vector {
+	_, s, _ := l.currentState()
+	positions := make(map[string]model.Vector, len(s.modelToLayoutNodeLookup))
+	for id, idx := range s.modelToLayoutNodeLookup {
+		pos := s.lnodes[idx].Pos
+		positions[id] = model.Vector{X: pos.X(), Y: pos.Y(), Z: pos.Z()}
+	}
+	return positions
+}
+
+// currentState returns the layout of the last completeSimulation, the layout
+// to answer requests with, which includes the result of a quickSimulation
+// based on it, and whether a completeSimulation is running
+func (l *ForceSimulationLayouter) currentState() (base, s *simulationState, computing bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	base, computing = l.simulationState, l.computing
+	s = base
+	if l.quickState != nil && l.quickBase == base {
+		s = l.quickState
+	}
+	return base, s, computing
+}
+
+// placeInBackground runs the quickSimulation for nodes and edges missing in
+// the layout s, unless one is running already. Its result is used by
+// GetNodePositions until the completeSimulation replaces base.
+func (l *ForceSimulationLayouter) placeInBackground(base, s *simulationState, nodes []*model.Node, edges []*model.Edge) {
+	l.lock.Lock()
+	if l.placing {
+		l.lock.Unlock()
+		return
+	}
+	l.placing = true
+	l.lock.Unlock()
+	// use a copy of the state for the quick simulation
+	s = copyState(s)
+	for _, node := range s.lnodes {
+		node.IsPinned = true
+	}
+	newNodes, _, err := appendNodesAndEdges(s, nodes, edges, l.edgeTypeWeights, l.edgeWeights)
+	if err != nil {
+		log.Error().Msgf("failed to place new nodes: %v", err)
+		l.lock.Lock()
+		l.placing = false
+		l.lock.Unlock()
+		return
+	}
+	l.placements.Add(1)
+	go func() {
+		defer l.placements.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), QuickSimulationTimeout)
+		defer cancel()
 		l.quickSimulation.InitializeNodes(ctx, newNodes)                     // initialize only new nodes
 		_, stats := l.quickSimulation.ComputeLayout(ctx, s.lnodes, s.ledges) // run quickSimulation with all nodes & edges
-		l.updateGraphWithPositions(s, g)
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		l.placing = false
+		if l.simulationState != base {
+			return // outdated by a completeSimulation
+		}
+		l.quickState, l.quickBase = s, base
 		log.Info().Msgf(
 			"*quick* graph layout computaton finished: stats{iterations: %d, time: %d ms}",
 			stats.Iterations,
 			stats.TotalTime.Milliseconds(),
 		)
+	}()
+}
+
+// layoutStatus tells clients whether positions are final: fresh if the
+// layout contains all nodes and edges of the graph
+func layoutStatus(computing, complete bool) *model.LayoutStatus {
+	status := model.LayoutStatusStale
+	if computing {
+		status = model.LayoutStatusComputing
+	} else if complete {
+		status = model.LayoutStatusFresh
 	}
-	for i, node := range g.Nodes {
-		idx := s.modelToLayoutNodeLookup[node.ID]
-		node := s.lnodes[idx]
-		g.Nodes[i].Position = &model.Vector{
-			X: node.Pos.X(),
-			Y: node.Pos.Y(),
-			Z: node.Pos.Z(),
+	return &status
+}
+
+// placeNearNeighbors places nodes without position at the centroid of their
+// positioned neighbors in g, nodes without any keep a null position
+func placeNearNeighbors(g *model.Graph, nodes []*model.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	unpositioned := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		unpositioned[node.ID] = true
+		node.Position = nil
+	}
+	positions := make(map[string]*model.Vector, len(g.Nodes))
+	for _, node := range g.Nodes {
+		if !unpositioned[node.ID] && node.Position != nil {
+			positions[node.ID] = node.Position
+		}
+	}
+	neighbors := adjacency(g)
+	for _, node := range nodes {
+		centroid, count := model.Vector{}, 0.0
+		for _, id := range neighbors[node.ID] {
+			if pos, ok := positions[id]; ok {
+				centroid.X, centroid.Y, centroid.Z = centroid.X+pos.X, centroid.Y+pos.Y, centroid.Z+pos.Z
+				count++
+			}
+		}
+		if count > 0 {
+			node.Position = &model.Vector{X: centroid.X / count, Y: centroid.Y / count, Z: centroid.Z / count}
 		}
 	}
 }
 
 // TODO(skep): use a  onChange channel in the Controller -> Reload should always run the graph embedding if called!
-func (l *ForceSimulationLayouter) shouldRun(g *model.Graph) bool {
+func (l *ForceSimulationLayouter) shouldRun(g *model.Graph, pins map[string]model.Vector) bool {
 	s := l.simulationState
 	if s.modelToLayoutNodeLookup == nil || s.modelToLayoutEdgeLookup == nil {
 		return true // initial run
 	}
-	missingNodes, missingEdges := getMissingNodesAndEdges(s, g)
-	if len(missingNodes) == 0 && len(missingEdges) == 0 {
-		return false
+	return len(changedNodes(s, g)) > 0 || len(changedPins(s.pins, pins)) > 0
+}
+
+// changedPins returns the IDs of all nodes, which were pinned, moved or
+// unpinned
+func changedPins(prev, pins map[string]model.Vector) map[string]bool {
+	changed := map[string]bool{}
+	for id, pos := range pins {
+		if prevPos, exists := prev[id]; !exists || prevPos != pos {
+			changed[id] = true
+		}
 	}
-	return true
+	for id := range prev {
+		if _, exists := pins[id]; !exists {
+			changed[id] = true
+		}
+	}
+	return changed
+}
+
+// changedNodes returns the IDs of all nodes in g, which are new, or have a
+// new, removed or re-weighted edge or neighbor compared to the layout s
+func changedNodes(s *simulationState, g *model.Graph) map[string]bool {
+	changed := map[string]bool{}
+	inGraph := make(map[string]bool, len(g.Nodes))
+	for _, node := range g.Nodes {
+		inGraph[node.ID] = true
+		if _, exists := s.modelToLayoutNodeLookup[node.ID]; !exists {
+			changed[node.ID] = true
+		}
+	}
+	edgesInGraph := make(map[string]bool, len(g.Edges))
+	for _, edge := range g.Edges {
+		edgesInGraph[edge.ID] = true
+		if _, exists := s.modelToLayoutEdgeLookup[edge.ID]; !exists {
+			changed[edge.From] = true
+			changed[edge.To] = true
+		} else if weight, known := s.edgeWeights[edge.ID]; known && weight != edge.Weight {
+			// votes changed the weight
+			changed[edge.From] = true
+			changed[edge.To] = true
+		}
+	}
+	layoutToModelNode := make(map[int]string, len(s.modelToLayoutNodeLookup))
+	for id, idx := range s.modelToLayoutNodeLookup {
+		layoutToModelNode[idx] = id
+	}
+	for id, idx := range s.modelToLayoutEdgeLookup {
+		edge := s.ledges[idx]
+		from, to := layoutToModelNode[edge.Source], layoutToModelNode[edge.Target]
+		if edgesInGraph[id] && inGraph[from] && inGraph[to] {
+			continue
+		}
+		// edge or one of its nodes was removed
+		for _, node := range []string{from, to} {
+			if inGraph[node] {
+				changed[node] = true
+			}
+		}
+	}
+	return changed
+}
+
+// adjacency returns node ID → IDs of neighbor nodes, ignoring direction
+func adjacency(g *model.Graph) map[string][]string {
+	neighbors := map[string][]string{}
+	for _, edge := range g.Edges {
+		neighbors[edge.From] = append(neighbors[edge.From], edge.To)
+		neighbors[edge.To] = append(neighbors[edge.To], edge.From)
+	}
+	return neighbors
+}
+
+// neighborhood returns the nodes within hops edges of nodes
+func neighborhood(neighbors map[string][]string, nodes map[string]bool, hops int) map[string]bool {
+	res := make(map[string]bool, len(nodes))
+	frontier := []string{}
+	for id := range nodes {
+		res[id] = true
+		frontier = append(frontier, id)
+	}
+	for i := 0; i < hops; i++ {
+		next := []string{}
+		for _, id := range frontier {
+			for _, neighbor := range neighbors[id] {
+				if !res[neighbor] {
+					res[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+	return res
 }
 
 func (l *ForceSimulationLayouter) Reload(ctx context.Context, g *model.Graph) layout.Stats {
-	if !l.shouldRun(g) {
+	l.setComputing(true)
+	defer l.setComputing(false)
+	if !l.initialLayoutDone && l.restore(ctx, g) {
+		l.initialLayoutDone = true
+	}
+	pins := l.pins(ctx)
+	if !l.shouldRun(g, pins) {
 		return layout.Stats{}
 	}
-	s := simulationState{}
+	s := simulationState{pins: pins}
 	s.lnodes, s.ledges = []*layout.Node{}, []*layout.Edge{}
 	s.modelToLayoutNodeLookup = make(map[string]int, len(g.Nodes))
 	s.modelToLayoutEdgeLookup = make(map[string]int, len(g.Edges))
-	appendNodesAndEdges(&s, g.Nodes, g.Edges)
-	l.completeSimulation.InitializeNodes(ctx, s.lnodes)
+	if _, _, err := appendNodesAndEdges(&s, g.Nodes, g.Edges, l.edgeTypeWeights, l.edgeWeights); err != nil {
+		log.Ctx(ctx).Error().Msgf("force layout failed, keeping previous positions: %v", err)
+		return layout.Stats{}
+	}
+	l.warmStart(ctx, &s, g)
+	l.pinNodes(&s)
 	_, stats := l.completeSimulation.ComputeLayout(ctx, s.lnodes, s.ledges)
+	stats.Quality = l.completeSimulation.MeasureQuality(s.lnodes, s.ledges)
 	l.updateGraphWithPositions(&s, g)
-	l.simulationState = &s
-	if !l.initialLayoutDone {
-		l.initialLayoutDone = true
-		close(l.waitForInitialLayout)
-	}
+	l.setSimulationState(&s)
+	l.version++
+	l.initialLayoutDone = true
+	l.save(g)
 	return stats
 }
 
+func (l *ForceSimulationLayouter) setComputing(computing bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.computing = computing
+}
+
+// setSimulationState replaces the layout, only Reload writes it, hence it
+// may read it without locking
+func (l *ForceSimulationLayouter) setSimulationState(s *simulationState) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.simulationState = s
+}
+
+// warmStart initializes the nodes of s from the previous layout: previously
+// positioned nodes keep their position and only the neighborhood of changed
+// nodes moves freely, new nodes are placed next to their neighbors.
+func (l *ForceSimulationLayouter) warmStart(ctx context.Context, s *simulationState, g *model.Graph) {
+	prev := l.simulationState
+	conf := l.incremental
+	changed := changedNodes(prev, g)
+	for id := range changedPins(prev.pins, s.pins) {
+		if _, exists := s.modelToLayoutNodeLookup[id]; exists {
+			changed[id] = true
+		}
+	}
+	incremental := prev.modelToLayoutNodeLookup != nil && float64(len(changed)) <= conf.FullRelayoutRatio*float64(len(g.Nodes))
+	neighbors := adjacency(g)
+	hot := neighborhood(neighbors, changed, conf.ReheatHops)
+	newNodes, anchors := []*layout.Node{}, []vector.Vector{}
+	for _, node := range g.Nodes {
+		lnode := s.lnodes[s.modelToLayoutNodeLookup[node.ID]]
+		if idx, exists := prev.modelToLayoutNodeLookup[node.ID]; exists {
+			lnode.Pos = append(vector.Vector{}, prev.lnodes[idx].Pos...)
+			if incremental {
+				lnode.MaxDisplacement = conf.MaxDisplacement
+				if !hot[node.ID] {
+					lnode.Mobility = conf.Mobility
+				}
+			}
+			continue
+		}
+		var anchor vector.Vector
+		count := 0.0
+		for _, neighbor := range neighbors[node.ID] {
+			if idx, exists := prev.modelToLayoutNodeLookup[neighbor]; exists {
+				if anchor == nil {
+					anchor = make(vector.Vector, l.completeSimulation.Dimensions())
+				}
+				vector.In(anchor).Add(prev.lnodes[idx].Pos)
+				count++
+			}
+		}
+		if anchor != nil {
+			anchor = anchor.Scale(1 / count)
+		}
+		newNodes = append(newNodes, lnode)
+		anchors = append(anchors, anchor)
+	}
+	l.completeSimulation.InitializeNodesNear(ctx, newNodes, anchors, conf.NewNodeRadius)
+	if incremental {
+		log.Ctx(ctx).Info().Msgf("incremental graph layout: %d changed nodes, %d of %d nodes move freely", len(changed), len(hot), len(g.Nodes))
+	}
+}
+
+// pins loads the positions of pinned nodes, without a store or on errors the
+// previous pins are kept
+func (l *ForceSimulationLayouter) pins(ctx context.Context) map[string]model.Vector {
+	if l.store == nil {
+		return l.simulationState.pins
+	}
+	pins, err := l.store.NodePins(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("failed to load pinned nodes: %v", err)
+		return l.simulationState.pins
+	}
+	return pins
+}
+
+// pinNodes fixes the pinned nodes of s at their position, the complete
+// simulation does not move them
+func (l *ForceSimulationLayouter) pinNodes(s *simulationState) {
+	for id, pos := range s.pins {
+		idx, exists := s.modelToLayoutNodeLookup[id]
+		if !exists {
+			continue
+		}
+		s.lnodes[idx].Pos = vector.Vector{pos.X, pos.Y, pos.Z}[:l.completeSimulation.Dimensions()]
+		s.lnodes[idx].IsPinned = true
+	}
+}
+
+// restore loads the persisted positions of the nodes in g, returns true if
+// any were found
+func (l *ForceSimulationLayouter) restore(ctx context.Context, g *model.Graph) bool {
+	if l.store == nil {
+		return false
+	}
+	saved, err := l.store.NodePositions(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("failed to restore layout: %v", err)
+		return false
+	}
+	if saved == nil || len(saved.Positions) == 0 {
+		return false
+	}
+	nodes := []*model.Node{}
+	for _, node := range g.Nodes {
+		if _, exists := saved.Positions[node.ID]; exists {
+			nodes = append(nodes, node)
+		}
+	}
+	edges := []*model.Edge{}
+	for _, edge := range g.Edges {
+		_, fromExists := saved.Positions[edge.From]
+		_, toExists := saved.Positions[edge.To]
+		if fromExists && toExists {
+			edges = append(edges, edge)
+		}
+	}
+	if len(nodes) == 0 {
+		return false
+	}
+	s := simulationState{}
+	s.lnodes, s.ledges = []*layout.Node{}, []*layout.Edge{}
+	s.modelToLayoutNodeLookup = make(map[string]int, len(nodes))
+	s.modelToLayoutEdgeLookup = make(map[string]int, len(edges))
+	if _, _, err := appendNodesAndEdges(&s, nodes, edges, l.edgeTypeWeights, l.edgeWeights); err != nil {
+		log.Ctx(ctx).Error().Msgf("failed to restore layout version %d: %v", saved.Version, err)
+		return false
+	}
+	for _, node := range nodes {
+		pos := saved.Positions[node.ID]
+		s.lnodes[s.modelToLayoutNodeLookup[node.ID]].Pos = vector.Vector{pos.X, pos.Y, pos.Z}[:l.completeSimulation.Dimensions()]
+	}
+	l.setSimulationState(&s)
+	l.version = saved.Version
+	log.Ctx(ctx).Info().Msgf("restored layout version %d with %d of %d nodes", saved.Version, len(nodes), len(g.Nodes))
+	return true
+}
+
+// save persists the positions of the current layout, the simulation may have
+// used up the deadline of its context, hence a separate one is used
+func (l *ForceSimulationLayouter) save(g *model.Graph) {
+	if l.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), SaveLayoutTimeout)
+	defer cancel()
+	saved := &db.Layout{Version: l.version, Positions: make(map[string]model.Vector, len(g.Nodes))}
+	for _, node := range g.Nodes {
+		if node.Position != nil {
+			saved.Positions[node.ID] = *node.Position
+		}
+	}
+	if err := l.store.SaveNodePositions(ctx, saved); err != nil {
+		log.Error().Msgf("failed to save layout version %d: %v", l.version, err)
+	}
+}
+
 func (l *ForceSimulationLayouter) updateGraphWithPositions(s *simulationState, g *model.Graph) {
 	for i := range g.Nodes {
 		idx := s.modelToLayoutNodeLookup[g.Nodes[i].ID]
@@ -196,19 +865,50 @@ func (l *ForceSimulationLayouter) updateGraphWithPositions(s *simulationState, g
 	}
 }
 
-// returns newly added nodes and edges as layout.{Node/Edge} type
-func appendNodesAndEdges(s *simulationState, nodes []*model.Node, edges []*model.Edge) ([]*layout.Node, []*layout.Edge) {
+// returns newly added nodes and edges as layout.{Node/Edge} type, fails
+// without changing s if the strength of an edge is not positive, since
+// layout.NewGraph treats zero as unset
+func appendNodesAndEdges(s *simulationState, nodes []*model.Node, edges []*model.Edge, edgeTypeWeights map[model.EdgeType]float64, edgeWeights EdgeWeightLayoutConfig) ([]*layout.Node, []*layout.Edge, error) {
+	strengths := make([]float64, len(edges))
+	for index, edge := range edges {
+		typeWeight, ok := edgeTypeWeights[edge.Type]
+		if !ok {
+			typeWeight = 1.0
+		}
+		strengths[index] = typeWeight * edgeWeights.strength(edge.Weight)
+		if !(strengths[index] > 0) {
+			return nil, nil, fmt.Errorf("edge %s has strength %v, the weight of edge type '%s' and the edge strengths must be positive", edge.ID, strengths[index], edge.Type)
+		}
+	}
 	newNodes := []*layout.Node{}
 	for index, node := range nodes {
-		newNodes = append(newNodes, &layout.Node{Name: node.Description})
+		name := ""
+		if node.Description != nil {
+			name = node.Description.Text
+		}
+		cluster := -1
+		if node.Cluster != nil {
+			cluster = *node.Cluster
+		}
+		newNodes = append(newNodes, &layout.Node{Name: name, Cluster: cluster})
 		s.modelToLayoutNodeLookup[node.ID] = index + len(s.lnodes)
 	}
+	if s.edgeWeights == nil {
+		s.edgeWeights = make(map[string]float64, len(edges))
+	}
 	newEdges := []*layout.Edge{}
 	for index, edge := range edges {
-		newEdges = append(newEdges, &layout.Edge{Source: s.modelToLayoutNodeLookup[edge.From], Target: s.modelToLayoutNodeLookup[edge.To]})
+		s.edgeWeights[edge.ID] = edge.Weight
+		newEdges = append(newEdges, &layout.Edge{
+			Source:   s.modelToLayoutNodeLookup[edge.From],
+			Target:   s.modelToLayoutNodeLookup[edge.To],
+			Value:    strengths[index],
+			Length:   edgeWeights.length(edge.Weight),
+			Directed: edge.Type == model.EdgeTypePrerequisite,
+		})
 		s.modelToLayoutEdgeLookup[edge.ID] = index + len(s.ledges)
 	}
 	s.lnodes = append(s.lnodes, newNodes...)
 	s.ledges = append(s.ledges, newEdges...)
-	return newNodes, newEdges
+	return newNodes, newEdges, nil
 }
@@ -2,10 +2,13 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/quartercastle/vector"
 	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
 	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
 	"github.com/suxatcode/learn-graph-poc-backend/layout"
 )
@@ -22,7 +25,6 @@ func TestForceSimulationLayouter_GetNodePositions_simple(t *testing.T) {
 	g := &model.Graph{
 		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
 	}
-	close(l.waitForInitialLayout) // assume initial layout is there
 	l.GetNodePositions(context.Background(), g)
 	assert := assert.New(t)
 	assert.Equal([]*model.Node{
@@ -30,6 +32,22 @@ func TestForceSimulationLayouter_GetNodePositions_simple(t *testing.T) {
 	}, g.Nodes)
 }
 
+func TestForceSimulationLayouter_NodePositions(t *testing.T) {
+	l := NewForceSimulationLayouter()
+	assert := assert.New(t)
+	assert.Empty(l.NodePositions(context.Background()), "no layout yet")
+	l.simulationState.lnodes = []*layout.Node{
+		{Name: "1", Pos: vector.Vector{1, 2}}, {Name: "2", Pos: vector.Vector{3, 4}},
+	}
+	l.simulationState.modelToLayoutNodeLookup = map[string]int{"1": 0, "2": 1}
+	assert.Equal(map[string]model.Vector{"1": {X: 1, Y: 2}, "2": {X: 3, Y: 4}}, l.NodePositions(context.Background()))
+	quick := copyState(l.simulationState)
+	quick.lnodes = append(quick.lnodes, &layout.Node{Name: "3", Pos: vector.Vector{5, 6}})
+	quick.modelToLayoutNodeLookup["3"] = 2
+	l.quickState, l.quickBase = quick, l.simulationState
+	assert.Equal(model.Vector{X: 5, Y: 6}, l.NodePositions(context.Background())["3"], "placed by the quick simulation")
+}
+
 func TestForceSimulationLayouter_GetNodePositions_notOrdered(t *testing.T) {
 	l := NewForceSimulationLayouter()
 	l.simulationState.lnodes = []*layout.Node{
@@ -42,7 +60,6 @@ func TestForceSimulationLayouter_GetNodePositions_notOrdered(t *testing.T) {
 	g := &model.Graph{
 		Nodes: []*model.Node{{ID: "2"}, {ID: "1"}},
 	}
-	close(l.waitForInitialLayout) // assume initial layout is there
 	l.GetNodePositions(context.Background(), g)
 	assert := assert.New(t)
 	assert.Equal([]*model.Node{
@@ -50,8 +67,58 @@ func TestForceSimulationLayouter_GetNodePositions_notOrdered(t *testing.T) {
 	}, g.Nodes)
 }
 
-func TestForceSimulationLayouter_GetNodePositions_missingNodes(t *testing.T) {
+func TestForceSimulationLayouter_GetNodePositions_nearNeighbors(t *testing.T) {
 	l := NewForceSimulationLayouter()
+	l.simulationState.lnodes = []*layout.Node{
+		{Name: "1", Pos: vector.Vector{10, 20}}, {Name: "2", Pos: vector.Vector{30, 40}},
+	}
+	l.simulationState.modelToLayoutNodeLookup = map[string]int{"1": 0, "2": 1}
+	l.simulationState.modelToLayoutEdgeLookup = map[string]int{}
+	l.placing = true // no quick simulation
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "new"}, {ID: "isolated"}},
+		Edges: []*model.Edge{{ID: "3", From: "new", To: "1"}, {ID: "4", From: "2", To: "new"}},
+	}
+	l.GetNodePositions(context.Background(), g)
+	assert := assert.New(t)
+	assert.Equal(&model.Vector{X: 20, Y: 30}, g.Nodes[2].Position, "centroid of neighbors")
+	assert.Nil(g.Nodes[3].Position)
+}
+
+func TestForceSimulationLayouter_GetNodePositions_layoutStatus(t *testing.T) {
+	l := NewForceSimulationLayouter()
+	g := &model.Graph{Nodes: []*model.Node{{ID: "1"}}}
+	assert := assert.New(t)
+	l.GetNodePositions(context.Background(), g) // must not block
+	assert.Nil(g.Nodes[0].Position)
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus, "no layout yet")
+
+	l.setComputing(true)
+	l.GetNodePositions(context.Background(), g)
+	assert.Equal(model.LayoutStatusComputing, *g.LayoutStatus)
+	l.setComputing(false)
+
+	l.Reload(context.Background(), &model.Graph{Nodes: []*model.Node{{ID: "1"}}})
+	l.GetNodePositions(context.Background(), g)
+	assert.NotNil(g.Nodes[0].Position)
+	assert.Equal(model.LayoutStatusFresh, *g.LayoutStatus)
+
+	g = &model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}
+	l.GetNodePositions(context.Background(), g)
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus, "new node")
+	l.placements.Wait()
+}
+
+// newSeededForceSimulationLayouter returns a layouter computing reproducible
+// positions for snapshot tests
+func newSeededForceSimulationLayouter() *ForceSimulationLayouter {
+	complete, quick := DefaultCompleteSimulationConfig, DefaultQuickSimulationConfig
+	complete.Seed, quick.Seed = 1, 2
+	return NewForceSimulationLayouter().WithSimulationConfig(complete, quick)
+}
+
+func TestForceSimulationLayouter_GetNodePositions_missingNodes(t *testing.T) {
+	l := newSeededForceSimulationLayouter()
 	l.simulationState.lnodes = []*layout.Node{
 		{Name: "1", Pos: vector.Vector{1, 2, 3}}, {Name: "2", Pos: vector.Vector{3, 4, 5}},
 	}
@@ -63,13 +130,18 @@ func TestForceSimulationLayouter_GetNodePositions_missingNodes(t *testing.T) {
 	g := &model.Graph{
 		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}},
 	}
-	close(l.waitForInitialLayout) // assume initial layout is there
 	l.GetNodePositions(context.Background(), g)
 	assert := assert.New(t)
+	assert.Nil(g.Nodes[2].Position, "no neighbors to place it next to")
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus)
+	// the quick simulation places the node in the background
+	l.placements.Wait()
+	l.GetNodePositions(context.Background(), g)
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus)
 	for i, expected := range []*model.Node{
 		{ID: "1", Position: &model.Vector{X: 1, Y: 2, Z: 3}},
 		{ID: "2", Position: &model.Vector{X: 3, Y: 4, Z: 5}},
-		{ID: "3", Position: &model.Vector{X: 58.737482042038394, Y: 133.03387516414173, Z: 0}}, // XXX(skep): not ready for 3D
+		{ID: "3", Position: &model.Vector{X: -122.47820110133735, Y: -72.23196567363047, Z: 0}}, // XXX(skep): not ready for 3D
 	} {
 		assert.Equal(expected.ID, g.Nodes[i].ID)
 		assert.True(layout.IsClose(expected.Position.X, g.Nodes[i].Position.X), "expected '%v', but got '%v'", expected.Position, g.Nodes[i].Position)
@@ -80,16 +152,16 @@ func TestForceSimulationLayouter_GetNodePositions_missingNodes(t *testing.T) {
 
 // snapshot test that force simulation is executed
 func TestForceSimulationLayouter_Reload(t *testing.T) {
-	l := NewForceSimulationLayouter()
+	l := newSeededForceSimulationLayouter()
 	g := &model.Graph{
-		Nodes: []*model.Node{{ID: "2", Description: "B"}, {ID: "1", Description: "A"}},
+		Nodes: []*model.Node{{ID: "2", Description: &model.LocalizedString{Text: "B"}}, {ID: "1", Description: &model.LocalizedString{Text: "A"}}},
 		Edges: []*model.Edge{{ID: "55", From: "1", To: "2", Weight: 5.0}},
 	}
 	l.Reload(context.Background(), g)
 	assert := assert.New(t)
 	for i, node := range []*layout.Node{
-		{Name: "B", Pos: vector.Vector{-0.11307979551978042, -4.8444672122163555}},
-		{Name: "A", Pos: vector.Vector{0.11307979551978042, 4.8444672122163555}},
+		{Name: "B", Pos: vector.Vector{-6.763579451221459e-05, 5.658157124902487}},
+		{Name: "A", Pos: vector.Vector{6.763579451221459e-05, -5.658157124902487}},
 	} {
 		assert.Equal(node.Name, l.simulationState.lnodes[i].Name)
 		assert.True(layout.IsCloseVec(node.Pos, l.simulationState.lnodes[i].Pos, 0, 0.02), "expected '%v' to be close to '%v' (relative tolerance 0.02)", node.Pos, l.simulationState.lnodes[i].Pos)
@@ -97,3 +169,472 @@ func TestForceSimulationLayouter_Reload(t *testing.T) {
 	assert.Equal(map[string]int{"2": 0, "1": 1}, l.simulationState.modelToLayoutNodeLookup)
 	assert.Equal(map[string]int{"55": 0}, l.simulationState.modelToLayoutEdgeLookup)
 }
+
+func TestForceSimulationLayouter_Reload_reproducible(t *testing.T) {
+	newGraph := func() *model.Graph {
+		g := &model.Graph{}
+		for i := 0; i < 30; i++ {
+			g.Nodes = append(g.Nodes, &model.Node{ID: fmt.Sprint(i)})
+			if i > 0 {
+				g.Edges = append(g.Edges, &model.Edge{ID: fmt.Sprint("e", i), From: fmt.Sprint(i / 2), To: fmt.Sprint(i), Weight: 5.0})
+			}
+		}
+		return g
+	}
+	layout := func() []*model.Node {
+		l := newSeededForceSimulationLayouter()
+		g := newGraph()
+		l.Reload(context.Background(), g)
+		// a node added after the complete simulation is placed by the
+		// quick simulation
+		g.Nodes = append(g.Nodes, &model.Node{ID: "new"})
+		g.Edges = append(g.Edges, &model.Edge{ID: "e-new", From: "0", To: "new", Weight: 5.0})
+		l.GetNodePositions(context.Background(), g)
+		l.placements.Wait()
+		l.GetNodePositions(context.Background(), g)
+		return g.Nodes
+	}
+	assert.Equal(t, layout(), layout())
+}
+
+func TestAppendNodesAndEdges_edgeTypeWeights(t *testing.T) {
+	s := &simulationState{
+		modelToLayoutNodeLookup: map[string]int{},
+		modelToLayoutEdgeLookup: map[string]int{},
+	}
+	_, edges, err := appendNodesAndEdges(s,
+		[]*model.Node{{ID: "1"}, {ID: "2"}},
+		[]*model.Edge{
+			{ID: "3", From: "1", To: "2", Type: model.EdgeTypePrerequisite},
+			{ID: "4", From: "2", To: "1", Type: model.EdgeTypeRelatedTo},
+		},
+		map[model.EdgeType]float64{model.EdgeTypePrerequisite: 2.0, model.EdgeTypeRelatedTo: 0.5},
+		EdgeWeightLayoutConfig{MinStrength: 1, MaxStrength: 1},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []*layout.Edge{
+		{Source: 0, Target: 1, Value: 2.0, Directed: true},
+		{Source: 1, Target: 0, Value: 0.5},
+	}, edges)
+}
+
+func TestAppendNodesAndEdges_edgeWeights(t *testing.T) {
+	s := &simulationState{
+		modelToLayoutNodeLookup: map[string]int{},
+		modelToLayoutEdgeLookup: map[string]int{},
+	}
+	_, edges, err := appendNodesAndEdges(s,
+		[]*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		[]*model.Edge{
+			{ID: "4", From: "1", To: "2", Weight: 1, Type: model.EdgeTypeRelatedTo},
+			{ID: "5", From: "2", To: "3", Weight: 5.5, Type: model.EdgeTypeRelatedTo},
+			{ID: "6", From: "3", To: "1", Weight: 10, Type: model.EdgeTypeRelatedTo},
+			{ID: "7", From: "3", To: "2", Weight: 20},
+		},
+		map[model.EdgeType]float64{model.EdgeTypeRelatedTo: 1.0},
+		EdgeWeightLayoutConfig{MinWeight: 1, MaxWeight: 10, MinStrength: 1, MaxStrength: 3, LengthAtMinWeight: 300, LengthAtMaxWeight: 100},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []*layout.Edge{
+		{Source: 0, Target: 1, Value: 1.0, Length: 300},
+		{Source: 1, Target: 2, Value: 2.0, Length: 200},
+		{Source: 2, Target: 0, Value: 3.0, Length: 100},
+		{Source: 2, Target: 1, Value: 3.0, Length: 100}, // clamped, unknown type weight 1.0
+	}, edges)
+	assert.Equal(t, map[string]float64{"4": 1, "5": 5.5, "6": 10, "7": 20}, s.edgeWeights)
+}
+
+func TestAppendNodesAndEdges_zeroStrength(t *testing.T) {
+	s := &simulationState{
+		modelToLayoutNodeLookup: map[string]int{},
+		modelToLayoutEdgeLookup: map[string]int{},
+	}
+	_, _, err := appendNodesAndEdges(s,
+		[]*model.Node{{ID: "1"}, {ID: "2"}},
+		[]*model.Edge{{ID: "3", From: "1", To: "2", Weight: 5, Type: model.EdgeTypeRelatedTo}},
+		map[model.EdgeType]float64{model.EdgeTypeRelatedTo: 0},
+		DefaultEdgeWeightLayoutConfig,
+	)
+	assert.Error(t, err, "zero would be read as unset, i.e. full strength")
+	assert.Empty(t, s.lnodes)
+	assert.Empty(t, s.modelToLayoutNodeLookup)
+}
+
+func TestAppendNodesAndEdges_clusters(t *testing.T) {
+	s := &simulationState{
+		modelToLayoutNodeLookup: map[string]int{},
+		modelToLayoutEdgeLookup: map[string]int{},
+	}
+	nodes, _, err := appendNodesAndEdges(s,
+		[]*model.Node{{ID: "1", Cluster: intPtr(3)}, {ID: "2"}},
+		[]*model.Edge{},
+		map[model.EdgeType]float64{},
+		EdgeWeightLayoutConfig{},
+	)
+	assert.NoError(t, err)
+	if assert.Len(t, nodes, 2) {
+		assert.Equal(t, 3, nodes[0].Cluster)
+		assert.Equal(t, -1, nodes[1].Cluster)
+	}
+}
+
+func TestForceSimulationLayouter_WithEdgeWeights(t *testing.T) {
+	l := NewForceSimulationLayouter().WithEdgeWeights(EdgeWeightLayoutConfig{})
+	assert := assert.New(t)
+	assert.Equal(DefaultEdgeWeightLayoutConfig, l.edgeWeights)
+	conf := DefaultEdgeWeightLayoutConfig
+	conf.DirectionalStrength = 0.5
+	l.WithEdgeWeights(conf)
+	assert.Equal(conf, l.edgeWeights)
+	assert.Equal(0.5, l.completeSimulation.Config().DirectionalStrength)
+	assert.Equal(0.5, l.quickSimulation.Config().DirectionalStrength)
+}
+
+type layoutStoreStub struct {
+	layout *db.Layout
+	saved  []*db.Layout
+	pins   map[string]model.Vector
+}
+
+func (s *layoutStoreStub) NodePositions(ctx context.Context) (*db.Layout, error) {
+	return s.layout, nil
+}
+
+func (s *layoutStoreStub) SaveNodePositions(ctx context.Context, layout *db.Layout) error {
+	s.saved = append(s.saved, layout)
+	return nil
+}
+
+func (s *layoutStoreStub) NodePins(ctx context.Context) (map[string]model.Vector, error) {
+	pins := make(map[string]model.Vector, len(s.pins))
+	for id, pos := range s.pins {
+		pins[id] = pos
+	}
+	return pins, nil
+}
+
+func (s *layoutStoreStub) PinNode(ctx context.Context, user db.User, nodeID string, position model.Vector) error {
+	s.pins[nodeID] = position
+	return nil
+}
+
+func (s *layoutStoreStub) UnpinNode(ctx context.Context, user db.User, nodeID string) error {
+	delete(s.pins, nodeID)
+	return nil
+}
+
+func TestForceSimulationLayouter_Reload_pinnedNodes(t *testing.T) {
+	store := &layoutStoreStub{pins: map[string]model.Vector{"1": {X: -500, Y: 300}, "3": {}}}
+	l := newSeededForceSimulationLayouter().WithStore(store)
+	newGraph := func() *model.Graph {
+		return &model.Graph{
+			Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+			Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}, {ID: "23", From: "2", To: "3"}},
+		}
+	}
+	positions := func() map[string]model.Vector {
+		g := newGraph()
+		l.GetNodePositions(context.Background(), g)
+		res := map[string]model.Vector{}
+		for _, node := range g.Nodes {
+			res[node.ID] = *node.Position
+		}
+		return res
+	}
+	assert := assert.New(t)
+	stats := l.Reload(context.Background(), newGraph())
+	assert.Greater(stats.Iterations, 0)
+	assert.Equal(model.Vector{X: -500, Y: 300}, positions()["1"])
+	assert.Equal(model.Vector{}, positions()["3"], "pinned at the origin")
+	assert.NotEqual(model.Vector{}, positions()["2"])
+
+	stats = l.Reload(context.Background(), newGraph())
+	assert.Equal(0, stats.Iterations, "nothing changed")
+
+	store.pins["1"] = model.Vector{X: 400, Y: -100}
+	stats = l.Reload(context.Background(), newGraph())
+	assert.Greater(stats.Iterations, 0, "pin moved")
+	assert.Equal(model.Vector{X: 400, Y: -100}, positions()["1"])
+
+	delete(store.pins, "1")
+	stats = l.Reload(context.Background(), newGraph())
+	assert.Greater(stats.Iterations, 0, "unpinned")
+	assert.False(l.simulationState.lnodes[l.simulationState.modelToLayoutNodeLookup["1"]].IsPinned)
+	assert.True(l.simulationState.lnodes[l.simulationState.modelToLayoutNodeLookup["3"]].IsPinned)
+}
+
+func TestChangedPins(t *testing.T) {
+	assert.Equal(t,
+		map[string]bool{"moved": true, "new": true, "removed": true},
+		changedPins(
+			map[string]model.Vector{"same": {X: 1}, "moved": {X: 1}, "removed": {X: 1}},
+			map[string]model.Vector{"same": {X: 1}, "moved": {X: 2}, "new": {X: 1}},
+		),
+	)
+	assert.Empty(t, changedPins(nil, map[string]model.Vector{}))
+}
+
+func TestForceSimulationLayouter_Reload_restoresPersistedLayout(t *testing.T) {
+	store := &layoutStoreStub{layout: &db.Layout{Version: 7, Positions: map[string]model.Vector{
+		"1": {X: 1, Y: 2}, "2": {X: 3, Y: 4},
+	}}}
+	l := NewForceSimulationLayouter().WithStore(store)
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
+		Edges: []*model.Edge{{ID: "55", From: "1", To: "2"}},
+	}
+	stats := l.Reload(context.Background(), g)
+	assert := assert.New(t)
+	assert.Equal(0, stats.Iterations, "nothing to compute")
+	assert.Empty(store.saved)
+	assert.Equal(7, l.version)
+	g = &model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}
+	l.GetNodePositions(context.Background(), g) // must not block
+	assert.Equal([]*model.Node{
+		{ID: "1", Position: &model.Vector{X: 1, Y: 2}}, {ID: "2", Position: &model.Vector{X: 3, Y: 4}},
+	}, g.Nodes)
+}
+
+func TestForceSimulationLayouter_Reload_persistsLayout(t *testing.T) {
+	store := &layoutStoreStub{layout: &db.Layout{Version: 7, Positions: map[string]model.Vector{
+		"1": {X: 100, Y: 200}, "2": {X: 300, Y: 400},
+	}}}
+	l := NewForceSimulationLayouter().WithStore(store)
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		Edges: []*model.Edge{{ID: "55", From: "1", To: "2"}, {ID: "56", From: "3", To: "2"}},
+	}
+	stats := l.Reload(context.Background(), g)
+	assert := assert.New(t)
+	assert.NotZero(stats.Iterations, "node 3 has no position yet")
+	if assert.Len(store.saved, 1) {
+		assert.Equal(8, store.saved[0].Version)
+		assert.Len(store.saved[0].Positions, 3)
+		for _, node := range g.Nodes {
+			assert.Equal(*node.Position, store.saved[0].Positions[node.ID])
+		}
+	}
+	assert.Equal(map[string]int{"1": 0, "2": 1, "3": 2}, l.simulationState.modelToLayoutNodeLookup)
+}
+
+func TestForceSimulationLayouter_Reload_noPersistedLayout(t *testing.T) {
+	store := &layoutStoreStub{}
+	l := NewForceSimulationLayouter().WithStore(store)
+	g := &model.Graph{Nodes: []*model.Node{{ID: "1"}, {ID: "2"}}}
+	l.Reload(context.Background(), g)
+	assert := assert.New(t)
+	if assert.Len(store.saved, 1) {
+		assert.Equal(1, store.saved[0].Version)
+		assert.Len(store.saved[0].Positions, 2)
+	}
+}
+
+func TestChangedNodes(t *testing.T) {
+	s := &simulationState{
+		lnodes:                  []*layout.Node{{}, {}, {}, {}},
+		ledges:                  []*layout.Edge{{Source: 0, Target: 1}, {Source: 2, Target: 3}},
+		modelToLayoutNodeLookup: map[string]int{"1": 0, "2": 1, "3": 2, "4": 3},
+		modelToLayoutEdgeLookup: map[string]int{"12": 0, "34": 1},
+		edgeWeights:             map[string]float64{"12": 0, "34": 0},
+	}
+	for _, test := range []struct {
+		Name  string
+		Graph *model.Graph
+		Exp   map[string]bool
+	}{
+		{
+			Name: "unchanged",
+			Graph: &model.Graph{
+				Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}},
+				Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}, {ID: "34", From: "3", To: "4"}},
+			},
+			Exp: map[string]bool{},
+		},
+		{
+			Name: "new node and edge",
+			Graph: &model.Graph{
+				Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}},
+				Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}, {ID: "34", From: "3", To: "4"}, {ID: "15", From: "1", To: "5"}},
+			},
+			Exp: map[string]bool{"1": true, "5": true},
+		},
+		{
+			Name: "removed edge",
+			Graph: &model.Graph{
+				Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}},
+				Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}},
+			},
+			Exp: map[string]bool{"3": true, "4": true},
+		},
+		{
+			Name: "removed node",
+			Graph: &model.Graph{
+				Nodes: []*model.Node{{ID: "1"}, {ID: "3"}, {ID: "4"}},
+				Edges: []*model.Edge{{ID: "34", From: "3", To: "4"}},
+			},
+			Exp: map[string]bool{"1": true},
+		},
+		{
+			Name: "re-weighted edge",
+			Graph: &model.Graph{
+				Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}},
+				Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}, {ID: "34", From: "3", To: "4", Weight: 5}},
+			},
+			Exp: map[string]bool{"3": true, "4": true},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Exp, changedNodes(s, test.Graph))
+		})
+	}
+}
+
+func TestNeighborhood(t *testing.T) {
+	g := &model.Graph{Edges: []*model.Edge{
+		{From: "1", To: "2"}, {From: "3", To: "2"}, {From: "3", To: "4"},
+	}}
+	neighbors := adjacency(g)
+	assert := assert.New(t)
+	assert.Equal(map[string]bool{"1": true}, neighborhood(neighbors, map[string]bool{"1": true}, 0))
+	assert.Equal(map[string]bool{"1": true, "2": true}, neighborhood(neighbors, map[string]bool{"1": true}, 1))
+	assert.Equal(map[string]bool{"1": true, "2": true, "3": true}, neighborhood(neighbors, map[string]bool{"1": true}, 2))
+}
+
+func TestForceSimulationLayouter_Reload_warmStart(t *testing.T) {
+	l := NewForceSimulationLayouter()
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}},
+		Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}, {ID: "23", From: "2", To: "3"}, {ID: "34", From: "3", To: "4"}},
+	}
+	l.Reload(context.Background(), g)
+	before := map[string]model.Vector{}
+	for _, node := range g.Nodes {
+		before[node.ID] = *node.Position
+	}
+	g = &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}},
+		Edges: []*model.Edge{
+			{ID: "12", From: "1", To: "2"}, {ID: "23", From: "2", To: "3"}, {ID: "34", From: "3", To: "4"},
+			{ID: "45", From: "4", To: "5"},
+		},
+	}
+	stats := l.Reload(context.Background(), g)
+	assert := assert.New(t)
+	assert.NotZero(stats.Iterations)
+	distance := func(a, b model.Vector) float64 {
+		return vector.Vector{a.X - b.X, a.Y - b.Y}.Magnitude()
+	}
+	for _, node := range g.Nodes[:4] {
+		assert.LessOrEqual(distance(before[node.ID], *node.Position), l.incremental.MaxDisplacement+1e-9, "node %s moved too far", node.ID)
+	}
+	lnodes := l.simulationState.lnodes
+	lookup := l.simulationState.modelToLayoutNodeLookup
+	assert.Equal(1.0, lnodes[lookup["4"]].Mobility, "neighbor of new node is reheated")
+	assert.Equal(1.0, lnodes[lookup["3"]].Mobility, "within reheat hops")
+	assert.Equal(l.incremental.Mobility, lnodes[lookup["1"]].Mobility, "far away from change")
+}
+
+func TestForceSimulationLayouter_Reload_3D(t *testing.T) {
+	store := &layoutStoreStub{}
+	l := NewForceSimulationLayouter().WithDimensions(3).WithStore(store)
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		Edges: []*model.Edge{{ID: "12", From: "1", To: "2"}},
+	}
+	l.Reload(context.Background(), g)
+	assert := assert.New(t)
+	for _, node := range g.Nodes {
+		assert.NotZero(node.Position.Z, "node %s has no z-coordinate", node.ID)
+	}
+	restored := NewForceSimulationLayouter().WithDimensions(3).WithStore(&layoutStoreStub{layout: store.saved[0]})
+	restored.Reload(context.Background(), g)
+	for i, node := range g.Nodes {
+		assert.Len(restored.simulationState.lnodes[i].Pos, 3)
+		assert.Equal(store.saved[0].Positions[node.ID].Z, restored.simulationState.lnodes[i].Pos.Z())
+	}
+}
+
+func TestGetLayouterEnvConfig(t *testing.T) {
+	t.Setenv("LAYOUT_TIMEOUT", "1m")
+	t.Setenv("LAYOUT_COMPLETE_ALPHA_DECAY", "0.01")
+	t.Setenv("LAYOUT_COMPLETE_INITIAL_LAYOUT", "sphere")
+	t.Setenv("LAYOUT_QUICK_RECT_WIDTH", "500")
+	t.Setenv("LAYOUT_QUICK_GRAVITY", "false")
+	conf, err := GetLayouterEnvConfig()
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.Equal(time.Minute, conf.Timeout)
+	assert.Equal(0.01, conf.CompleteSimulation.AlphaDecay)
+	assert.Equal(layout.InitialLayoutSphere, conf.CompleteSimulation.InitialLayout)
+	assert.Equal(DefaultCompleteSimulationConfig.AlphaInit, conf.CompleteSimulation.AlphaInit, "unset values keep their default")
+	assert.True(conf.CompleteSimulation.Gravity)
+	assert.Equal(500.0, conf.QuickSimulation.Rect.Width)
+	assert.Equal(DefaultQuickSimulationConfig.Rect.Height, conf.QuickSimulation.Rect.Height)
+	assert.False(conf.QuickSimulation.Gravity)
+	assert.Equal(DefaultQuickSimulationConfig.AlphaDecay, conf.QuickSimulation.AlphaDecay)
+	assert.NoError(conf.Validate())
+}
+
+func TestGetLayouterEnvConfig_invalid(t *testing.T) {
+	t.Setenv("LAYOUT_COMPLETE_THETA", "many")
+	_, err := GetLayouterEnvConfig()
+	assert.Error(t, err)
+}
+
+func TestDefaultLayouterConfig(t *testing.T) {
+	t.Setenv("LAYOUT_DIMENSIONS", "3")
+	conf := DefaultLayouterConfig()
+	assert := assert.New(t)
+	assert.Equal(2, conf.Dimensions, "environment is ignored")
+	assert.Equal(LayoutAlgorithmForce, conf.Algorithm)
+	assert.Equal(5*time.Minute, conf.Timeout)
+	assert.Equal(DefaultEdgeWeightLayoutConfig, conf.EdgeWeights)
+	assert.NoError(conf.Validate())
+}
+
+func TestLayouterConfig_Validate(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Modify func(*LayouterConfig)
+		ExpErr string
+	}{
+		{Name: "default", Modify: func(conf *LayouterConfig) {}},
+		{Name: "unknown algorithm", Modify: func(conf *LayouterConfig) { conf.Algorithm = "spiral" }, ExpErr: "algorithm"},
+		{Name: "dimensions", Modify: func(conf *LayouterConfig) { conf.Dimensions = 1 }, ExpErr: "dimensions"},
+		{Name: "timeout", Modify: func(conf *LayouterConfig) { conf.Timeout = 0 }, ExpErr: "timeout"},
+		{Name: "edge weight range", Modify: func(conf *LayouterConfig) { conf.EdgeWeights.MaxWeight = 0 }, ExpErr: "edge weight range"},
+		{Name: "edge length", Modify: func(conf *LayouterConfig) { conf.EdgeWeights.LengthAtMaxWeight = -1 }, ExpErr: "edge lengths"},
+		{Name: "zero edge strength", Modify: func(conf *LayouterConfig) { conf.EdgeWeights.MinStrength = 0 }, ExpErr: "edge strengths"},
+		{Name: "complete simulation", Modify: func(conf *LayouterConfig) { conf.CompleteSimulation.AlphaDecay = 2 }, ExpErr: "complete simulation: AlphaDecay"},
+		{Name: "quick simulation", Modify: func(conf *LayouterConfig) { conf.QuickSimulation.FrameTime = -1 }, ExpErr: "quick simulation: FrameTime"},
+		{Name: "simulation dimensions", Modify: func(conf *LayouterConfig) { conf.QuickSimulation.Dimensions = 3 }, ExpErr: "quick simulation: dimensions"},
+		{Name: "simulation directional strength", Modify: func(conf *LayouterConfig) { conf.CompleteSimulation.DirectionalStrength = 1 }, ExpErr: "complete simulation: directional strength"},
+		{Name: "simulation dimensions set consistently", Modify: func(conf *LayouterConfig) {
+			conf.Dimensions, conf.CompleteSimulation.Dimensions = 3, 3
+		}},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			conf := DefaultLayouterConfig()
+			test.Modify(&conf)
+			err := conf.Validate()
+			if test.ExpErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.ExpErr)
+			}
+			_, err = NewLayouter(nil, conf)
+			assert.Error(t, err, "NewLayouter validates")
+		})
+	}
+}
+
+func TestForceSimulationLayouter_WithSimulationConfig(t *testing.T) {
+	complete, quick := DefaultCompleteSimulationConfig, DefaultQuickSimulationConfig
+	complete.Theta, quick.Theta = 0.5, 0.9
+	l := NewForceSimulationLayouter().WithSimulationConfig(complete, quick)
+	assert := assert.New(t)
+	assert.Equal(0.5, l.completeSimulation.Config().Theta)
+	assert.Equal(0.9, l.quickSimulation.Config().Theta)
+}
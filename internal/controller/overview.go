@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+// MaxOverviewLevel limits the grid of GraphOverview to 2^MaxOverviewLevel
+// squares per axis
+const MaxOverviewLevel = 10
+
+// ViewportNodeLimit is the maximum number of nodes returned by GraphViewport
+// at zoom 1, lower zoom levels return proportionally less nodes
+var ViewportNodeLimit = 5000
+
+// GraphOverview aggregates the positioned nodes for zoomed-out views: level 0
+// groups them by cluster, level n > 0 by the squares of a 2^n x 2^n grid
+// over their bounding square. Only the edges and the representatives of the
+// groups are read from the DB.
+func (c *Controller) GraphOverview(ctx context.Context, level int) (*model.GraphOverview, error) {
+	if level < 0 || level > MaxOverviewLevel {
+		err := fmt.Errorf("level must be within [0, %d], got %d", MaxOverviewLevel, level)
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	edges, err := c.db.Edges(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	g := &model.Graph{Edges: edges}
+	positions := c.layouter.NodePositions(ctx)
+	for _, id := range sortedNodeIDs(positions) {
+		pos := positions[id]
+		g.Nodes = append(g.Nodes, &model.Node{ID: id, Position: &pos})
+	}
+	c.assignClusters(g)
+	var group groupFunc = clusterGroup
+	if level > 0 {
+		group = cellGroup(g.Nodes, level)
+	}
+	overview := aggregateGraph(g, group)
+	if err := c.loadRepresentatives(ctx, overview); err != nil {
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("GraphOverview() returns %d nodes and %d edges", len(overview.Nodes), len(overview.Edges))
+	return overview, nil
+}
+
+// loadRepresentatives replaces the representatives of the overview nodes,
+// which only have an ID, position and cluster, by the nodes from the DB
+func (c *Controller) loadRepresentatives(ctx context.Context, overview *model.GraphOverview) error {
+	ids := make([]string, 0, len(overview.Nodes))
+	for _, overviewNode := range overview.Nodes {
+		ids = append(ids, overviewNode.Representative.ID)
+	}
+	g, err := c.db.Subgraph(ctx, ids)
+	if err != nil || g == nil {
+		return err
+	}
+	c.assignClusters(g)
+	loaded := make(map[string]*model.Node, len(g.Nodes))
+	for _, node := range g.Nodes {
+		loaded[node.ID] = node
+	}
+	for _, overviewNode := range overview.Nodes {
+		// a node deleted since the last graph embedding keeps its ID only
+		if node, ok := loaded[overviewNode.Representative.ID]; ok {
+			node.Position = overviewNode.Representative.Position
+			overviewNode.Representative = node
+		}
+	}
+	return nil
+}
+
+// sortedNodeIDs returns the node IDs of positions in order of creation, like
+// the nodes of the DB
+func sortedNodeIDs(positions map[string]model.Vector) []string {
+	ids := make([]string, 0, len(positions))
+	for id := range positions {
+		ids = append(ids, id)
+	}
+	// IDs are increasing numbers
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// groupFunc returns the ID of the overview node of a node and its cluster,
+// ok is false for nodes left out of the overview
+type groupFunc func(node *model.Node) (id string, cluster *int, ok bool)
+
+// clusterGroup groups nodes by cluster, nodes without cluster stay alone
+func clusterGroup(node *model.Node) (string, *int, bool) {
+	if node.Cluster == nil {
+		return "node:" + node.ID, nil, true
+	}
+	return fmt.Sprintf("cluster:%d", *node.Cluster), node.Cluster, true
+}
+
+// cellGroup groups nodes by the square of a 2^level x 2^level grid over the
+// bounding square of the positioned nodes, nodes without position are left
+// out
+func cellGroup(nodes []*model.Node, level int) groupFunc {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, node := range nodes {
+		if node.Position == nil {
+			continue
+		}
+		minX, maxX = math.Min(minX, node.Position.X), math.Max(maxX, node.Position.X)
+		minY, maxY = math.Min(minY, node.Position.Y), math.Max(maxY, node.Position.Y)
+	}
+	size := math.Max(maxX-minX, maxY-minY)
+	cells := 1 << level
+	cell := func(pos, min float64) int {
+		if size <= 0 {
+			return 0
+		}
+		i := int((pos - min) / size * float64(cells))
+		if i >= cells {
+			i = cells - 1
+		}
+		return i
+	}
+	return func(node *model.Node) (string, *int, bool) {
+		if node.Position == nil {
+			return "", nil, false
+		}
+		return fmt.Sprintf("cell:%d:%d:%d", level, cell(node.Position.X, minX), cell(node.Position.Y, minY)), nil, true
+	}
+}
+
+// aggregateGraph merges the nodes of each group into an overview node, and
+// all edges between two groups into an overview edge, both in order of their
+// first occurrence in g
+func aggregateGraph(g *model.Graph, group groupFunc) *model.GraphOverview {
+	importance := nodeImportance(g.Edges)
+	overview := &model.GraphOverview{Nodes: []*model.OverviewNode{}, Edges: []*model.OverviewEdge{}}
+	overviewNodes := map[string]*model.OverviewNode{}
+	nodeToGroup := map[string]string{}
+	positionSums := map[string]*model.Vector{}
+	positioned := map[string]int{}
+	for _, node := range g.Nodes {
+		id, cluster, ok := group(node)
+		if !ok {
+			continue
+		}
+		nodeToGroup[node.ID] = id
+		overviewNode, exists := overviewNodes[id]
+		if !exists {
+			overviewNode = &model.OverviewNode{ID: id, Cluster: cluster, Representative: node}
+			overviewNodes[id] = overviewNode
+			positionSums[id] = &model.Vector{}
+			overview.Nodes = append(overview.Nodes, overviewNode)
+		}
+		overviewNode.NodeCount += 1
+		if importance[node.ID] > importance[overviewNode.Representative.ID] {
+			overviewNode.Representative = node
+		}
+		if node.Position != nil {
+			sum := positionSums[id]
+			sum.X, sum.Y, sum.Z = sum.X+node.Position.X, sum.Y+node.Position.Y, sum.Z+node.Position.Z
+			positioned[id] += 1
+		}
+	}
+	for id, overviewNode := range overviewNodes {
+		if count := float64(positioned[id]); count > 0 {
+			sum := positionSums[id]
+			overviewNode.Position = &model.Vector{X: sum.X / count, Y: sum.Y / count, Z: sum.Z / count}
+		}
+	}
+	overviewEdges := map[[2]string]*model.OverviewEdge{}
+	for _, edge := range g.Edges {
+		from, okFrom := nodeToGroup[edge.From]
+		to, okTo := nodeToGroup[edge.To]
+		if !okFrom || !okTo || from == to {
+			continue
+		}
+		key := [2]string{from, to}
+		if from > to {
+			key = [2]string{to, from}
+		}
+		overviewEdge, exists := overviewEdges[key]
+		if !exists {
+			overviewEdge = &model.OverviewEdge{From: from, To: to}
+			overviewEdges[key] = overviewEdge
+			overview.Edges = append(overview.Edges, overviewEdge)
+		}
+		overviewEdge.Weight += edge.Weight
+		overviewEdge.EdgeCount += 1
+	}
+	return overview
+}
+
+// nodeImportance returns node ID → sum of the weights of its edges
+func nodeImportance(edges []*model.Edge) map[string]float64 {
+	importance := map[string]float64{}
+	for _, edge := range edges {
+		importance[edge.From] += edge.Weight
+		importance[edge.To] += edge.Weight
+	}
+	return importance
+}
+
+// GraphViewport returns the positioned nodes inside rect and the edges
+// between them. With zoom < 1 only the zoom * ViewportNodeLimit most
+// important nodes are returned. The nodes inside rect are found by their
+// positions from the layouter, only they are read from the DB.
+func (c *Controller) GraphViewport(ctx context.Context, rect model.RectInput, zoom *float64) (*model.Graph, error) {
+	if !isFinite(rect.X, rect.Y, rect.Width, rect.Height) || rect.Width < 0 || rect.Height < 0 {
+		err := fmt.Errorf("invalid rect %v", rect)
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	if zoom != nil && (!isFinite(*zoom) || *zoom <= 0) {
+		err := fmt.Errorf("invalid zoom %v", *zoom)
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		return nil, err
+	}
+	positions := c.layouter.NodePositions(ctx)
+	nodeIDs := db.FindAll(sortedNodeIDs(positions), func(id string) bool {
+		pos := positions[id]
+		return rect.X <= pos.X && pos.X <= rect.X+rect.Width &&
+			rect.Y <= pos.Y && pos.Y <= rect.Y+rect.Height
+	})
+	limit := ViewportNodeLimit
+	if zoom != nil && *zoom < 1 {
+		limit = int(math.Ceil(float64(limit) * *zoom))
+	}
+	if len(nodeIDs) > limit {
+		edges, err := c.db.Edges(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("%v", err)
+			return nil, err
+		}
+		importance := nodeImportance(edges)
+		sort.SliceStable(nodeIDs, func(i, j int) bool {
+			return importance[nodeIDs[i]] > importance[nodeIDs[j]]
+		})
+		nodeIDs = nodeIDs[:limit]
+	}
+	g, err := c.db.Subgraph(ctx, nodeIDs)
+	if err != nil || g == nil {
+		log.Ctx(ctx).Error().Msgf("%v | graph=%v", err, g)
+		return nil, err
+	}
+	c.assignClusters(g)
+	c.layouter.GetNodePositions(ctx, g)
+	log.Ctx(ctx).Debug().Msgf("GraphViewport() returns %d nodes and %d edges", len(g.Nodes), len(g.Edges))
+	return g, nil
+}
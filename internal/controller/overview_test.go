@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+func overviewTestGraph() *model.Graph {
+	return &model.Graph{
+		Nodes: []*model.Node{
+			{ID: "1", Position: &model.Vector{X: 0, Y: 0}},
+			{ID: "2", Position: &model.Vector{X: 2, Y: 0}},
+			{ID: "3", Position: &model.Vector{X: 10, Y: 10}},
+			{ID: "4", Position: &model.Vector{X: 10, Y: 8}},
+			{ID: "5"},
+		},
+		Edges: []*model.Edge{
+			{ID: "6", From: "1", To: "2", Weight: 1},
+			{ID: "7", From: "2", To: "3", Weight: 2},
+			{ID: "8", From: "4", To: "1", Weight: 3},
+			{ID: "9", From: "3", To: "4", Weight: 4},
+		},
+	}
+}
+
+// overviewTestPositions returns the positions of the positioned nodes of
+// overviewTestGraph, like the layouter
+func overviewTestPositions() map[string]model.Vector {
+	positions := map[string]model.Vector{}
+	for _, node := range overviewTestGraph().Nodes {
+		if node.Position != nil {
+			positions[node.ID] = *node.Position
+		}
+	}
+	return positions
+}
+
+// overviewTestSubgraph returns the nodes of overviewTestGraph with nodeIDs
+// and the edges between them, without positions like the DB
+func overviewTestSubgraph(ctx context.Context, nodeIDs []string) (*model.Graph, error) {
+	g := overviewTestGraph()
+	g.Nodes = db.FindAll(g.Nodes, func(node *model.Node) bool { return db.Contains(nodeIDs, node.ID) })
+	for _, node := range g.Nodes {
+		node.Position = nil
+	}
+	g.Edges = db.FindAll(g.Edges, func(edge *model.Edge) bool {
+		return db.Contains(nodeIDs, edge.From) && db.Contains(nodeIDs, edge.To)
+	})
+	return g, nil
+}
+
+func TestController_GraphOverview(t *testing.T) {
+	representative := func(id string, pos model.Vector, cluster int) *model.Node {
+		return &model.Node{ID: id, Position: &pos, Cluster: intPtr(cluster)}
+	}
+	for _, test := range []struct {
+		Name      string
+		Level     int
+		Expect    *model.GraphOverview
+		ExpectDB  bool
+		EdgesErr  error
+		ExpectErr bool
+	}{
+		{
+			Name:     "level 0: clusters",
+			Level:    0,
+			ExpectDB: true,
+			Expect: &model.GraphOverview{
+				Nodes: []*model.OverviewNode{
+					{ID: "cluster:0", NodeCount: 2, Position: &model.Vector{X: 1, Y: 0}, Cluster: intPtr(0), Representative: representative("1", model.Vector{X: 0, Y: 0}, 0)},
+					{ID: "cluster:1", NodeCount: 2, Position: &model.Vector{X: 10, Y: 9}, Cluster: intPtr(1), Representative: representative("4", model.Vector{X: 10, Y: 8}, 1)},
+				},
+				Edges: []*model.OverviewEdge{
+					{From: "cluster:0", To: "cluster:1", Weight: 5, EdgeCount: 2},
+				},
+			},
+		},
+		{
+			Name:     "level 1: 2x2 grid",
+			Level:    1,
+			ExpectDB: true,
+			Expect: &model.GraphOverview{
+				Nodes: []*model.OverviewNode{
+					{ID: "cell:1:0:0", NodeCount: 2, Position: &model.Vector{X: 1, Y: 0}, Representative: representative("1", model.Vector{X: 0, Y: 0}, 0)},
+					{ID: "cell:1:1:1", NodeCount: 2, Position: &model.Vector{X: 10, Y: 9}, Representative: representative("4", model.Vector{X: 10, Y: 8}, 1)},
+				},
+				Edges: []*model.OverviewEdge{
+					{From: "cell:1:0:0", To: "cell:1:1:1", Weight: 5, EdgeCount: 2},
+				},
+			},
+		},
+		{
+			Name:      "invalid level",
+			Level:     MaxOverviewLevel + 1,
+			ExpectErr: true,
+		},
+		{
+			Name:      "edges fail",
+			Level:     0,
+			EdgesErr:  errors.New("connection lost"),
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockDB := db.NewMockDB(ctrl)
+			l := NewMockLayouter(ctrl)
+			ctx := context.Background()
+			if test.ExpectDB {
+				mockDB.EXPECT().Edges(ctx).Return(overviewTestGraph().Edges, nil)
+				l.EXPECT().NodePositions(ctx).Return(overviewTestPositions())
+				mockDB.EXPECT().Subgraph(ctx, []string{"1", "4"}).DoAndReturn(overviewTestSubgraph)
+			}
+			if test.EdgesErr != nil {
+				mockDB.EXPECT().Edges(ctx).Return(nil, test.EdgesErr)
+			}
+			c := NewController(mockDB, l)
+			c.clusters = map[string]int{"1": 0, "2": 0, "3": 1, "4": 1}
+			overview, err := c.GraphOverview(ctx, test.Level)
+			if test.ExpectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.Expect, overview)
+		})
+	}
+}
+
+func TestController_GraphViewport(t *testing.T) {
+	for _, test := range []struct {
+		Name        string
+		Rect        model.RectInput
+		Zoom        *float64
+		Limit       int
+		ExpectNodes []string
+		ExpectEdges []string
+		ExpectErr   bool
+		// IDs of the nodes read from the DB
+		ExpectNodesRead []string
+		// edges are read to find the most important nodes
+		ExpectEdgesRead bool
+	}{
+		{
+			Name:            "nodes inside rect",
+			Rect:            model.RectInput{X: -1, Y: -1, Width: 4, Height: 2},
+			ExpectNodes:     []string{"1", "2"},
+			ExpectEdges:     []string{"6"},
+			ExpectNodesRead: []string{"1", "2"},
+		},
+		{
+			Name:            "border is inside",
+			Rect:            model.RectInput{X: 2, Y: 0, Width: 8, Height: 10},
+			ExpectNodes:     []string{"2", "3", "4"},
+			ExpectEdges:     []string{"7", "9"},
+			ExpectNodesRead: []string{"2", "3", "4"},
+		},
+		{
+			Name:            "zoom limits to most important nodes",
+			Rect:            model.RectInput{X: -100, Y: -100, Width: 200, Height: 200},
+			Zoom:            floatPtr(0.5),
+			Limit:           4,
+			ExpectNodes:     []string{"3", "4"},
+			ExpectEdges:     []string{"9"},
+			ExpectNodesRead: []string{"4", "3"},
+			ExpectEdgesRead: true,
+		},
+		{
+			Name:      "invalid rect",
+			Rect:      model.RectInput{X: 0, Y: 0, Width: -1, Height: 1},
+			ExpectErr: true,
+		},
+		{
+			Name:      "invalid zoom",
+			Rect:      model.RectInput{X: 0, Y: 0, Width: 1, Height: 1},
+			Zoom:      floatPtr(0),
+			ExpectErr: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockDB := db.NewMockDB(ctrl)
+			l := NewMockLayouter(ctrl)
+			ctx := context.Background()
+			if !test.ExpectErr {
+				l.EXPECT().NodePositions(ctx).Return(overviewTestPositions())
+				mockDB.EXPECT().Subgraph(ctx, test.ExpectNodesRead).DoAndReturn(overviewTestSubgraph)
+				l.EXPECT().GetNodePositions(ctx, gomock.Any())
+			}
+			if test.ExpectEdgesRead {
+				mockDB.EXPECT().Edges(ctx).Return(overviewTestGraph().Edges, nil)
+			}
+			if test.Limit != 0 {
+				defer func(limit int) { ViewportNodeLimit = limit }(ViewportNodeLimit)
+				ViewportNodeLimit = test.Limit
+			}
+			c := NewController(mockDB, l)
+			g, err := c.GraphViewport(ctx, test.Rect, test.Zoom)
+			assert := assert.New(t)
+			if test.ExpectErr {
+				assert.Error(err)
+				assert.Nil(g)
+				return
+			}
+			if !assert.NoError(err) || !assert.NotNil(g) {
+				return
+			}
+			nodes, edges := []string{}, []string{}
+			for _, node := range g.Nodes {
+				nodes = append(nodes, node.ID)
+			}
+			for _, edge := range g.Edges {
+				edges = append(edges, edge.ID)
+			}
+			assert.Equal(test.ExpectNodes, nodes)
+			assert.Equal(test.ExpectEdges, edges)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"github.com/suxatcode/learn-graph-poc-backend/layout"
+)
+
+// implements Layouter
+// StaticLayouter recomputes the complete layout on every Reload with a
+// deterministic (2D) layout algorithm, GetNodePositions only assigns the
+// result.
+type StaticLayouter struct {
+	name    string
+	compute func(ctx context.Context, nodes []*layout.Node, lookup map[string]int, g *model.Graph) (layout.Stats, error)
+
+	lock      sync.RWMutex
+	positions map[string]model.Vector
+	// edges holds the IDs of the edges of the last layout
+	edges     map[string]bool
+	computing bool
+}
+
+// NewHierarchicalLayouter places prerequisites above the topics requiring
+// them, and topics above their sub-topics. Related topics do not affect the
+// layout.
+func NewHierarchicalLayouter(conf layout.LayeredConfig) *StaticLayouter {
+	return &StaticLayouter{
+		name: "hierarchical",
+		compute: func(ctx context.Context, nodes []*layout.Node, lookup map[string]int, g *model.Graph) (layout.Stats, error) {
+			edges := []*layout.Edge{}
+			for _, edge := range g.Edges {
+				from, to := edge.From, edge.To
+				switch edge.Type {
+				case model.EdgeTypePrerequisite:
+				case model.EdgeTypePartOf:
+					from, to = to, from
+				default:
+					continue
+				}
+				edges = appendLayoutEdge(edges, lookup, from, to)
+			}
+			return layout.Layered(ctx, nodes, edges, conf), nil
+		},
+	}
+}
+
+// NewRadialLayouter places nodes on rings around the node with ID root, or
+// around the node with the most edges if root is not part of the graph.
+func NewRadialLayouter(root string, conf layout.RadialConfig) *StaticLayouter {
+	return &StaticLayouter{
+		name: "radial",
+		compute: func(ctx context.Context, nodes []*layout.Node, lookup map[string]int, g *model.Graph) (layout.Stats, error) {
+			rootIndex, ok := lookup[root]
+			if !ok {
+				rootIndex = -1
+			}
+			return layout.Radial(ctx, nodes, allLayoutEdges(lookup, g), rootIndex, conf), nil
+		},
+	}
+}
+
+// NewStressLayouter places nodes such that their distances reflect the
+// graph-theoretic distances.
+func NewStressLayouter(conf layout.StressConfig) *StaticLayouter {
+	l := &StaticLayouter{name: "stress"}
+	l.compute = func(ctx context.Context, nodes []*layout.Node, lookup map[string]int, g *model.Graph) (layout.Stats, error) {
+		// start from the previous layout to keep orientation stable
+		l.lock.RLock()
+		for id, i := range lookup {
+			if pos, ok := l.positions[id]; ok {
+				nodes[i].Pos = []float64{pos.X, pos.Y}
+			}
+		}
+		l.lock.RUnlock()
+		return layout.Stress(ctx, nodes, allLayoutEdges(lookup, g), conf)
+	}
+	return l
+}
+
+func allLayoutEdges(lookup map[string]int, g *model.Graph) []*layout.Edge {
+	edges := []*layout.Edge{}
+	for _, edge := range g.Edges {
+		edges = appendLayoutEdge(edges, lookup, edge.From, edge.To)
+	}
+	return edges
+}
+
+func appendLayoutEdge(edges []*layout.Edge, lookup map[string]int, from, to string) []*layout.Edge {
+	source, okSource := lookup[from]
+	target, okTarget := lookup[to]
+	if !okSource || !okTarget {
+		return edges
+	}
+	return append(edges, &layout.Edge{Source: source, Target: target})
+}
+
+func (l *StaticLayouter) GetNodePositions(ctx context.Context, g *model.Graph) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	missing := []*model.Node{}
+	for _, node := range g.Nodes {
+		if pos, ok := l.positions[node.ID]; ok {
+			node.Position = &model.Vector{X: pos.X, Y: pos.Y, Z: pos.Z}
+		} else {
+			missing = append(missing, node)
+		}
+	}
+	// nodes added since the last Reload are placed next to their neighbors
+	placeNearNeighbors(g, missing)
+	complete := l.positions != nil && len(missing) == 0
+	for _, edge := range g.Edges {
+		complete = complete && l.edges[edge.ID]
+	}
+	g.LayoutStatus = layoutStatus(l.computing, complete)
+}
+
+func (l *StaticLayouter) NodePositions(ctx context.Context) map[string]model.Vector {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	positions := make(map[string]model.Vector, len(l.positions))
+	for id, pos := range l.positions {
+		positions[id] = pos
+	}
+	return positions
+}
+
+func (l *StaticLayouter) Reload(ctx context.Context, g *model.Graph) layout.Stats {
+	l.setComputing(true)
+	defer l.setComputing(false)
+	nodes := make([]*layout.Node, len(g.Nodes))
+	lookup := make(map[string]int, len(g.Nodes))
+	for i, node := range g.Nodes {
+		nodes[i] = &layout.Node{}
+		lookup[node.ID] = i
+	}
+	stats, err := l.compute(ctx, nodes, lookup, g)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("%s layout failed, keeping previous positions: %v", l.name, err)
+		return stats
+	}
+	if ctx.Err() != nil {
+		// the algorithms stop early and return a partial layout
+		log.Ctx(ctx).Error().Msgf("%s layout cancelled, keeping previous positions: %v", l.name, ctx.Err())
+		return stats
+	}
+	stats.Quality = layout.MeasureQuality(nodes, allLayoutEdges(lookup, g), nil)
+	positions := make(map[string]model.Vector, len(g.Nodes))
+	for i, node := range g.Nodes {
+		pos := nodes[i].Pos
+		if len(pos) < 2 {
+			continue
+		}
+		positions[node.ID] = model.Vector{X: pos.X(), Y: pos.Y(), Z: pos.Z()}
+	}
+	edges := make(map[string]bool, len(g.Edges))
+	for _, edge := range g.Edges {
+		edges[edge.ID] = true
+	}
+	l.lock.Lock()
+	l.positions, l.edges = positions, edges
+	l.lock.Unlock()
+	return stats
+}
+
+func (l *StaticLayouter) setComputing(computing bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.computing = computing
+}
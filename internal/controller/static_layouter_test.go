@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"github.com/suxatcode/learn-graph-poc-backend/layout"
+)
+
+func TestNewLayouter(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Algorithm string
+		Expected  interface{}
+		ExpErr    bool
+	}{
+		{Name: "default", Algorithm: "", Expected: &ForceSimulationLayouter{}},
+		{Name: "force", Algorithm: LayoutAlgorithmForce, Expected: &ForceSimulationLayouter{}},
+		{Name: "hierarchical", Algorithm: LayoutAlgorithmHierarchical, Expected: &StaticLayouter{}},
+		{Name: "radial", Algorithm: LayoutAlgorithmRadial, Expected: &StaticLayouter{}},
+		{Name: "stress", Algorithm: LayoutAlgorithmStress, Expected: &StaticLayouter{}},
+		{Name: "unknown", Algorithm: "spiral", ExpErr: true},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			conf := DefaultLayouterConfig()
+			conf.Algorithm = test.Algorithm
+			l, err := NewLayouter(nil, conf)
+			assert := assert.New(t)
+			if test.ExpErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.IsType(test.Expected, l)
+		})
+	}
+}
+
+func TestHierarchicalLayouter(t *testing.T) {
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "basics"}, {ID: "advanced"}, {ID: "subtopic"}, {ID: "related"}},
+		Edges: []*model.Edge{
+			{ID: "1", From: "basics", To: "advanced", Type: model.EdgeTypePrerequisite},
+			{ID: "2", From: "subtopic", To: "basics", Type: model.EdgeTypePartOf},
+			{ID: "3", From: "related", To: "subtopic", Type: model.EdgeTypeRelatedTo},
+		},
+	}
+	l := NewHierarchicalLayouter(layout.DefaultLayeredConfig)
+	l.Reload(context.Background(), g)
+	l.GetNodePositions(context.Background(), g)
+	assert := assert.New(t)
+	for _, node := range g.Nodes {
+		assert.NotNil(node.Position, node.ID)
+	}
+	assert.Greater(g.Nodes[0].Position.Y, g.Nodes[1].Position.Y, "prerequisite above")
+	assert.Greater(g.Nodes[0].Position.Y, g.Nodes[2].Position.Y, "topic above sub-topic")
+}
+
+func TestRadialLayouter(t *testing.T) {
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		Edges: []*model.Edge{{ID: "1", From: "1", To: "2"}, {ID: "2", From: "2", To: "3"}},
+	}
+	l := NewRadialLayouter("3", layout.RadialConfig{RingSpacing: 10})
+	l.Reload(context.Background(), g)
+	l.GetNodePositions(context.Background(), g)
+	assert := assert.New(t)
+	assert.Equal(&model.Vector{}, g.Nodes[2].Position)
+	assert.InDelta(20.0, math.Hypot(g.Nodes[0].Position.X, g.Nodes[0].Position.Y), 1e-9, "two rings from root")
+}
+
+func TestStressLayouter(t *testing.T) {
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
+		Edges: []*model.Edge{{ID: "1", From: "1", To: "2"}},
+	}
+	l := NewStressLayouter(layout.DefaultStressConfig)
+	stats := l.Reload(context.Background(), g)
+	l.GetNodePositions(context.Background(), g)
+	assert := assert.New(t)
+	assert.Greater(stats.Iterations, 0)
+	assert.NotNil(g.Nodes[0].Position)
+	assert.NotEqual(g.Nodes[0].Position, g.Nodes[1].Position)
+}
+
+func TestStaticLayouter_Reload_cancelledKeepsPreviousPositions(t *testing.T) {
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
+		Edges: []*model.Edge{{ID: "1", From: "1", To: "2"}},
+	}
+	l := NewHierarchicalLayouter(layout.DefaultLayeredConfig)
+	l.positions = map[string]model.Vector{"1": {X: 1, Y: 2}, "2": {X: 3, Y: 4}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Reload(ctx, g)
+	assert.Equal(t, map[string]model.Vector{"1": {X: 1, Y: 2}, "2": {X: 3, Y: 4}}, l.positions)
+}
+
+func TestStaticLayouter_GetNodePositions_newNode(t *testing.T) {
+	l := NewHierarchicalLayouter(layout.DefaultLayeredConfig)
+	l.positions = map[string]model.Vector{"1": {X: 10, Y: 20}, "2": {X: 30, Y: 40}}
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}, {ID: "new"}, {ID: "isolated"}},
+		Edges: []*model.Edge{{ID: "1", From: "new", To: "1"}, {ID: "2", From: "2", To: "new"}},
+	}
+	l.GetNodePositions(context.Background(), g)
+	assert := assert.New(t)
+	assert.Equal(&model.Vector{X: 10, Y: 20}, g.Nodes[0].Position)
+	assert.Equal(&model.Vector{X: 20, Y: 30}, g.Nodes[2].Position, "centroid of neighbors")
+	assert.Nil(g.Nodes[3].Position)
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus)
+}
+
+func TestStaticLayouter_NodePositions(t *testing.T) {
+	l := NewHierarchicalLayouter(layout.DefaultLayeredConfig)
+	l.positions = map[string]model.Vector{"1": {X: 10, Y: 20}}
+	positions := l.NodePositions(context.Background())
+	assert.Equal(t, map[string]model.Vector{"1": {X: 10, Y: 20}}, positions)
+	positions["2"] = model.Vector{}
+	assert.Len(t, l.positions, 1, "a copy")
+}
+
+func TestStaticLayouter_GetNodePositions_layoutStatus(t *testing.T) {
+	l := NewRadialLayouter("", layout.DefaultRadialConfig)
+	g := &model.Graph{
+		Nodes: []*model.Node{{ID: "1"}, {ID: "2"}},
+		Edges: []*model.Edge{{ID: "3", From: "1", To: "2"}},
+	}
+	l.GetNodePositions(context.Background(), g)
+	assert := assert.New(t)
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus, "no layout yet")
+	l.Reload(context.Background(), g)
+	l.GetNodePositions(context.Background(), g)
+	assert.Equal(model.LayoutStatusFresh, *g.LayoutStatus)
+	g.Edges = append(g.Edges, &model.Edge{ID: "4", From: "2", To: "1"})
+	l.GetNodePositions(context.Background(), g)
+	assert.Equal(model.LayoutStatusStale, *g.LayoutStatus, "new edge")
+}
@@ -0,0 +1,168 @@
+package layoutbench
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+// NamedGraph generates a fresh copy of a benchmark graph on every call of
+// New, since layouters modify the node positions.
+type NamedGraph struct {
+	Name string
+	New  func() *model.Graph
+}
+
+const (
+	GraphRandom    = "random"
+	GraphScaleFree = "scalefree"
+	GraphTree      = "tree"
+	GraphDAG       = "dag"
+)
+
+var GraphKinds = []string{GraphRandom, GraphScaleFree, GraphTree, GraphDAG}
+
+// Graphs returns a generator for each of the kinds of graphs (see
+// GraphKinds) and sizes, i.e. number of nodes.
+func Graphs(kinds []string, sizes []int, seed int64) ([]NamedGraph, error) {
+	graphs := []NamedGraph{}
+	for _, kind := range kinds {
+		for _, n := range sizes {
+			n := n
+			var generate func() *model.Graph
+			switch kind {
+			case GraphRandom:
+				generate = func() *model.Graph { return Random(n, 2*n, seed) }
+			case GraphScaleFree:
+				generate = func() *model.Graph { return ScaleFree(n, 2, seed) }
+			case GraphTree:
+				generate = func() *model.Graph { return Tree(n, 4, seed) }
+			case GraphDAG:
+				generate = func() *model.Graph { return PrerequisiteDAG(n, seed) }
+			default:
+				return nil, fmt.Errorf("unknown graph kind '%s'", kind)
+			}
+			graphs = append(graphs, NamedGraph{Name: fmt.Sprintf("%s-%d", kind, n), New: generate})
+		}
+	}
+	return graphs, nil
+}
+
+type graphBuilder struct {
+	g     *model.Graph
+	rnd   *rand.Rand
+	edges map[[2]int]bool
+}
+
+func newGraphBuilder(n int, seed int64) *graphBuilder {
+	b := &graphBuilder{g: &model.Graph{}, rnd: rand.New(rand.NewSource(seed)), edges: map[[2]int]bool{}}
+	for i := 0; i < n; i++ {
+		b.g.Nodes = append(b.g.Nodes, &model.Node{ID: strconv.Itoa(i)})
+	}
+	return b
+}
+
+// addEdge adds an edge with a random weight in [1,10], unless it is a
+// self-loop or the nodes are connected already
+func (b *graphBuilder) addEdge(from, to int, edgeType model.EdgeType) bool {
+	if from == to || b.edges[[2]int{from, to}] || b.edges[[2]int{to, from}] {
+		return false
+	}
+	b.edges[[2]int{from, to}] = true
+	b.g.Edges = append(b.g.Edges, &model.Edge{
+		ID:     "e" + strconv.Itoa(len(b.g.Edges)),
+		From:   strconv.Itoa(from),
+		To:     strconv.Itoa(to),
+		Weight: 1 + b.rnd.Float64()*9,
+		Type:   edgeType,
+	})
+	return true
+}
+
+// Random returns a graph with n nodes and m edges between uniformly chosen
+// pairs of nodes.
+func Random(n, m int, seed int64) *model.Graph {
+	b := newGraphBuilder(n, seed)
+	if maxEdges := n * (n - 1) / 2; m > maxEdges {
+		m = maxEdges
+	}
+	for len(b.g.Edges) < m {
+		b.addEdge(b.rnd.Intn(n), b.rnd.Intn(n), model.EdgeTypeRelatedTo)
+	}
+	return b.g
+}
+
+// ScaleFree returns a graph with n nodes grown by preferential attachment
+// (Barabási–Albert): each new node connects to k existing nodes, chosen
+// proportional to their degree.
+func ScaleFree(n, k int, seed int64) *model.Graph {
+	b := newGraphBuilder(n, seed)
+	// every node appears once per incident edge
+	endpoints := []int{}
+	for i := 1; i < n; i++ {
+		for added, tries := 0, 0; added < k && added < i && tries < 10*k; tries++ {
+			target := b.rnd.Intn(i)
+			if len(endpoints) > 0 && b.rnd.Float64() < 0.9 {
+				target = endpoints[b.rnd.Intn(len(endpoints))]
+			}
+			if b.addEdge(i, target, model.EdgeTypeRelatedTo) {
+				endpoints = append(endpoints, i, target)
+				added++
+			}
+		}
+	}
+	return b.g
+}
+
+// Tree returns a random tree with n nodes, where each node has at most
+// branching sub-topics.
+func Tree(n, branching int, seed int64) *model.Graph {
+	b := newGraphBuilder(n, seed)
+	children := make([]int, n)
+	open := []int{0}
+	for i := 1; i < n; i++ {
+		idx := b.rnd.Intn(len(open))
+		parent := open[idx]
+		b.addEdge(i, parent, model.EdgeTypePartOf)
+		children[parent]++
+		if children[parent] == branching {
+			open = append(open[:idx], open[idx+1:]...)
+		}
+		open = append(open, i)
+	}
+	return b.g
+}
+
+// PrerequisiteDAG returns a graph shaped like the learngraph: topics in
+// about sqrt(n) levels, where each topic requires 1-3 topics of the levels
+// before (mostly the previous one), some topics are sub-topics of a topic on
+// the same level, and some are loosely related.
+func PrerequisiteDAG(n int, seed int64) *model.Graph {
+	b := newGraphBuilder(n, seed)
+	levelSize := 1
+	for levelSize*levelSize < n {
+		levelSize++
+	}
+	level := func(i int) int { return i / levelSize }
+	for i := levelSize; i < n; i++ {
+		prerequisites := 1 + b.rnd.Intn(3)
+		for p := 0; p < prerequisites; p++ {
+			from := level(i) - 1
+			if from > 0 && b.rnd.Float64() < 0.2 {
+				from = b.rnd.Intn(from)
+			}
+			b.addEdge(from*levelSize+b.rnd.Intn(levelSize), i, model.EdgeTypePrerequisite)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if i%levelSize != 0 && b.rnd.Float64() < 0.2 {
+			b.addEdge(i, level(i)*levelSize, model.EdgeTypePartOf)
+		}
+		if b.rnd.Float64() < 0.1 {
+			b.addEdge(i, b.rnd.Intn(n), model.EdgeTypeRelatedTo)
+		}
+	}
+	return b.g
+}
@@ -0,0 +1,222 @@
+package layoutbench
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+)
+
+func TestGraphs(t *testing.T) {
+	graphs, err := Graphs(GraphKinds, []int{50, 200}, 1)
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.Len(graphs, 8)
+	for _, graph := range graphs {
+		g := graph.New()
+		assert.Equal(g, graph.New(), "%s is not deterministic", graph.Name)
+		assert.NotEmpty(g.Edges, graph.Name)
+		ids := map[string]bool{}
+		for _, node := range g.Nodes {
+			ids[node.ID] = true
+		}
+		for _, edge := range g.Edges {
+			assert.NotEqual(edge.From, edge.To, graph.Name)
+			assert.True(ids[edge.From] && ids[edge.To], graph.Name)
+			assert.GreaterOrEqual(edge.Weight, 1.0)
+			assert.LessOrEqual(edge.Weight, 10.0)
+		}
+	}
+	assert.Equal(50, len(graphs[0].New().Nodes))
+	assert.Equal(graphs[0].Name, "random-50")
+
+	_, err = Graphs([]string{"unknown"}, []int{10}, 1)
+	assert.Error(err)
+}
+
+func TestGraphs_differentSeeds(t *testing.T) {
+	assert.NotEqual(t, Random(50, 100, 1), Random(50, 100, 2))
+}
+
+func TestTree(t *testing.T) {
+	g := Tree(100, 3, 1)
+	assert := assert.New(t)
+	assert.Len(g.Edges, 99)
+	children := map[string]int{}
+	for _, edge := range g.Edges {
+		assert.Equal(model.EdgeTypePartOf, edge.Type)
+		children[edge.To]++
+	}
+	for id, count := range children {
+		assert.LessOrEqual(count, 3, id)
+	}
+}
+
+func TestPrerequisiteDAG_acyclic(t *testing.T) {
+	g := PrerequisiteDAG(300, 1)
+	requires := map[string][]string{}
+	for _, edge := range g.Edges {
+		if edge.Type == model.EdgeTypePrerequisite {
+			requires[edge.To] = append(requires[edge.To], edge.From)
+		}
+	}
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := map[string]int{}
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		switch state[id] {
+		case inProgress:
+			return false
+		case done:
+			return true
+		}
+		state[id] = inProgress
+		for _, prerequisite := range requires[id] {
+			if !visit(prerequisite) {
+				return false
+			}
+		}
+		state[id] = done
+		return true
+	}
+	for _, node := range g.Nodes {
+		assert.True(t, visit(node.ID), "cycle through %s", node.ID)
+	}
+}
+
+func TestScaleFree_hubs(t *testing.T) {
+	g := ScaleFree(500, 2, 1)
+	degree := map[string]int{}
+	for _, edge := range g.Edges {
+		degree[edge.From]++
+		degree[edge.To]++
+	}
+	maxDegree := 0
+	for _, d := range degree {
+		if d > maxDegree {
+			maxDegree = d
+		}
+	}
+	assert.Greater(t, maxDegree, 20)
+}
+
+func positionedGraph(positions map[string]model.Vector, edges [][2]string) *model.Graph {
+	g := &model.Graph{}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if pos, ok := positions[id]; ok {
+			g.Nodes = append(g.Nodes, &model.Node{ID: id, Position: &model.Vector{X: pos.X, Y: pos.Y}})
+		}
+	}
+	for i, edge := range edges {
+		g.Edges = append(g.Edges, &model.Edge{ID: fmt.Sprint(i), From: edge[0], To: edge[1]})
+	}
+	return g
+}
+
+func TestMeasure(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Positions map[string]model.Vector
+		Edges     [][2]string
+		Exp       Metrics
+	}{
+		{
+			Name:      "path on a line is perfect",
+			Positions: map[string]model.Vector{"a": {X: 0}, "b": {X: 10}, "c": {X: 20}, "d": {X: 30}},
+			Edges:     [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}},
+			Exp:       Metrics{Stress: 0, NeighborhoodPreservation: 1, EdgeCrossings: 0},
+		},
+		{
+			Name:      "crossing square",
+			Positions: map[string]model.Vector{"a": {X: 0, Y: 0}, "b": {X: 10, Y: 10}, "c": {X: 10, Y: 0}, "d": {X: 0, Y: 10}},
+			Edges:     [][2]string{{"a", "b"}, {"c", "d"}},
+			Exp:       Metrics{Stress: 0, NeighborhoodPreservation: 0, EdgeCrossings: 1},
+		},
+		{
+			Name:      "nodes without position are ignored",
+			Positions: map[string]model.Vector{"a": {X: 0}, "b": {X: 10}},
+			Edges:     [][2]string{{"a", "b"}, {"b", "c"}},
+			Exp:       Metrics{Stress: 0, NeighborhoodPreservation: 1, EdgeCrossings: 0},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			metrics := Measure(positionedGraph(test.Positions, test.Edges), 1)
+			assert := assert.New(t)
+			assert.InDelta(test.Exp.Stress, metrics.Stress, 1e-9)
+			assert.InDelta(test.Exp.NeighborhoodPreservation, metrics.NeighborhoodPreservation, 1e-9)
+			assert.Equal(test.Exp.EdgeCrossings, metrics.EdgeCrossings)
+		})
+	}
+}
+
+func TestMeasure_stress(t *testing.T) {
+	// the path a-b-c folded onto itself: c lies on a
+	g := positionedGraph(
+		map[string]model.Vector{"a": {X: 0}, "b": {X: 10}, "c": {X: 0.001}},
+		[][2]string{{"a", "b"}, {"b", "c"}},
+	)
+	assert.Greater(t, Measure(g, 1).Stress, 0.1)
+}
+
+func TestRunAll(t *testing.T) {
+	graphs, err := Graphs([]string{GraphDAG}, []int{30}, 1)
+	assert := assert.New(t)
+	assert.NoError(err)
+	layouters, err := Layouters(LayouterKinds, 1)
+	assert.NoError(err)
+	results := []Result{}
+	RunAll(context.Background(), graphs, layouters, 1, 0, func(r Result) { results = append(results, r) })
+	assert.Len(results, len(LayouterKinds))
+	for _, result := range results {
+		assert.Equal("dag-30", result.Graph)
+		assert.Equal(30, result.Nodes)
+		assert.Greater(result.Allocations, uint64(0), result.Layouter)
+		assert.Greater(result.Iterations, 0, result.Layouter)
+		assert.Greater(result.NeighborhoodPreservation, 0.0, result.Layouter)
+	}
+
+	_, err = Layouters([]string{"unknown"}, 1)
+	assert.Error(err)
+}
+
+func TestRun_deterministicStaticLayouts(t *testing.T) {
+	graphs, _ := Graphs([]string{GraphScaleFree}, []int{40}, 1)
+	layouters, _ := Layouters(LayouterKinds[1:], 1)
+	for _, layouter := range layouters {
+		first := Run(context.Background(), graphs[0], layouter, 1, 0)
+		second := Run(context.Background(), graphs[0], layouter, 1, 0)
+		assert.Equal(t, first.Metrics, second.Metrics, layouter.Name)
+	}
+}
+
+func BenchmarkLayouters(b *testing.B) {
+	const seed = 1
+	graphs, _ := Graphs(GraphKinds, []int{100, 1000}, seed)
+	layouters, _ := Layouters(LayouterKinds, seed)
+	for _, graph := range graphs {
+		for _, layouter := range layouters {
+			b.Run(graph.Name+"/"+layouter.Name, func(b *testing.B) {
+				b.ReportAllocs()
+				var metrics Metrics
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					g, l := graph.New(), layouter.New()
+					b.StartTimer()
+					l.Reload(context.Background(), g)
+					l.GetNodePositions(context.Background(), g)
+					b.StopTimer()
+					metrics = Measure(g, seed)
+				}
+				b.ReportMetric(metrics.Stress, "stress")
+				b.ReportMetric(metrics.NeighborhoodPreservation, "neighborhood")
+				b.ReportMetric(metrics.EdgeCrossings, "crossings")
+			})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package layoutbench
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/suxatcode/learn-graph-poc-backend/internal/controller"
+	"github.com/suxatcode/learn-graph-poc-backend/layout"
+)
+
+// NamedLayouter creates a fresh Layouter on every call of New, so that no
+// state is shared between runs.
+type NamedLayouter struct {
+	Name string
+	New  func() controller.Layouter
+}
+
+var LayouterKinds = []string{
+	controller.LayoutAlgorithmForce,
+	controller.LayoutAlgorithmHierarchical,
+	controller.LayoutAlgorithmRadial,
+	controller.LayoutAlgorithmStress,
+}
+
+// Layouters returns the layouters of the given kinds (see LayouterKinds),
+// configured as in production, but with random numbers drawn from seed.
+func Layouters(kinds []string, seed int64) ([]NamedLayouter, error) {
+	layouters := []NamedLayouter{}
+	for _, kind := range kinds {
+		var create func() controller.Layouter
+		switch kind {
+		case controller.LayoutAlgorithmForce:
+			create = func() controller.Layouter {
+				complete := controller.DefaultCompleteSimulationConfig
+				complete.Seed = seed
+				quick := controller.DefaultQuickSimulationConfig
+				quick.Seed = seed + 1
+				return controller.NewForceSimulationLayouter().WithSimulationConfig(complete, quick)
+			}
+		case controller.LayoutAlgorithmHierarchical:
+			create = func() controller.Layouter {
+				return controller.NewHierarchicalLayouter(layout.DefaultLayeredConfig)
+			}
+		case controller.LayoutAlgorithmRadial:
+			create = func() controller.Layouter {
+				return controller.NewRadialLayouter("", layout.DefaultRadialConfig)
+			}
+		case controller.LayoutAlgorithmStress:
+			create = func() controller.Layouter {
+				conf := layout.DefaultStressConfig
+				conf.RandomFloat = rand.New(rand.NewSource(seed)).Float64
+				return controller.NewStressLayouter(conf)
+			}
+		default:
+			return nil, fmt.Errorf("unknown layouter '%s'", kind)
+		}
+		layouters = append(layouters, NamedLayouter{Name: kind, New: create})
+	}
+	return layouters, nil
+}
@@ -0,0 +1,173 @@
+package layoutbench
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/quartercastle/vector"
+	"github.com/suxatcode/learn-graph-poc-backend/graph/model"
+	"github.com/suxatcode/learn-graph-poc-backend/layout"
+)
+
+// MetricSamples limits the number of nodes used as sources for the stress
+// and neighborhood preservation metrics, which are quadratic otherwise.
+var MetricSamples = 200
+
+// Metrics describe the quality of a layout independent of the algorithm that
+// computed it.
+type Metrics struct {
+	// Stress is the normalized stress of the layout scaled optimally to the
+	// graph-theoretic distances: 0 means distances in the layout are
+	// proportional to shortest path lengths.
+	Stress float64 `json:"stress"`
+	// NeighborhoodPreservation is the mean Jaccard similarity of the graph
+	// neighbors of a node and its nearest nodes in the layout, 1 is best.
+	NeighborhoodPreservation float64 `json:"neighborhoodPreservation"`
+	// EdgeCrossings is the (estimated) number of crossing edges in the x-y
+	// plane, see layout.MeasureQuality.
+	EdgeCrossings float64 `json:"edgeCrossings"`
+}
+
+// Measure computes the Metrics of the positions assigned to g. Nodes without
+// position are ignored.
+func Measure(g *model.Graph, seed int64) Metrics {
+	rnd := rand.New(rand.NewSource(seed))
+	nodes, neighbors := positionedNodes(g)
+	samples := sampleNodes(len(nodes), rnd)
+	return Metrics{
+		Stress:                   normalizedStress(nodes, neighbors, samples),
+		NeighborhoodPreservation: neighborhoodPreservation(nodes, neighbors, samples),
+		EdgeCrossings:            edgeCrossings(nodes, neighbors, rnd),
+	}
+}
+
+func positionedNodes(g *model.Graph) ([]*layout.Node, [][]int) {
+	nodes := []*layout.Node{}
+	lookup := map[string]int{}
+	for _, node := range g.Nodes {
+		if node.Position == nil {
+			continue
+		}
+		lookup[node.ID] = len(nodes)
+		nodes = append(nodes, &layout.Node{Pos: vector.Vector{node.Position.X, node.Position.Y, node.Position.Z}})
+	}
+	neighbors := make([][]int, len(nodes))
+	for _, edge := range g.Edges {
+		from, okFrom := lookup[edge.From]
+		to, okTo := lookup[edge.To]
+		if !okFrom || !okTo || from == to {
+			continue
+		}
+		neighbors[from] = append(neighbors[from], to)
+		neighbors[to] = append(neighbors[to], from)
+	}
+	return nodes, neighbors
+}
+
+func sampleNodes(n int, rnd *rand.Rand) []int {
+	samples := rnd.Perm(n)
+	if len(samples) > MetricSamples {
+		samples = samples[:MetricSamples]
+	}
+	return samples
+}
+
+func shortestPaths(neighbors [][]int, source int) []int {
+	dist := make([]int, len(neighbors))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[source] = 0
+	queue := []int{source}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, u := range neighbors[v] {
+			if dist[u] < 0 {
+				dist[u] = dist[v] + 1
+				queue = append(queue, u)
+			}
+		}
+	}
+	return dist
+}
+
+func normalizedStress(nodes []*layout.Node, neighbors [][]int, samples []int) float64 {
+	// stress(s) = Σ (s·x - d)²/d² is minimal for s = Σ x/d / Σ x²/d²
+	type pair struct{ x, d float64 }
+	pairs := []pair{}
+	for _, source := range samples {
+		for target, d := range shortestPaths(neighbors, source) {
+			if d <= 0 {
+				continue
+			}
+			x := nodes[source].Pos.Sub(nodes[target].Pos).Magnitude()
+			pairs = append(pairs, pair{x: x, d: float64(d)})
+		}
+	}
+	num, denom := 0.0, 0.0
+	for _, p := range pairs {
+		num += p.x / p.d
+		denom += p.x * p.x / (p.d * p.d)
+	}
+	if len(pairs) == 0 || denom == 0 {
+		return 0
+	}
+	scale := num / denom
+	stress := 0.0
+	for _, p := range pairs {
+		diff := (scale*p.x - p.d) / p.d
+		stress += diff * diff
+	}
+	return stress / float64(len(pairs))
+}
+
+func neighborhoodPreservation(nodes []*layout.Node, neighbors [][]int, samples []int) float64 {
+	sum, count := 0.0, 0
+	others := make([]int, 0, len(nodes))
+	for _, v := range samples {
+		graphNeighbors := map[int]bool{}
+		for _, u := range neighbors[v] {
+			graphNeighbors[u] = true
+		}
+		k := len(graphNeighbors)
+		if k == 0 {
+			continue
+		}
+		others = others[:0]
+		for u := range nodes {
+			if u != v {
+				others = append(others, u)
+			}
+		}
+		distance := func(u int) float64 { return nodes[v].Pos.Sub(nodes[u].Pos).Magnitude() }
+		sort.SliceStable(others, func(i, j int) bool { return distance(others[i]) < distance(others[j]) })
+		shared := 0
+		for _, u := range others[:k] {
+			if graphNeighbors[u] {
+				shared++
+			}
+		}
+		// both sets have k elements
+		sum += float64(shared) / float64(2*k-shared)
+		count++
+	}
+	if count == 0 {
+		return 1
+	}
+	return sum / float64(count)
+}
+
+func edgeCrossings(nodes []*layout.Node, neighbors [][]int, rnd *rand.Rand) float64 {
+	edges := []*layout.Edge{}
+	for v := range neighbors {
+		for _, u := range neighbors[v] {
+			if v < u {
+				edges = append(edges, &layout.Edge{Source: v, Target: u})
+			}
+		}
+	}
+	crossings := layout.MeasureQuality(nodes, edges, rnd.Float64).EdgeCrossings
+	return math.Round(crossings)
+}
@@ -0,0 +1,78 @@
+// Package layoutbench compares the layout algorithms on generated graphs, so
+// that changes to the layouts can be checked for regressions in runtime and
+// quality.
+package layoutbench
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// Result of a single layouter on a single graph, encoded as one JSON line by
+// cmd/layout-bench.
+type Result struct {
+	Graph          string  `json:"graph"`
+	Layouter       string  `json:"layouter"`
+	Seed           int64   `json:"seed"`
+	Nodes          int     `json:"nodes"`
+	Edges          int     `json:"edges"`
+	RuntimeMs      float64 `json:"runtimeMs"`
+	Allocations    uint64  `json:"allocations"`
+	AllocatedBytes uint64  `json:"allocatedBytes"`
+	Iterations     int     `json:"iterations"`
+	Converged      bool    `json:"converged"`
+	TimedOut       bool    `json:"timedOut"`
+	Metrics
+}
+
+// Run computes the layout of graph with layouter, and measures runtime,
+// allocations and quality of the result. timeout limits the layout
+// computation, 0 means no limit.
+func Run(ctx context.Context, graph NamedGraph, layouter NamedLayouter, seed int64, timeout time.Duration) Result {
+	g := graph.New()
+	l := layouter.New()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	runtime.GC()
+	before := runtime.MemStats{}
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	stats := l.Reload(ctx, g)
+	l.GetNodePositions(ctx, g)
+	elapsed := time.Since(start)
+	after := runtime.MemStats{}
+	runtime.ReadMemStats(&after)
+
+	return Result{
+		Graph:          graph.Name,
+		Layouter:       layouter.Name,
+		Seed:           seed,
+		Nodes:          len(g.Nodes),
+		Edges:          len(g.Edges),
+		RuntimeMs:      float64(elapsed.Microseconds()) / 1000,
+		Allocations:    after.Mallocs - before.Mallocs,
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+		Iterations:     stats.Iterations,
+		Converged:      stats.Converged,
+		TimedOut:       stats.TimedOut || ctx.Err() != nil,
+		Metrics:        Measure(g, seed),
+	}
+}
+
+// RunAll runs every layouter on every graph and passes each result to
+// report as soon as it is available.
+func RunAll(ctx context.Context, graphs []NamedGraph, layouters []NamedLayouter, seed int64, timeout time.Duration, report func(Result)) {
+	for _, graph := range graphs {
+		for _, layouter := range layouters {
+			if ctx.Err() != nil {
+				return
+			}
+			report(Run(ctx, graph, layouter, seed, timeout))
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package layout
+
+import "sort"
+
+// Communities detects clusters of densely connected nodes with the Louvain
+// method, i.e. by greedily maximizing the modularity of the partition. Edges
+// are undirected and weighted by Edge.Value (1 if unset), resolution scales
+// the expected number of edges between clusters: higher values yield more,
+// smaller clusters (1 if ≤ 0).
+//
+// The result maps each of the n nodes to a cluster ID in [0, clusters),
+// numbered in the order of the nodes. It is deterministic for the same input.
+func Communities(n int, edges []*Edge, resolution float64) []int {
+	if resolution <= 0 {
+		resolution = 1
+	}
+	b := newWeightedGraphBuilder(n)
+	for _, edge := range edges {
+		if edge.Source == edge.Target || edge.Source < 0 || edge.Target < 0 || edge.Source >= n || edge.Target >= n {
+			continue
+		}
+		weight := edge.Value
+		if weight <= 0 {
+			weight = 1
+		}
+		b.add(edge.Source, edge.Target, weight)
+		b.add(edge.Target, edge.Source, weight)
+	}
+	g := b.build()
+	community := make([]int, n)
+	for i := range community {
+		community[i] = i
+	}
+	for {
+		assignment, moved := g.moveNodes(resolution)
+		if !moved {
+			break
+		}
+		clusters := relabel(assignment)
+		for i := range community {
+			community[i] = assignment[community[i]]
+		}
+		if clusters == len(g.neighbors) {
+			break
+		}
+		g = g.aggregate(assignment, clusters)
+	}
+	relabel(community)
+	return community
+}
+
+// weightedGraph is a symmetric weighted adjacency, self-loops of aggregated
+// clusters count twice the internal weight. Neighbors are sorted, so that
+// sums of weights do not depend on map iteration order.
+type weightedGraph struct {
+	neighbors [][]weightedNeighbor
+	// degree is the sum of the weights of all edges of a node
+	degree []float64
+	total  float64
+}
+
+type weightedNeighbor struct {
+	node   int
+	weight float64
+}
+
+// weightedGraphBuilder merges parallel edges
+type weightedGraphBuilder []map[int]float64
+
+func newWeightedGraphBuilder(n int) weightedGraphBuilder {
+	b := make(weightedGraphBuilder, n)
+	for i := range b {
+		b[i] = map[int]float64{}
+	}
+	return b
+}
+
+func (b weightedGraphBuilder) add(from, to int, weight float64) {
+	b[from][to] += weight
+}
+
+func (b weightedGraphBuilder) build() *weightedGraph {
+	g := &weightedGraph{neighbors: make([][]weightedNeighbor, len(b)), degree: make([]float64, len(b))}
+	for i, weights := range b {
+		for j, weight := range weights {
+			g.neighbors[i] = append(g.neighbors[i], weightedNeighbor{node: j, weight: weight})
+		}
+		sort.Slice(g.neighbors[i], func(a, c int) bool { return g.neighbors[i][a].node < g.neighbors[i][c].node })
+		for _, neighbor := range g.neighbors[i] {
+			g.degree[i] += neighbor.weight
+		}
+		g.total += g.degree[i]
+	}
+	return g
+}
+
+// moveNodes moves each node to the neighboring community with the highest
+// modularity gain until no node moves anymore, returns node → community and
+// whether any node moved
+func (g *weightedGraph) moveNodes(resolution float64) ([]int, bool) {
+	n := len(g.neighbors)
+	community := make([]int, n)
+	communityDegree := make([]float64, n)
+	for i := range community {
+		community[i] = i
+		communityDegree[i] = g.degree[i]
+	}
+	if g.total == 0 {
+		return community, false
+	}
+	moved := false
+	weightTo := make([]float64, n)
+	touched := []int{}
+	for pass := 0; pass < 100; pass++ {
+		improved := false
+		for i := 0; i < n; i++ {
+			touched = touched[:0]
+			for _, neighbor := range g.neighbors[i] {
+				if neighbor.node == i {
+					continue
+				}
+				c := community[neighbor.node]
+				if weightTo[c] == 0 {
+					touched = append(touched, c)
+				}
+				weightTo[c] += neighbor.weight
+			}
+			sort.Ints(touched)
+			current := community[i]
+			communityDegree[current] -= g.degree[i]
+			best := current
+			bestGain := weightTo[current] - resolution*g.degree[i]*communityDegree[current]/g.total
+			for _, c := range touched {
+				gain := weightTo[c] - resolution*g.degree[i]*communityDegree[c]/g.total
+				if gain > bestGain+1e-12 {
+					best, bestGain = c, gain
+				}
+			}
+			communityDegree[best] += g.degree[i]
+			community[i] = best
+			for _, c := range touched {
+				weightTo[c] = 0
+			}
+			if best != current {
+				improved, moved = true, true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return community, moved
+}
+
+// aggregate returns the graph of communities, where community[i] ∈ [0, n)
+func (g *weightedGraph) aggregate(community []int, n int) *weightedGraph {
+	b := newWeightedGraphBuilder(n)
+	for i, neighbors := range g.neighbors {
+		for _, neighbor := range neighbors {
+			b.add(community[i], community[neighbor.node], neighbor.weight)
+		}
+	}
+	return b.build()
+}
+
+// relabel renumbers the values of ids to [0, n) in order of their first
+// occurrence and returns n
+func relabel(ids []int) int {
+	labels := map[int]int{}
+	for i, id := range ids {
+		label, ok := labels[id]
+		if !ok {
+			label = len(labels)
+			labels[id] = label
+		}
+		ids[i] = label
+	}
+	return len(labels)
+}
@@ -0,0 +1,90 @@
+package layout
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunities(t *testing.T) {
+	// two triangles connected by a single edge
+	twoTriangles := []*Edge{
+		{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 2, Target: 0},
+		{Source: 3, Target: 4}, {Source: 4, Target: 5}, {Source: 5, Target: 3},
+		{Source: 2, Target: 3},
+	}
+	for _, test := range []struct {
+		Name  string
+		N     int
+		Edges []*Edge
+		Exp   []int
+	}{
+		{Name: "empty graph", N: 0, Edges: []*Edge{}, Exp: []int{}},
+		{Name: "isolated nodes", N: 3, Edges: []*Edge{}, Exp: []int{0, 1, 2}},
+		{Name: "two triangles", N: 6, Edges: twoTriangles, Exp: []int{0, 0, 0, 1, 1, 1}},
+		{
+			Name: "heavy bridge pulls its nodes together",
+			N:    6,
+			Edges: append(append([]*Edge{}, twoTriangles[:6]...),
+				&Edge{Source: 2, Target: 3, Value: 100},
+			),
+			Exp: []int{0, 0, 1, 1, 2, 2},
+		},
+		{
+			Name:  "self-loops and invalid edges are ignored",
+			N:     2,
+			Edges: []*Edge{{Source: 0, Target: 0}, {Source: 0, Target: 7}},
+			Exp:   []int{0, 1},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Exp, Communities(test.N, test.Edges, 1))
+		})
+	}
+}
+
+func TestCommunities_plantedPartition(t *testing.T) {
+	// 4 groups of 25 nodes, dense inside, sparse between groups
+	rnd := rand.New(rand.NewSource(1))
+	edges := []*Edge{}
+	for i := 0; i < 100; i++ {
+		for j := i + 1; j < 100; j++ {
+			p := 0.005
+			if i/25 == j/25 {
+				p = 0.5
+			}
+			if rnd.Float64() < p {
+				edges = append(edges, &Edge{Source: i, Target: j})
+			}
+		}
+	}
+	communities := Communities(100, edges, 1)
+	assert := assert.New(t)
+	assert.Equal(communities, Communities(100, edges, 1), "deterministic")
+	for i := 0; i < 100; i++ {
+		assert.Equal(communities[i/25*25], communities[i], "node %d", i)
+		if i >= 25 {
+			assert.NotEqual(communities[i-25], communities[i], "node %d", i)
+		}
+	}
+	assert.Equal(0, communities[0], "numbered in order of the nodes")
+}
+
+func TestCommunities_resolution(t *testing.T) {
+	// a ring of 12 nodes splits into more clusters with a higher resolution
+	edges := []*Edge{}
+	for i := 0; i < 12; i++ {
+		edges = append(edges, &Edge{Source: i, Target: (i + 1) % 12})
+	}
+	count := func(communities []int) int {
+		max := 0
+		for _, c := range communities {
+			if c > max {
+				max = c
+			}
+		}
+		return max + 1
+	}
+	assert.Less(t, count(Communities(12, edges, 0.1)), count(Communities(12, edges, 5)))
+}
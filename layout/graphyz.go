@@ -18,25 +18,43 @@ type Graph struct {
 	Nodes           []*Node `json:"nodes"`
 	Edges           []*Edge `json:"edges"`
 	forceSimulation *ForceSimulation
+	// kinetic energy and displacement of all nodes in the last tick
+	energy, maxDisplacement, totalDisplacement float64
 }
 
 type Node struct {
 	Name     string `json:"name"`
 	degree   float64
 	IsPinned bool
-	radius   float64
-	Pos      vector.Vector `json:"pos,omitempty"`
-	vel      vector.Vector
-	acc      vector.Vector
+	// Mobility scales the movement of the node per tick, e.g. 0.1 lets a
+	// node move a tenth of the distance. Zero means full mobility (1.0).
+	Mobility float64
+	// MaxDisplacement limits the distance a node moves away from its initial
+	// position during a single simulation. Zero means unlimited.
+	MaxDisplacement float64
+	// Cluster of the node, e.g. from Communities, for the cluster forces of
+	// the simulation. Negative values mean the node belongs to no cluster.
+	Cluster int `json:"cluster,omitempty"`
+	radius  float64
+	Pos     vector.Vector `json:"pos,omitempty"`
+	vel     vector.Vector
+	acc     vector.Vector
+	start   vector.Vector
 }
 
 type Edge struct {
-	Source int     `json:"source"`
-	Target int     `json:"target"`
-	Value  float64 `json:"value"`
+	Source int `json:"source"`
+	Target int `json:"target"`
+	// Value scales the attraction between Source and Target, zero means 1.0
+	Value float64 `json:"value"`
+	// Length is the ideal distance between Source and Target. Zero means
+	// the nodes are pulled together until they touch.
+	Length float64 `json:"length,omitempty"`
+	// Directed edges push Source above Target (towards positive y), see
+	// ForceSimulationConfig.DirectionalStrength
+	Directed bool `json:"directed,omitempty"`
 }
 
-// TODO(skep):  3D: point on sphere
 func pointOnCircle(i, TotalPoints, Radius int, center vector.Vector) vector.Vector {
 	return vector.Vector{
 		math.Sin(float64(i) * 2.0 * math.Pi / float64(TotalPoints)),
@@ -44,6 +62,22 @@ func pointOnCircle(i, TotalPoints, Radius int, center vector.Vector) vector.Vect
 	}.Scale(float64(Radius)).Add(center)
 }
 
+// pointOnSphere returns the i-th of TotalPoints points evenly spread on a
+// sphere, see https://en.wikipedia.org/wiki/Fibonacci_sphere
+func pointOnSphere(i, TotalPoints, Radius int, center vector.Vector) vector.Vector {
+	y := 1.0
+	if TotalPoints > 1 {
+		y = 1.0 - 2.0*float64(i)/float64(TotalPoints-1)
+	}
+	r := math.Sqrt(1 - y*y)
+	angle := float64(i) * math.Pi * (3 - math.Sqrt(5)) // golden angle
+	return vector.Vector{
+		r * math.Cos(angle),
+		y,
+		r * math.Sin(angle),
+	}.Scale(float64(Radius)).Add(center)
+}
+
 func min[T constraints.Ordered](a, b T) T {
 	if a < b {
 		return a
@@ -65,29 +99,29 @@ func NewGraph(nodes []*Node, edges []*Edge, forceSimulation *ForceSimulation) *G
 		graph.Nodes[edge.Target].degree += edge.Value
 	}
 	for i, node := range graph.Nodes {
-		if node.Pos.Magnitude() == 0 {
-			if forceSimulation.conf.InitialLayout == InitialLayoutRandom {
-				node.Pos = forceSimulation.conf.RandomVectorInside()
-			} else if forceSimulation.conf.InitialLayout == InitialLayoutRandom {
-				node.Pos = pointOnCircle(
-					i, len(graph.Nodes),
-					int(math.Floor(min(forceSimulation.conf.Rect.X, forceSimulation.conf.Rect.Y))),
-					forceSimulation.conf.Rect.Center(),
-				)
-			}
+		if node.Pos.Magnitude() == 0 && !node.IsPinned {
+			node.Pos = forceSimulation.conf.initialPosition(i, len(graph.Nodes))
+		}
+		if missing := forceSimulation.conf.dimensions() - len(node.Pos); missing > 0 {
+			// e.g. a 2D position in a 3D simulation
+			node.Pos = append(append(vector.Vector{}, node.Pos...), make(vector.Vector, missing)...)
 		}
 		if node.radius == 0 {
 			node.radius = forceSimulation.conf.DefaultNodeRadius
 		}
 		if len(node.acc) == 0 {
-			node.acc = vector.Vector{0, 0}
+			node.acc = forceSimulation.conf.zero()
 		}
 		if len(node.vel) == 0 {
-			node.vel = vector.Vector{0, 0}
+			node.vel = forceSimulation.conf.zero()
 		}
 		if node.degree == 0.0 {
 			node.degree = 1.0 // default degree != 0 is necessary for node<>node repulsion
 		}
+		if node.Mobility == 0.0 {
+			node.Mobility = 1.0
+		}
+		node.start = append(vector.Vector{}, node.Pos...)
 	}
 	return &graph
 }
@@ -107,16 +141,22 @@ func (g *Graph) resetPosition() {
 	}
 }
 
-func (g *Graph) ApplyForce(deltaTime float64, qt *QuadTree) {
+func (g *Graph) ApplyForce(deltaTime float64, tree BarnesHutTree) {
 	g.resetAcceleration()
 	if g.forceSimulation.conf.Gravity {
 		g.gravityToCenterForce()
 	}
 
 	g.attractionByEdgesForce()
+	if g.forceSimulation.conf.DirectionalStrength > 0 {
+		g.directionalForce()
+	}
+	if g.forceSimulation.conf.ClusterAttraction > 0 || g.forceSimulation.conf.ClusterRepulsion > 0 {
+		g.clusterForce()
+	}
 
-	if config.BarnesHut {
-		g.repulsionBarnesHut(qt)
+	if !g.forceSimulation.conf.DisableBarnesHut {
+		g.repulsionBarnesHut(tree)
 	} else {
 		g.repulsionNaive()
 	}
@@ -137,39 +177,70 @@ func clamp(in, lo, hi float64) float64 {
 }
 
 func VectorClampValue(v vector.Vector, min, max float64) vector.Vector {
-	return vector.Vector{
-		clamp(v.X(), min, max),
-		clamp(v.Y(), min, max),
+	r := make(vector.Vector, len(v))
+	for i := range v {
+		r[i] = clamp(v[i], min, max)
 	}
+	return r
 }
 func VectorClampVector(v, min, max vector.Vector) vector.Vector {
-	return vector.Vector{
-		clamp(v.X(), min.X(), max.X()),
-		clamp(v.Y(), min.Y(), max.Y()),
+	r := make(vector.Vector, len(v))
+	for i := range v {
+		r[i] = clamp(v[i], min[i], max[i])
 	}
+	return r
 }
 
 func (g *Graph) updatePositions(deltaTime float64) {
 	outOfBoundsFactor := g.forceSimulation.conf.ScreenMultiplierToClampPosition
 	// TODO: should use g.forceSimulation.conf.Rect here instead of global config
-	w, h := g.forceSimulation.conf.Rect.Width, g.forceSimulation.conf.Rect.Height
-	boundsMin := vector.Vector{-outOfBoundsFactor * float64(w), -outOfBoundsFactor * float64(h)}
-	boundsMax := vector.Vector{outOfBoundsFactor * float64(w), outOfBoundsFactor * float64(h)}
+	w, h, d := g.forceSimulation.conf.Rect.Width, g.forceSimulation.conf.Rect.Height, g.forceSimulation.conf.Rect.Depth
+	boundsMin := vector.Vector{-outOfBoundsFactor * w, -outOfBoundsFactor * h, -outOfBoundsFactor * d}
+	boundsMax := vector.Vector{outOfBoundsFactor * w, outOfBoundsFactor * h, outOfBoundsFactor * d}
+	g.energy, g.maxDisplacement, g.totalDisplacement = 0, 0, 0
 	for _, node := range g.Nodes {
 		if node.IsPinned {
 			continue
 		}
 		vector.In(node.vel).Add(node.acc)
-		vector.In(node.vel).Scale(1 - config.VelocityDecay)
+		vector.In(node.vel).Scale(1 - g.forceSimulation.conf.VelocityDecay)
 		node.vel = VectorClampValue(node.vel, -100, 100)
-		vector.In(node.Pos).Add(node.vel.Scale(deltaTime))
+		step := node.vel.Scale(deltaTime * node.Mobility)
+		vector.In(node.Pos).Add(step)
+		displacement := step.Magnitude()
+		g.energy += 0.5 * node.vel.Dot(node.vel)
+		g.totalDisplacement += displacement
+		g.maxDisplacement = math.Max(g.maxDisplacement, displacement)
 		node.Pos = VectorClampVector(node.Pos, boundsMin, boundsMax)
+		if node.MaxDisplacement > 0 {
+			displacement := node.Pos.Sub(node.start)
+			if displacement.Magnitude() > node.MaxDisplacement {
+				node.Pos = node.start.Add(displacement.Unit().Scale(node.MaxDisplacement))
+			}
+		}
+	}
+}
+
+// jitterCoincidentNodes moves nodes sharing the exact same position slightly
+// apart, since there is no direction for the repulsion between them. It runs
+// once before the simulation, nodes coinciding later on are bucketed by the
+// MaxDepth of the Barnes-Hut tree.
+func (g *Graph) jitterCoincidentNodes() {
+	seen := make(map[[3]float64]bool, len(g.Nodes))
+	for _, node := range g.Nodes {
+		key := [3]float64{node.Pos.X(), node.Pos.Y(), node.Pos.Z()}
+		if seen[key] && !node.IsPinned {
+			offset := g.forceSimulation.randomDirection().Scale(g.forceSimulation.conf.MinDistanceBeweenNodes)
+			node.Pos = node.Pos.Add(offset)
+			key = [3]float64{node.Pos.X(), node.Pos.Y(), node.Pos.Z()}
+		}
+		seen[key] = true
 	}
 }
 
 func (g *Graph) resetAcceleration() {
 	for _, node := range g.Nodes {
-		node.acc = vector.Vector{0, 0}
+		node.acc = g.forceSimulation.conf.zero()
 	}
 }
 
@@ -186,26 +257,106 @@ func (g *Graph) attractionByEdgesForce() {
 	for _, edge := range g.Edges {
 		from := g.Nodes[edge.Source]
 		to := g.Nodes[edge.Target]
-		force := g.forceSimulation.calculateAttractionForce(from, to, edge.Value)
+		force := g.forceSimulation.calculateAttractionForce(from, to, edge.Value, edge.Length)
 		vector.In(from.acc).Sub(force)
 		vector.In(to.acc).Add(force)
 	}
 }
 
-func (g *Graph) repulsionBarnesHut(qt *QuadTree) {
-	qt.Clear()
+// directionalForce pushes the source of each directed edge above its target,
+// until the source is at least the edge's length (or both radii) higher
+func (g *Graph) directionalForce() {
+	for _, edge := range g.Edges {
+		if !edge.Directed {
+			continue
+		}
+		from := g.Nodes[edge.Source]
+		to := g.Nodes[edge.Target]
+		gap := math.Max(edge.Length, from.radius+to.radius) - (from.Pos.Y() - to.Pos.Y())
+		if gap <= 0 {
+			continue
+		}
+		scale := gap / math.Min(from.radius, to.radius)
+		scale *= edge.Value * g.forceSimulation.conf.DirectionalStrength * g.forceSimulation.temperature
+		from.acc[1] += scale
+		to.acc[1] -= scale
+	}
+}
+
+// clusterForce pulls nodes towards the center of their cluster and pushes the
+// clusters apart, each node carries an equal share of its cluster's
+// repulsion
+func (g *Graph) clusterForce() {
+	conf := g.forceSimulation.conf
+	type cluster struct {
+		center, force vector.Vector
+		size          float64
+	}
+	clusters := map[int]*cluster{}
+	// in order of the nodes, to keep the simulation deterministic
+	ids := []int{}
+	for _, node := range g.Nodes {
+		if node.Cluster < 0 {
+			continue
+		}
+		c, exists := clusters[node.Cluster]
+		if !exists {
+			c = &cluster{center: conf.zero(), force: conf.zero()}
+			clusters[node.Cluster] = c
+			ids = append(ids, node.Cluster)
+		}
+		vector.In(c.center).Add(node.Pos)
+		c.size++
+	}
+	for _, id := range ids {
+		vector.In(clusters[id].center).Scale(1 / clusters[id].size)
+	}
+	if conf.ClusterRepulsion > 0 {
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				ca, cb := clusters[a], clusters[b]
+				delta := ca.center.Sub(cb.center)
+				dist := delta.Magnitude()
+				if dist == 0 {
+					continue
+				}
+				scale := ca.size * cb.size * g.forceSimulation.temperature / math.Max(dist, conf.MinDistanceBeweenNodes)
+				scale *= conf.ClusterRepulsion * conf.RepulsionMultiplier
+				force := delta.Unit().Scale(scale)
+				vector.In(ca.force).Add(force)
+				vector.In(cb.force).Sub(force)
+			}
+		}
+	}
+	for _, node := range g.Nodes {
+		if node.Cluster < 0 {
+			continue
+		}
+		c := clusters[node.Cluster]
+		if conf.ClusterAttraction > 0 {
+			vector.In(node.acc).Add(c.center.Sub(node.Pos).Scale(conf.ClusterAttraction * g.forceSimulation.temperature))
+		}
+		vector.In(node.acc).Add(c.force.Scale(1 / c.size))
+	}
+}
+
+func (g *Graph) repulsionBarnesHut(tree BarnesHutTree) {
+	tree.Clear()
+	tree.Fit(g.Nodes)
 	for _, node := range g.Nodes {
-		qt.Insert(node)
+		tree.Insert(node)
 	}
-	qt.CalculateMasses()
+	tree.CalculateMasses()
 	calculateForce := func(nodes []*Node) {
 		for _, node := range nodes {
-			force := vector.Vector{0, 0}
-			tmp := vector.Vector{0, 0}
-			qt.CalculateForce(&force, &tmp, node, config.Theta, g.forceSimulation.conf.Parallelization)
+			force := g.forceSimulation.conf.zero()
+			tmp := g.forceSimulation.conf.zero()
+			tree.CalculateForce(&force, &tmp, node, g.forceSimulation.conf.Theta, g.forceSimulation.conf.Parallelization)
 			vector.In(node.acc).Add(force)
 		}
 	}
+	// each node's force is summed up by a single goroutine in tree order, so
+	// the result does not depend on scheduling or Parallelization
 	if g.forceSimulation.conf.Parallelization > 0 {
 		total := len(g.Nodes)
 		p := g.forceSimulation.conf.Parallelization
@@ -224,7 +375,7 @@ func (g *Graph) repulsionBarnesHut(qt *QuadTree) {
 }
 
 func (g *Graph) repulsionNaive() {
-	tmp := vector.Vector{0, 0}
+	tmp := g.forceSimulation.conf.zero()
 	for i, node := range g.Nodes {
 		for j, other := range g.Nodes {
 			if i == j {
@@ -37,6 +37,29 @@ func TestGraph_updatePositions(t *testing.T) {
 	assert.Greaterf(g.Nodes[1].Pos.X(), 2.0, "should move nodes away from each other")
 }
 
+func TestGraph_updatePositions_mobilityAndMaxDisplacement(t *testing.T) {
+	rect := Rect{X: 0, Y: 0, Width: 10, Height: 10}
+	fs := NewForceSimulation(ForceSimulationConfig{Rect: rect})
+	g := NewGraph(
+		[]*Node{
+			{Pos: vector.Vector{1, 1}},
+			{Pos: vector.Vector{1, 1}, Mobility: 0.1},
+			{Pos: vector.Vector{1, 1}, MaxDisplacement: 0.5},
+		},
+		[]*Edge{},
+		fs,
+	)
+	for _, node := range g.Nodes {
+		node.acc = vector.Vector{10, 0}
+	}
+	g.updatePositions(1.0)
+	assert := assert.New(t)
+	moved := g.Nodes[0].Pos.X() - 1
+	assert.Greater(moved, 0.5)
+	assert.InDelta(moved*0.1, g.Nodes[1].Pos.X()-1, 1e-9, "low mobility moves less")
+	assert.Equal(vector.Vector{1.5, 1}, g.Nodes[2].Pos, "displacement is limited")
+}
+
 func TestGraph_repulsionBarnesHut(t *testing.T) {
 	rect := Rect{X: 0, Y: 0, Width: 10, Height: 10}
 	fs := NewForceSimulation(ForceSimulationConfig{Rect: rect})
@@ -113,3 +136,24 @@ func TestPointOnCircle(t *testing.T) {
 		assert.True(t, IsClose(exp.Y(), pointOnCircle(i, 4, 1, vector.Vector{0, 0}).Y()))
 	}
 }
+
+func TestPointOnSphere(t *testing.T) {
+	center := vector.Vector{1, 2, 3}
+	assert := assert.New(t)
+	for i := 0; i < 10; i++ {
+		p := pointOnSphere(i, 10, 5, center)
+		assert.Len(p, 3)
+		assert.InDelta(5.0, p.Sub(center).Magnitude(), 1e-9)
+	}
+	assert.NotEqual(pointOnSphere(0, 10, 5, center), pointOnSphere(1, 10, 5, center))
+}
+
+func TestNewGraph_3D(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{Rect: Rect{X: 0, Y: 0, Width: 10, Height: 10}, Dimensions: 3})
+	g := NewGraph([]*Node{{}, {Pos: vector.Vector{1, 2}}}, []*Edge{}, fs)
+	assert := assert.New(t)
+	assert.Len(g.Nodes[0].Pos, 3)
+	assert.Equal(vector.Vector{1, 2, 0}, g.Nodes[1].Pos, "2D positions are extended")
+	assert.Len(g.Nodes[0].vel, 3)
+	assert.Len(g.Nodes[0].acc, 3)
+}
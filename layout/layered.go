@@ -0,0 +1,211 @@
+package layout
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/quartercastle/vector"
+)
+
+type LayeredConfig struct {
+	// distance between two layers
+	LayerSpacing float64
+	// distance between two nodes in the same layer
+	NodeSpacing float64
+	// number of barycenter sweeps to reduce edge crossings
+	OrderingSweeps int
+}
+
+var DefaultLayeredConfig = LayeredConfig{
+	LayerSpacing:   200.0,
+	NodeSpacing:    100.0,
+	OrderingSweeps: 8,
+}
+
+// Layered computes a hierarchical layout (Sugiyama-style): every edge points
+// from an upper layer to a lower one, i.e. edge sources are placed above
+// their targets (positive y is up). Cycles are broken by reversing edges,
+// long edges are routed through virtual nodes, and the nodes in each layer
+// are ordered by the barycenter heuristic to reduce edge crossings.
+func Layered(ctx context.Context, nodes []*Node, edges []*Edge, conf LayeredConfig) Stats {
+	startTime := time.Now()
+	n := len(nodes)
+	succ, pred := acyclicAdjacency(n, edges)
+	layer := longestPathLayers(n, succ, pred)
+
+	// split edges spanning several layers by virtual nodes
+	layerOf := append([]int{}, layer...)
+	down := make([][]int, n)
+	up := make([][]int, n)
+	for from := 0; from < n; from++ {
+		for _, to := range succ[from] {
+			prev := from
+			for l := layer[from] + 1; l < layer[to]; l++ {
+				virtual := len(layerOf)
+				layerOf = append(layerOf, l)
+				down = append(down, nil)
+				up = append(up, nil)
+				down[prev] = append(down[prev], virtual)
+				up[virtual] = append(up[virtual], prev)
+				prev = virtual
+			}
+			down[prev] = append(down[prev], to)
+			up[to] = append(up[to], prev)
+		}
+	}
+
+	layers := [][]int{}
+	for v, l := range layerOf {
+		for len(layers) <= l {
+			layers = append(layers, []int{})
+		}
+		layers[l] = append(layers[l], v)
+	}
+	order := make([]float64, len(layerOf))
+	for _, members := range layers {
+		for i, v := range members {
+			order[v] = float64(i)
+		}
+	}
+	stats := Stats{}
+	for sweep := 0; sweep < conf.OrderingSweeps; sweep++ {
+		if ctx.Err() != nil {
+			break
+		}
+		if sweep%2 == 0 {
+			for l := 1; l < len(layers); l++ {
+				orderByBarycenter(layers[l], up, order)
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				orderByBarycenter(layers[l], down, order)
+			}
+		}
+		stats.Iterations++
+	}
+
+	top := float64(len(layers)-1) / 2
+	for l, members := range layers {
+		center := float64(len(members)-1) / 2
+		for i, v := range members {
+			if v < n {
+				nodes[v].Pos = vector.Vector{
+					(float64(i) - center) * conf.NodeSpacing,
+					(top - float64(l)) * conf.LayerSpacing,
+				}
+			}
+		}
+	}
+	stats.TotalTime = time.Since(startTime)
+	return stats
+}
+
+// acyclicAdjacency returns successors and predecessors of all nodes, where
+// edges closing a cycle (back edges of a depth-first search) are reversed.
+// Self-loops and duplicate edges are dropped.
+func acyclicAdjacency(n int, edges []*Edge) ([][]int, [][]int) {
+	out := make([][]int, n)
+	for _, edge := range edges {
+		if edge.Source != edge.Target {
+			out[edge.Source] = append(out[edge.Source], edge.Target)
+		}
+	}
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make([]int, n)
+	seen := map[[2]int]bool{}
+	succ := make([][]int, n)
+	pred := make([][]int, n)
+	add := func(from, to int) {
+		if seen[[2]int{from, to}] {
+			return
+		}
+		seen[[2]int{from, to}] = true
+		succ[from] = append(succ[from], to)
+		pred[to] = append(pred[to], from)
+	}
+	type frame struct{ node, next int }
+	for root := 0; root < n; root++ {
+		if state[root] != unvisited {
+			continue
+		}
+		stack := []frame{{node: root}}
+		state[root] = active
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(out[top.node]) {
+				state[top.node] = done
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			to := out[top.node][top.next]
+			top.next++
+			switch state[to] {
+			case active:
+				add(to, top.node) // back edge
+			case done:
+				add(top.node, to)
+			default:
+				add(top.node, to)
+				state[to] = active
+				stack = append(stack, frame{node: to})
+			}
+		}
+	}
+	return succ, pred
+}
+
+// longestPathLayers assigns each node the length of the longest path from
+// any source to it
+func longestPathLayers(n int, succ, pred [][]int) []int {
+	layer := make([]int, n)
+	indegree := make([]int, n)
+	queue := []int{}
+	for v := 0; v < n; v++ {
+		indegree[v] = len(pred[v])
+		if indegree[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, to := range succ[v] {
+			if layer[v]+1 > layer[to] {
+				layer[to] = layer[v] + 1
+			}
+			indegree[to]--
+			if indegree[to] == 0 {
+				queue = append(queue, to)
+			}
+		}
+	}
+	return layer
+}
+
+// orderByBarycenter sorts members by the average order of their neighbors,
+// members without neighbors keep their position
+func orderByBarycenter(members []int, neighbors [][]int, order []float64) {
+	barycenter := make(map[int]float64, len(members))
+	for _, v := range members {
+		if len(neighbors[v]) == 0 {
+			barycenter[v] = order[v]
+			continue
+		}
+		sum := 0.0
+		for _, u := range neighbors[v] {
+			sum += order[u]
+		}
+		barycenter[v] = sum / float64(len(neighbors[v]))
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return barycenter[members[i]] < barycenter[members[j]]
+	})
+	for i, v := range members {
+		order[v] = float64(i)
+	}
+}
@@ -0,0 +1,74 @@
+package layout
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayered(t *testing.T) {
+	for _, test := range []struct {
+		Name  string
+		Nodes int
+		Edges []*Edge
+		// Above[i] = j: node i must be placed above node j
+		Above [][2]int
+		// SameLayer pairs of nodes
+		SameLayer [][2]int
+	}{
+		{
+			Name:  "chain",
+			Nodes: 3,
+			Edges: []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}},
+			Above: [][2]int{{0, 1}, {1, 2}},
+		},
+		{
+			Name:      "long edge is placed via virtual nodes",
+			Nodes:     4,
+			Edges:     []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 0, Target: 2}, {Source: 3, Target: 2}},
+			Above:     [][2]int{{0, 1}, {1, 2}, {3, 2}},
+			SameLayer: [][2]int{{0, 3}},
+		},
+		{
+			Name:  "cycle is broken",
+			Nodes: 3,
+			Edges: []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 2, Target: 0}},
+			Above: [][2]int{{0, 1}, {1, 2}},
+		},
+		{
+			Name:      "self-loop and duplicate edges",
+			Nodes:     2,
+			Edges:     []*Edge{{Source: 0, Target: 0}, {Source: 0, Target: 1}, {Source: 0, Target: 1}},
+			Above:     [][2]int{{0, 1}},
+			SameLayer: [][2]int{},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			nodes := make([]*Node, test.Nodes)
+			for i := range nodes {
+				nodes[i] = &Node{}
+			}
+			Layered(context.Background(), nodes, test.Edges, DefaultLayeredConfig)
+			assert := assert.New(t)
+			for _, node := range nodes {
+				assert.Len(node.Pos, 2)
+			}
+			for _, pair := range test.Above {
+				assert.Greater(nodes[pair[0]].Pos.Y(), nodes[pair[1]].Pos.Y(), "%d above %d", pair[0], pair[1])
+			}
+			for _, pair := range test.SameLayer {
+				assert.Equal(nodes[pair[0]].Pos.Y(), nodes[pair[1]].Pos.Y())
+				assert.NotEqual(nodes[pair[0]].Pos.X(), nodes[pair[1]].Pos.X())
+			}
+		})
+	}
+}
+
+func TestLayered_barycenterOrdering(t *testing.T) {
+	// 0 -> 3, 1 -> 2: without reordering the edges would cross
+	nodes := []*Node{{}, {}, {}, {}}
+	edges := []*Edge{{Source: 0, Target: 3}, {Source: 1, Target: 2}}
+	Layered(context.Background(), nodes, edges, DefaultLayeredConfig)
+	assert.Less(t, nodes[3].Pos.X(), nodes[2].Pos.X())
+}
@@ -2,6 +2,7 @@ package layout
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"math/rand"
 	"os"
@@ -13,37 +14,20 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// TODO(skep): merge all the config values into ForceSimulationConfig struct
-// values taken from https://github.com/jwhandley/graphyz/blob/main/config.yaml
-var config = struct {
-	// percentage velocity decay each tick of the simulation (1.0 = 100%)
-	VelocityDecay float64
-	// Theta parameter for BarnesHut algorithm (see https://en.wikipedia.org/wiki/Barnes%E2%80%93Hut_simulation#Calculating_the_force_acting_on_a_body)
-	Theta float64
-	// Enable/Disable usage of BarnesHut optimization algorithm.
-	BarnesHut       bool
-	EnableProfiling bool
-	// Capacity of each tile in the QuadTree used for BarnesHut algorithm.
-	Capacity int
-}{
-	VelocityDecay:   0.1,
-	Theta:           0.75,
-	BarnesHut:       true,
-	EnableProfiling: false,
-	Capacity:        10,
-}
-
+// ForceSimulationConfig configures a ForceSimulation. Zero values are replaced
+// by DefaultForceSimulationConfig. The env tags allow loading it via
+// github.com/caarlos0/env, usually with a prefix per simulation.
 type ForceSimulationConfig struct {
-	Rect                   Rect
-	DefaultNodeRadius      float64
-	MinDistanceBeweenNodes float64
-	RepulsionMultiplier    float64
+	Rect                   Rect    `envPrefix:"RECT_"`
+	DefaultNodeRadius      float64 `env:"NODE_RADIUS"`
+	MinDistanceBeweenNodes float64 `env:"MIN_DISTANCE_BETWEEN_NODES"`
+	RepulsionMultiplier    float64 `env:"REPULSION_MULTIPLIER"`
 	// initial temperature of simulation
-	AlphaInit float64
+	AlphaInit float64 `env:"ALPHA_INIT"`
 	// decay of temperature per tick
-	AlphaDecay float64
+	AlphaDecay float64 `env:"ALPHA_DECAY"`
 	// target temperature of simulation
-	AlphaTarget float64
+	AlphaTarget float64 `env:"ALPHA_TARGET"`
 	// FrameTime describes the time passed per tick of simulation.
 	// Increasing this value increases the range of the position updates per
 	// tick, and thus decreases the precision of the simulation.
@@ -51,20 +35,59 @@ type ForceSimulationConfig struct {
 	//	   and thus never reaching equilibrium
 	//	=> too low FrameTime might lead to a lot of computation without ever
 	//	   reaching the optimal position
-	FrameTime                       float64
-	RandomFloat                     func() float64
-	ScreenMultiplierToClampPosition float64
-	// Parallelization is the number of goroutines spawned using BarnesHut
-	// algorithm *times 4*, i.e. if Parallelization = 1, then for each
-	// top-level sub-space (4) a goroutine a spawned.
-	Parallelization int
+	FrameTime float64 `env:"FRAME_TIME"`
+	// RandomFloat is the source of randomness, e.g. for initial positions. If
+	// nil, it is derived from Seed.
+	RandomFloat func() float64
+	// Seed makes the simulation reproducible: a ForceSimulation configured
+	// with the same non-zero Seed computes bit-identical positions for the
+	// same sequence of calls. Zero uses the global random source.
+	Seed                            int64   `env:"SEED"`
+	ScreenMultiplierToClampPosition float64 `env:"SCREEN_MULTIPLIER_TO_CLAMP_POSITION"`
+	// Parallelization is the number of goroutines computing the repulsion
+	// forces of the BarnesHut algorithm, each for an equal share of the
+	// nodes. Zero computes them in the calling goroutine.
+	Parallelization int `env:"PARALLELIZATION"`
 	// Gravity enables a force directed towards the center of the simulation,
 	// to keep nodes from flying away to infinity
-	Gravity         bool
-	GravityStrength float64
+	Gravity         bool    `env:"GRAVITY"`
+	GravityStrength float64 `env:"GRAVITY_STRENGTH"`
 	// InitialLayout defines how nodes are initialized before the force
 	// simulation starts
-	InitialLayout InitialLayout
+	InitialLayout InitialLayout `env:"INITIAL_LAYOUT"`
+	// Dimensions of the embedding, either 2 or 3 (default: 2). In 3D the
+	// simulation space is Rect extended by Rect.Z and Rect.Depth. Not read
+	// from the environment, since both simulations of a layouter share it.
+	Dimensions int
+	// DirectionalStrength scales the force pushing the source of directed
+	// edges above their target, zero disables it. Not read from the
+	// environment, like Dimensions.
+	DirectionalStrength float64
+	// ClusterAttraction pulls nodes towards the center of their cluster (see
+	// Node.Cluster), zero disables it
+	ClusterAttraction float64 `env:"CLUSTER_ATTRACTION"`
+	// ClusterRepulsion pushes the centers of different clusters apart, in
+	// addition to the repulsion between nodes, zero disables it
+	ClusterRepulsion float64 `env:"CLUSTER_REPULSION"`
+	// percentage velocity decay each tick of the simulation (1.0 = 100%)
+	VelocityDecay float64 `env:"VELOCITY_DECAY"`
+	// Theta parameter for BarnesHut algorithm (see https://en.wikipedia.org/wiki/Barnes%E2%80%93Hut_simulation#Calculating_the_force_acting_on_a_body)
+	Theta float64 `env:"THETA"`
+	// DisableBarnesHut computes the repulsion between all pairs of nodes
+	// instead of using the BarnesHut approximation
+	DisableBarnesHut bool `env:"DISABLE_BARNES_HUT"`
+	// TreeCapacity is the capacity of each tile in the QuadTree/Octree used
+	// for BarnesHut algorithm.
+	TreeCapacity int `env:"TREE_CAPACITY"`
+	// EnableProfiling writes a CPU profile of each ComputeLayout call to
+	// cpu.pp
+	EnableProfiling bool `env:"ENABLE_PROFILING"`
+	// ConvergenceEnergy stops the simulation early, once the average kinetic
+	// energy per node drops below it. Zero disables it.
+	ConvergenceEnergy float64 `env:"CONVERGENCE_ENERGY"`
+	// ConvergenceDisplacement stops the simulation early, once no node moves
+	// further than it within a tick. Zero disables it.
+	ConvergenceDisplacement float64 `env:"CONVERGENCE_DISPLACEMENT"`
 }
 
 type InitialLayout int
@@ -73,12 +96,30 @@ const (
 	InitialLayoutUndefined InitialLayout = iota
 	// initialize nodes in a circle, evenly spread
 	InitialLayoutCircle
-	// initialize nodes randomly
+	// initialize nodes randomly inside Rect (a box in 3D)
 	InitialLayoutRandom
+	// initialize nodes on a sphere, evenly spread (a circle in 2D)
+	InitialLayoutSphere
 )
 
+var initialLayoutNames = map[string]InitialLayout{
+	"circle": InitialLayoutCircle,
+	"random": InitialLayoutRandom,
+	"sphere": InitialLayoutSphere,
+}
+
+// UnmarshalText parses one of "circle", "random" or "sphere"
+func (l *InitialLayout) UnmarshalText(text []byte) error {
+	layout, ok := initialLayoutNames[string(text)]
+	if !ok {
+		return fmt.Errorf("unknown initial layout '%s'", text)
+	}
+	*l = layout
+	return nil
+}
+
 var DefaultForceSimulationConfig = ForceSimulationConfig{
-	Rect:                            Rect{0.0, 0.0, 1200, 800},
+	Rect:                            Rect{X: 0.0, Y: 0.0, Width: 1200, Height: 800},
 	MinDistanceBeweenNodes:          1e-2, // bad default, very small..
 	DefaultNodeRadius:               1.0,
 	RepulsionMultiplier:             10.0,
@@ -91,6 +132,56 @@ var DefaultForceSimulationConfig = ForceSimulationConfig{
 	Gravity:                         true,
 	GravityStrength:                 0.5,
 	InitialLayout:                   InitialLayoutRandom,
+	Dimensions:                      2,
+	VelocityDecay:                   0.1,
+	Theta:                           0.75,
+	TreeCapacity:                    10,
+}
+
+// Validate reports configuration values, which would break the simulation.
+// Zero values are valid, since they are replaced by defaults.
+func (conf ForceSimulationConfig) Validate() error {
+	for _, value := range []struct {
+		name  string
+		value float64
+	}{
+		{"Rect.Width", conf.Rect.Width},
+		{"Rect.Height", conf.Rect.Height},
+		{"Rect.Depth", conf.Rect.Depth},
+		{"DefaultNodeRadius", conf.DefaultNodeRadius},
+		{"MinDistanceBeweenNodes", conf.MinDistanceBeweenNodes},
+		{"RepulsionMultiplier", conf.RepulsionMultiplier},
+		{"AlphaInit", conf.AlphaInit},
+		{"AlphaTarget", conf.AlphaTarget},
+		{"FrameTime", conf.FrameTime},
+		{"ScreenMultiplierToClampPosition", conf.ScreenMultiplierToClampPosition},
+		{"Parallelization", float64(conf.Parallelization)},
+		{"GravityStrength", conf.GravityStrength},
+		{"DirectionalStrength", conf.DirectionalStrength},
+		{"ClusterAttraction", conf.ClusterAttraction},
+		{"ClusterRepulsion", conf.ClusterRepulsion},
+		{"Theta", conf.Theta},
+		{"TreeCapacity", float64(conf.TreeCapacity)},
+		{"ConvergenceEnergy", conf.ConvergenceEnergy},
+		{"ConvergenceDisplacement", conf.ConvergenceDisplacement},
+	} {
+		if value.value < 0 || math.IsNaN(value.value) || math.IsInf(value.value, 0) {
+			return fmt.Errorf("%s must be a non-negative number, got %v", value.name, value.value)
+		}
+	}
+	if conf.AlphaDecay < 0 || conf.AlphaDecay > 1 {
+		return fmt.Errorf("AlphaDecay must be in [0,1], got %v", conf.AlphaDecay)
+	}
+	if conf.VelocityDecay < 0 || conf.VelocityDecay > 1 {
+		return fmt.Errorf("VelocityDecay must be in [0,1], got %v", conf.VelocityDecay)
+	}
+	if conf.Dimensions != 0 && conf.Dimensions != 2 && conf.Dimensions != 3 {
+		return fmt.Errorf("Dimensions must be 2 or 3, got %d", conf.Dimensions)
+	}
+	if conf.InitialLayout < InitialLayoutUndefined || conf.InitialLayout > InitialLayoutSphere {
+		return fmt.Errorf("unknown InitialLayout %d", conf.InitialLayout)
+	}
+	return nil
 }
 
 // ForceSimulation holds all information needed for a force based graph
@@ -98,6 +189,8 @@ var DefaultForceSimulationConfig = ForceSimulationConfig{
 type ForceSimulation struct {
 	conf        ForceSimulationConfig
 	temperature float64
+	// random is the source of conf.RandomFloat, if derived from conf.Seed
+	random *rand.Rand
 }
 
 func NewForceSimulation(conf ForceSimulationConfig) *ForceSimulation {
@@ -131,6 +224,11 @@ func (fs *ForceSimulation) ApplyConfig(conf ForceSimulationConfig) {
 	if conf.FrameTime == 0.0 {
 		conf.FrameTime = DefaultForceSimulationConfig.FrameTime
 	}
+	fs.random = nil
+	if conf.RandomFloat == nil && conf.Seed != 0 {
+		fs.random = rand.New(rand.NewSource(conf.Seed))
+		conf.RandomFloat = fs.random.Float64
+	}
 	if conf.RandomFloat == nil {
 		conf.RandomFloat = func() float64 { return rand.Float64() }
 	}
@@ -143,11 +241,35 @@ func (fs *ForceSimulation) ApplyConfig(conf ForceSimulationConfig) {
 	if conf.InitialLayout == InitialLayoutUndefined {
 		conf.InitialLayout = DefaultForceSimulationConfig.InitialLayout
 	}
+	if conf.Dimensions == 0 {
+		conf.Dimensions = DefaultForceSimulationConfig.Dimensions
+	}
+	if conf.VelocityDecay == 0.0 {
+		conf.VelocityDecay = DefaultForceSimulationConfig.VelocityDecay
+	}
+	if conf.Theta == 0.0 {
+		conf.Theta = DefaultForceSimulationConfig.Theta
+	}
+	if conf.TreeCapacity == 0 {
+		conf.TreeCapacity = DefaultForceSimulationConfig.TreeCapacity
+	}
+	if conf.Dimensions == 3 && conf.Rect.Depth == 0.0 {
+		// cube-ish box centered around z=0
+		conf.Rect.Depth = math.Min(conf.Rect.Width, conf.Rect.Height)
+		conf.Rect.Z = -conf.Rect.Depth / 2
+	}
 	fs.conf = conf
 	fs.temperature = fs.conf.AlphaInit
 }
 
-func randomVectorInside(rect Rect, rndSource func() float64) vector.Vector {
+func randomVectorInside(rect Rect, rndSource func() float64, dimensions int) vector.Vector {
+	if dimensions == 3 {
+		return vector.Vector{
+			rect.X + rndSource()*rect.Width,
+			rect.Y + rndSource()*rect.Height,
+			rect.Z + rndSource()*rect.Depth,
+		}
+	}
 	return vector.Vector{
 		rect.X + rndSource()*rect.Width,
 		rect.Y + rndSource()*rect.Height,
@@ -158,33 +280,112 @@ func (fsconf ForceSimulationConfig) RandomVectorInside() vector.Vector {
 	if fsconf.RandomFloat == nil {
 		fsconf.RandomFloat = func() float64 { return rand.Float64() }
 	}
-	return randomVectorInside(fsconf.Rect, fsconf.RandomFloat)
+	return randomVectorInside(fsconf.Rect, fsconf.RandomFloat, fsconf.dimensions())
+}
+
+func (fsconf ForceSimulationConfig) dimensions() int {
+	if fsconf.Dimensions == 3 {
+		return 3
+	}
+	return 2
+}
+
+// zero returns the null vector with the dimensions of the simulation
+func (fsconf ForceSimulationConfig) zero() vector.Vector {
+	return make(vector.Vector, fsconf.dimensions())
+}
+
+// initialPosition returns the position of the i-th of n nodes according to
+// fsconf.InitialLayout
+func (fsconf ForceSimulationConfig) initialPosition(i, n int) vector.Vector {
+	radius := int(math.Floor(math.Min(fsconf.Rect.Width, fsconf.Rect.Height) / 2))
+	center := fsconf.Rect.Center()
+	switch fsconf.InitialLayout {
+	case InitialLayoutSphere:
+		if fsconf.dimensions() == 3 {
+			return pointOnSphere(i, n, radius, center)
+		}
+		return pointOnCircle(i, n, radius, center)
+	case InitialLayoutCircle:
+		pos := pointOnCircle(i, n, radius, center)
+		if fsconf.dimensions() == 3 {
+			pos = append(pos, center.Z())
+		}
+		return pos
+	default:
+		return fsconf.RandomVectorInside()
+	}
 }
 
 type Stats struct {
 	Iterations int
 	TotalTime  time.Duration
+	// Converged is set if the simulation stopped since the nodes came to
+	// rest, see ForceSimulationConfig.ConvergenceEnergy and
+	// ForceSimulationConfig.ConvergenceDisplacement
+	Converged bool
+	// TimedOut is set if the context stopped the simulation early
+	TimedOut bool
+	// Energy is the average kinetic energy per node in the last tick
+	Energy float64
+	// AverageDisplacement and MaxDisplacement describe the movement of the
+	// nodes in the last tick
+	AverageDisplacement float64
+	MaxDisplacement     float64
+	// Quality is not measured by ComputeLayout, see
+	// ForceSimulation.MeasureQuality
+	Quality
 }
 
 // InitializeNodes assigns positions to all nodes based on fs.conf.InitialLayout
 func (fs *ForceSimulation) InitializeNodes(ctx context.Context, nodes []*Node) {
-	if fs.conf.InitialLayout == InitialLayoutCircle {
-		for i := range nodes {
-			nodes[i].Pos = fs.conf.RandomVectorInside()
-		}
-	} else if fs.conf.InitialLayout == InitialLayoutCircle {
-		for i := range nodes {
-			nodes[i].Pos = pointOnCircle(
-				i, len(nodes),
-				int(math.Floor(min(fs.conf.Rect.X, fs.conf.Rect.Y))),
-				fs.conf.Rect.Center(),
-			)
+	for i := range nodes {
+		nodes[i].Pos = fs.conf.initialPosition(i, len(nodes))
+	}
+}
+
+// Config returns the configuration in use. If RandomFloat was derived from
+// Seed, it is unset, so that applying the configuration starts over.
+func (fs *ForceSimulation) Config() ForceSimulationConfig {
+	conf := fs.conf
+	if fs.random != nil {
+		conf.RandomFloat = nil
+	}
+	return conf
+}
+
+// Dimensions returns the number of dimensions of the embedding
+func (fs *ForceSimulation) Dimensions() int {
+	return fs.conf.dimensions()
+}
+
+// newTree returns the space partitioning tree for the Barnes-Hut algorithm
+// matching the dimensions of the simulation
+func (fs *ForceSimulation) newTree() BarnesHutTree {
+	treeConfig := &QuadTreeConfig{CapacityOfEachBlock: fs.conf.TreeCapacity, MaxDepth: QUADTREE_DEFAULT_CONFIG.MaxDepth}
+	if fs.conf.dimensions() == 3 {
+		return NewOctree(treeConfig, fs, fs.conf.Rect)
+	}
+	return NewQuadTree(treeConfig, fs, fs.conf.Rect)
+}
+
+// InitializeNodesNear places each node randomly within radius around
+// anchors[i], nodes without an anchor (nil) are initialized by
+// InitializeNodes.
+func (fs *ForceSimulation) InitializeNodesNear(ctx context.Context, nodes []*Node, anchors []vector.Vector, radius float64) {
+	rest := []*Node{}
+	for i, node := range nodes {
+		if anchors[i] == nil {
+			rest = append(rest, node)
+			continue
 		}
+		node.Pos = anchors[i].Add(fs.randomDirection().Scale(fs.conf.RandomFloat() * radius))
 	}
+	fs.InitializeNodes(ctx, rest)
 }
 
 func (fs *ForceSimulation) ComputeLayout(ctx context.Context, nodes []*Node, edges []*Edge) ([]*Node, Stats) {
-	if config.EnableProfiling {
+	if fs.conf.EnableProfiling {
 		f, err := os.Create("cpu.pp")
 		if err != nil {
 			panic(err)
@@ -193,7 +394,8 @@ func (fs *ForceSimulation) ComputeLayout(ctx context.Context, nodes []*Node, edg
 		defer pprof.StopCPUProfile()
 	}
 	graph := NewGraph(nodes, edges, fs)
-	qt := NewQuadTree(&QUADTREE_DEFAULT_CONFIG, fs, fs.conf.Rect)
+	graph.jitterCoincidentNodes()
+	tree := fs.newTree()
 	fs.temperature = fs.conf.AlphaInit
 	startTime := time.Now()
 	stats := Stats{}
@@ -202,21 +404,65 @@ simulation:
 		select {
 		case <-ctx.Done():
 			log.Ctx(ctx).Info().Msg("simulation stopped early (timeout)")
+			stats.TimedOut = true
 			break simulation
 		default:
 			// continue looping
 		}
-		graph.ApplyForce(fs.conf.FrameTime, qt)
+		graph.ApplyForce(fs.conf.FrameTime, tree)
 		stats.Iterations += 1
+		if fs.converged(graph) {
+			stats.Converged = true
+			break
+		}
 		fs.temperature += (fs.conf.AlphaTarget - fs.temperature) * fs.conf.AlphaDecay
 		if IsClose(fs.conf.AlphaTarget, fs.temperature) {
-			stats.TotalTime = time.Since(startTime)
 			break
 		}
 	}
+	stats.TotalTime = time.Since(startTime)
+	if len(graph.Nodes) > 0 {
+		stats.Energy = graph.energy / float64(len(graph.Nodes))
+		stats.AverageDisplacement = graph.totalDisplacement / float64(len(graph.Nodes))
+	}
+	stats.MaxDisplacement = graph.maxDisplacement
 	return graph.Nodes, stats
 }
 
+// MeasureQuality computes the Quality of a layout computed by fs. Edge
+// crossings are sampled from a random source separate from the simulation,
+// so that measuring does not change the following layouts. It is derived
+// from Seed, if set, to keep the measurement reproducible.
+func (fs *ForceSimulation) MeasureQuality(nodes []*Node, edges []*Edge) Quality {
+	var randomFloat func() float64
+	if fs.conf.Seed != 0 {
+		randomFloat = rand.New(rand.NewSource(fs.conf.Seed)).Float64
+	}
+	return MeasureQuality(nodes, edges, randomFloat)
+}
+
+// converged reports whether the nodes of g came to rest in the last tick
+func (fs *ForceSimulation) converged(g *Graph) bool {
+	if len(g.Nodes) == 0 {
+		return false
+	}
+	if fs.conf.ConvergenceEnergy > 0 && g.energy/float64(len(g.Nodes)) < fs.conf.ConvergenceEnergy {
+		return true
+	}
+	return fs.conf.ConvergenceDisplacement > 0 && g.maxDisplacement < fs.conf.ConvergenceDisplacement
+}
+
+// randomDirection returns a random unit vector
+func (fs *ForceSimulation) randomDirection() vector.Vector {
+	angle := fs.conf.RandomFloat() * 2.0 * math.Pi
+	if fs.conf.dimensions() == 3 {
+		z := 2.0*fs.conf.RandomFloat() - 1.0
+		r := math.Sqrt(1 - z*z)
+		return vector.Vector{r * math.Cos(angle), r * math.Sin(angle), z}
+	}
+	return vector.Vector{math.Cos(angle), math.Sin(angle)}
+}
+
 func IsCloseVec(a, b vector.Vector, tolerance ...float64) bool {
 	for i := range a {
 		if !IsClose(a[i], b[i], tolerance...) {
@@ -256,8 +502,8 @@ func (fs *ForceSimulation) calculateRepulsionForce(totalForce, tmp *vector.Vecto
 	vector.In(*totalForce).Add(*tmp)
 }
 
-func (fs *ForceSimulation) calculateAttractionForce(from *Node, to *Node, weight float64) vector.Vector {
-	return fs.calculateAttractionForce_simple(from, to, weight)
+func (fs *ForceSimulation) calculateAttractionForce(from *Node, to *Node, weight, length float64) vector.Vector {
+	return fs.calculateAttractionForce_simple(from, to, weight, length)
 	//return fs.calculateAttractionForce_forcegraphjs(from, to, weight)
 }
 
@@ -276,11 +522,15 @@ func (fs *ForceSimulation) calculateAttractionForce_forcegraphjs(from *Node, to
 	return force
 }
 
-func (fs *ForceSimulation) calculateAttractionForce_simple(from *Node, to *Node, weight float64) vector.Vector {
+func (fs *ForceSimulation) calculateAttractionForce_simple(from *Node, to *Node, weight, length float64) vector.Vector {
 	delta := from.Pos.Sub(to.Pos)
 	dist := clamp(delta.Magnitude(), fs.conf.MinDistanceBeweenNodes, math.Inf(+1))
 	// reduce distance by estimated radius: don't pull nodes together, that already touch!
 	scale := math.Abs(dist - (from.radius + to.radius))
+	if length > 0 {
+		// spring with an ideal length: pushes apart nodes closer than length
+		scale = dist - length
+	}
 	scale /= math.Min(from.radius, to.radius)
 	scale *= weight * fs.temperature
 	force := delta.Unit().Scale(scale)
@@ -2,8 +2,10 @@ package layout
 
 import (
 	"context"
+	"math"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/quartercastle/vector"
 	"github.com/stretchr/testify/assert"
@@ -14,10 +16,64 @@ func TestForceSimulation_ComputeLayoutShouldBreakOnCtxDone(t *testing.T) {
 	cancelled_ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 	p0, p1 := vector.Vector{1, 1}, vector.Vector{2, 2}
-	nodes, _ := fs.ComputeLayout(cancelled_ctx, []*Node{{Pos: p0}, {Pos: p1}}, []*Edge{})
+	nodes, stats := fs.ComputeLayout(cancelled_ctx, []*Node{{Pos: p0}, {Pos: p1}}, []*Edge{})
 	assert := assert.New(t)
 	assert.Equal(p0, nodes[0].Pos)
 	assert.Equal(p1, nodes[1].Pos)
+	assert.True(stats.TimedOut)
+	assert.False(stats.Converged)
+}
+
+func TestForceSimulation_ComputeLayout_convergence(t *testing.T) {
+	newNodes := func() []*Node {
+		return []*Node{{Pos: vector.Vector{100, 100}}, {Pos: vector.Vector{110, 100}}, {Pos: vector.Vector{100, 120}}}
+	}
+	edges := []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}}
+	rnd := rand.New(rand.NewSource(1))
+	conf := DefaultForceSimulationConfig
+	conf.RandomFloat = rnd.Float64
+	_, full := NewForceSimulation(conf).ComputeLayout(context.Background(), newNodes(), edges)
+	assert := assert.New(t)
+	assert.False(full.Converged)
+	assert.False(full.TimedOut)
+	assert.Greater(full.Energy, 0.0)
+	assert.Greater(full.MaxDisplacement, 0.0)
+	assert.GreaterOrEqual(full.MaxDisplacement, full.AverageDisplacement)
+
+	for _, modify := range []func(*ForceSimulationConfig){
+		func(conf *ForceSimulationConfig) { conf.ConvergenceDisplacement = math.MaxFloat64 },
+		func(conf *ForceSimulationConfig) { conf.ConvergenceEnergy = math.MaxFloat64 },
+	} {
+		early := conf
+		modify(&early)
+		_, stats := NewForceSimulation(early).ComputeLayout(context.Background(), newNodes(), edges)
+		assert.True(stats.Converged)
+		assert.Equal(1, stats.Iterations)
+		assert.Less(stats.Iterations, full.Iterations)
+	}
+}
+
+func TestForceSimulation_MeasureQuality_keepsLayoutReproducible(t *testing.T) {
+	defer func(maxEdges, samples int) { MaxEdgesForExactCrossings, CrossingSamples = maxEdges, samples }(MaxEdgesForExactCrossings, CrossingSamples)
+	MaxEdgesForExactCrossings, CrossingSamples = 0, 100 // sample crossings
+	edges := []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 2, Target: 3}, {Source: 3, Target: 0}}
+	conf := DefaultForceSimulationConfig
+	conf.Seed = 7
+	conf.AlphaDecay = 0.5
+	layouts := [][]*Node{}
+	for _, measure := range []bool{false, true} {
+		fs := NewForceSimulation(conf)
+		nodes, _ := fs.ComputeLayout(context.Background(), []*Node{{}, {}, {}, {}}, edges)
+		if measure {
+			quality := fs.MeasureQuality(nodes, edges)
+			assert.Equal(t, quality, fs.MeasureQuality(nodes, edges), "reproducible")
+		}
+		nodes, _ = fs.ComputeLayout(context.Background(), []*Node{{}, {}, {}, {}}, edges)
+		layouts = append(layouts, nodes)
+	}
+	for i := range layouts[0] {
+		assert.Equal(t, layouts[0][i].Pos, layouts[1][i].Pos)
+	}
 }
 
 func TestForceSimulation_ComputeLayout(t *testing.T) {
@@ -71,20 +127,233 @@ func TestForceSimulation_ComputeLayout(t *testing.T) {
 	}
 }
 
+func TestForceSimulation_ComputeLayout_3D(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{
+		Dimensions:  3,
+		RandomFloat: rand.New(rand.NewSource(1)).Float64,
+	})
+	nodes, _ := fs.ComputeLayout(context.Background(),
+		[]*Node{{Pos: vector.Vector{9, 9, 9}}, {Pos: vector.Vector{10, 10, 10}}},
+		[]*Edge{{Source: 0, Target: 1}},
+	)
+	assert := assert.New(t)
+	for _, node := range nodes {
+		assert.Len(node.Pos, 3)
+	}
+	assert.Less(nodes[0].Pos.Z(), 9.0, "nodes are pushed apart along the z-axis as well")
+	assert.Greater(nodes[1].Pos.Z(), 10.0, "nodes are pushed apart along the z-axis as well")
+}
+
+func TestForceSimulation_ComputeLayout_degenerate(t *testing.T) {
+	for _, test := range []struct {
+		Name       string
+		Dimensions int
+		Nodes      func() []*Node
+	}{
+		{
+			Name: "coincident nodes",
+			Nodes: func() []*Node {
+				nodes := []*Node{}
+				for i := 0; i < 50; i++ {
+					nodes = append(nodes, &Node{Pos: vector.Vector{1, 1}})
+				}
+				return nodes
+			},
+		},
+		{
+			Name:       "coincident nodes 3D",
+			Dimensions: 3,
+			Nodes: func() []*Node {
+				nodes := []*Node{}
+				for i := 0; i < 50; i++ {
+					nodes = append(nodes, &Node{Pos: vector.Vector{1, 1, 1}})
+				}
+				return nodes
+			},
+		},
+		{
+			Name: "nodes far outside of rect",
+			Nodes: func() []*Node {
+				return []*Node{{Pos: vector.Vector{-5000, -5000}}, {Pos: vector.Vector{-5001, -5001}}, {Pos: vector.Vector{5000, 5000}}}
+			},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			fs := NewForceSimulation(ForceSimulationConfig{
+				Dimensions:  test.Dimensions,
+				RandomFloat: rand.New(rand.NewSource(1)).Float64,
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			nodes, _ := fs.ComputeLayout(ctx, test.Nodes(), []*Edge{})
+			assert := assert.New(t)
+			assert.NoError(ctx.Err(), "simulation must not hang")
+			positions := map[[3]float64]bool{}
+			for _, node := range nodes {
+				for _, x := range node.Pos {
+					assert.False(math.IsNaN(x), "NaN position")
+				}
+				positions[[3]float64{node.Pos.X(), node.Pos.Y(), node.Pos.Z()}] = true
+			}
+			assert.Len(positions, len(nodes), "nodes are pushed apart")
+		})
+	}
+}
+
+func TestForceSimulation_InitializeNodes(t *testing.T) {
+	for _, test := range []struct {
+		Name       string
+		Config     ForceSimulationConfig
+		Dimensions int
+		Radius     float64
+	}{
+		{Name: "circle", Config: ForceSimulationConfig{InitialLayout: InitialLayoutCircle}, Dimensions: 2, Radius: 400},
+		{Name: "circle 3D", Config: ForceSimulationConfig{InitialLayout: InitialLayoutCircle, Dimensions: 3}, Dimensions: 3, Radius: 400},
+		{Name: "sphere", Config: ForceSimulationConfig{InitialLayout: InitialLayoutSphere, Dimensions: 3}, Dimensions: 3, Radius: 400},
+		{Name: "sphere 2D", Config: ForceSimulationConfig{InitialLayout: InitialLayoutSphere}, Dimensions: 2, Radius: 400},
+		{Name: "random box", Config: ForceSimulationConfig{InitialLayout: InitialLayoutRandom, Dimensions: 3}, Dimensions: 3},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			fs := NewForceSimulation(test.Config)
+			nodes := []*Node{{}, {}, {}, {}}
+			fs.InitializeNodes(context.Background(), nodes)
+			rect := fs.conf.Rect
+			assert := assert.New(t)
+			for _, node := range nodes {
+				assert.Len(node.Pos, test.Dimensions)
+				assert.True(rect.Contains(node.Pos), "%v outside of %v", node.Pos, rect)
+				if test.Radius > 0 {
+					assert.InDelta(test.Radius, node.Pos.Sub(rect.Center()).Magnitude(), 1e-9)
+				}
+			}
+		})
+	}
+}
+
+func TestForceSimulation_InitializeNodesNear(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{
+		Rect:          Rect{X: 1000, Y: 1000, Width: 10, Height: 10},
+		InitialLayout: InitialLayoutCircle,
+		RandomFloat:   rand.New(rand.NewSource(1)).Float64,
+	})
+	nodes := []*Node{{}, {}}
+	fs.InitializeNodesNear(context.Background(), nodes, []vector.Vector{{5, 5}, nil}, 2.0)
+	assert := assert.New(t)
+	assert.LessOrEqual(nodes[0].Pos.Sub(vector.Vector{5, 5}).Magnitude(), 2.0, "close to anchor")
+	assert.GreaterOrEqual(nodes[1].Pos.X(), 1000.0, "initialized inside rect")
+	assert.GreaterOrEqual(nodes[1].Pos.Y(), 1000.0, "initialized inside rect")
+}
+
 func TestForceSimulation_calculateAttractionForce(t *testing.T) {
 	fs := NewForceSimulation(DefaultForceSimulationConfig)
 	graph := NewGraph([]*Node{{Pos: vector.Vector{1, 1}}, {Pos: vector.Vector{4, 5}}}, []*Edge{}, fs)
 	from, to := graph.Nodes[0], graph.Nodes[1]
-	force := fs.calculateAttractionForce(from, to, 1.0)
+	force := fs.calculateAttractionForce(from, to, 1.0, 0)
 	assert := assert.New(t)
 	assert.Equal(vector.Vector{-1.7999999999999998, -2.4000000000000004}, force)
 
 	graph = NewGraph([]*Node{{Pos: vector.Vector{10, 10}}, {Pos: vector.Vector{40, 50}}}, []*Edge{}, fs)
 	from, to = graph.Nodes[0], graph.Nodes[1]
-	force = fs.calculateAttractionForce(from, to, 1.0)
+	force = fs.calculateAttractionForce(from, to, 1.0, 0)
 	assert.Equal(vector.Vector{-28.799999999999997, -38.400000000000006}, force)
 }
 
+func TestForceSimulation_calculateAttractionForce_length(t *testing.T) {
+	fs := NewForceSimulation(DefaultForceSimulationConfig)
+	graph := NewGraph([]*Node{{Pos: vector.Vector{1, 1}}, {Pos: vector.Vector{4, 5}}}, []*Edge{}, fs)
+	from, to := graph.Nodes[0], graph.Nodes[1]
+	assert := assert.New(t)
+	assert.Equal(vector.Vector{0, 0}, fs.calculateAttractionForce(from, to, 1.0, 5))
+	tooClose := fs.calculateAttractionForce(from, to, 1.0, 10)
+	assert.Greater(tooClose.X(), 0.0, "from is pushed away from to")
+	assert.Greater(tooClose.Y(), 0.0, "from is pushed away from to")
+	tooFar := fs.calculateAttractionForce(from, to, 2.0, 2.5)
+	assert.Less(tooFar.X(), 0.0, "from is pulled towards to")
+	assert.Equal(tooFar.Scale(0.5), fs.calculateAttractionForce(from, to, 1.0, 2.5))
+}
+
+func TestGraph_directionalForce(t *testing.T) {
+	conf := DefaultForceSimulationConfig
+	conf.DirectionalStrength = 1.0
+	fs := NewForceSimulation(conf)
+	fs.temperature = 1.0
+	graph := NewGraph(
+		[]*Node{{Pos: vector.Vector{1, 1}}, {Pos: vector.Vector{1, 10}}, {Pos: vector.Vector{5, 1}}, {Pos: vector.Vector{5, 20}}},
+		[]*Edge{
+			{Source: 0, Target: 1, Directed: true, Length: 5},
+			{Source: 3, Target: 2, Directed: true, Length: 5}, // already above
+			{Source: 2, Target: 0},
+		},
+		fs,
+	)
+	graph.resetAcceleration()
+	graph.directionalForce()
+	assert := assert.New(t)
+	assert.Greater(graph.Nodes[0].acc.Y(), 0.0, "source pushed up")
+	assert.Less(graph.Nodes[1].acc.Y(), 0.0, "target pushed down")
+	assert.Zero(graph.Nodes[0].acc.X())
+	assert.Zero(graph.Nodes[2].acc.Y())
+	assert.Zero(graph.Nodes[3].acc.Y())
+}
+
+func TestGraph_clusterForce(t *testing.T) {
+	conf := DefaultForceSimulationConfig
+	conf.ClusterAttraction = 1.0
+	conf.ClusterRepulsion = 1.0
+	fs := NewForceSimulation(conf)
+	fs.temperature = 1.0
+	graph := NewGraph(
+		[]*Node{
+			{Pos: vector.Vector{1, 1}, Cluster: 0}, {Pos: vector.Vector{3, 1}, Cluster: 0},
+			{Pos: vector.Vector{2, 11}, Cluster: 1},
+			{Pos: vector.Vector{7, 7}, Cluster: -1},
+		},
+		[]*Edge{},
+		fs,
+	)
+	graph.resetAcceleration()
+	graph.clusterForce()
+	assert := assert.New(t)
+	assert.Greater(graph.Nodes[0].acc.X(), 0.0, "pulled to the center of its cluster")
+	assert.Less(graph.Nodes[1].acc.X(), 0.0, "pulled to the center of its cluster")
+	assert.Less(graph.Nodes[0].acc.Y(), 0.0, "pushed away from the other cluster")
+	assert.Greater(graph.Nodes[2].acc.Y(), 0.0, "pushed away from the other cluster")
+	assert.Zero(graph.Nodes[2].acc.X(), "alone in its cluster")
+	assert.Equal(vector.Vector{0, 0}, graph.Nodes[3].acc, "no cluster")
+}
+
+func TestForceSimulation_ComputeLayout_clusters(t *testing.T) {
+	// nodes of two clusters start interleaved on a line, without edges
+	spread := func(conf ForceSimulationConfig) float64 {
+		conf.Seed = 1
+		nodes := []*Node{}
+		for i := 0; i < 20; i++ {
+			nodes = append(nodes, &Node{Pos: vector.Vector{float64(100 + 10*i), 100}, Cluster: i % 2})
+		}
+		NewForceSimulation(conf).ComputeLayout(context.Background(), nodes, []*Edge{})
+		// mean distance within clusters relative to the mean distance
+		// between clusters
+		within, between := 0.0, 0.0
+		for i := range nodes {
+			for j := i + 1; j < len(nodes); j++ {
+				dist := nodes[i].Pos.Sub(nodes[j].Pos).Magnitude()
+				if nodes[i].Cluster == nodes[j].Cluster {
+					within += dist
+				} else {
+					between += dist
+				}
+			}
+		}
+		return (within / 90) / (between / 100)
+	}
+	conf := DefaultForceSimulationConfig
+	conf.FrameTime = 1.0
+	withoutClusters := spread(conf)
+	conf.ClusterAttraction = 0.5
+	conf.ClusterRepulsion = 1.0
+	assert.Less(t, spread(conf), 0.5*withoutClusters)
+}
+
 func TestForceSimulation_calculateRepulsionForce(t *testing.T) {
 	// conf := ForceSimulationConfig{RepulsionMultiplier: 10.0}
 	fs := NewForceSimulation(DefaultForceSimulationConfig)
@@ -126,3 +395,122 @@ func BenchmarkForceSimulation_ComputeLayout(b *testing.B) {
 		b.StopTimer()
 	}
 }
+
+// BenchmarkGraph_ApplyForce measures a single tick of the simulation
+func BenchmarkGraph_ApplyForce(b *testing.B) {
+	conf := DefaultForceSimulationConfig
+	conf.Seed = 1
+	fs := NewForceSimulation(conf)
+	fs.temperature = conf.AlphaInit
+	rnd := rand.New(rand.NewSource(1))
+	nodes, edges := make([]*Node, 1000), []*Edge{}
+	for i := range nodes {
+		nodes[i] = &Node{}
+		if i > 0 {
+			edges = append(edges, &Edge{Source: i, Target: rnd.Intn(i)})
+		}
+	}
+	graph := NewGraph(nodes, edges, fs)
+	tree := fs.newTree()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.ApplyForce(conf.FrameTime, tree)
+	}
+}
+
+func TestForceSimulationConfig_Validate(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Conf   ForceSimulationConfig
+		ExpErr string
+	}{
+		{Name: "zero values use defaults", Conf: ForceSimulationConfig{}},
+		{Name: "defaults", Conf: DefaultForceSimulationConfig},
+		{Name: "negative width", Conf: ForceSimulationConfig{Rect: Rect{Width: -1}}, ExpErr: "Rect.Width"},
+		{Name: "NaN theta", Conf: ForceSimulationConfig{Theta: math.NaN()}, ExpErr: "Theta"},
+		{Name: "infinite alpha", Conf: ForceSimulationConfig{AlphaInit: math.Inf(1)}, ExpErr: "AlphaInit"},
+		{Name: "alpha decay > 1", Conf: ForceSimulationConfig{AlphaDecay: 1.5}, ExpErr: "AlphaDecay"},
+		{Name: "velocity decay < 0", Conf: ForceSimulationConfig{VelocityDecay: -0.1}, ExpErr: "VelocityDecay"},
+		{Name: "4D", Conf: ForceSimulationConfig{Dimensions: 4}, ExpErr: "Dimensions"},
+		{Name: "unknown initial layout", Conf: ForceSimulationConfig{InitialLayout: 42}, ExpErr: "InitialLayout"},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			err := test.Conf.Validate()
+			if test.ExpErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.ExpErr)
+			}
+		})
+	}
+}
+
+func TestInitialLayout_UnmarshalText(t *testing.T) {
+	assert := assert.New(t)
+	var l InitialLayout
+	assert.NoError(l.UnmarshalText([]byte("sphere")))
+	assert.Equal(InitialLayoutSphere, l)
+	assert.NoError(l.UnmarshalText([]byte("circle")))
+	assert.Equal(InitialLayoutCircle, l)
+	assert.Error(l.UnmarshalText([]byte("spiral")))
+	assert.Equal(InitialLayoutCircle, l, "unchanged on error")
+}
+
+func TestForceSimulation_ApplyConfig_defaults(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{})
+	conf := fs.Config()
+	assert := assert.New(t)
+	assert.Equal(DefaultForceSimulationConfig.VelocityDecay, conf.VelocityDecay)
+	assert.Equal(DefaultForceSimulationConfig.Theta, conf.Theta)
+	assert.Equal(DefaultForceSimulationConfig.TreeCapacity, conf.TreeCapacity)
+	assert.False(conf.DisableBarnesHut)
+}
+
+func TestForceSimulation_Seed_reproducible(t *testing.T) {
+	newGraph := func() ([]*Node, []*Edge) {
+		rnd := rand.New(rand.NewSource(42))
+		nodes := make([]*Node, 100)
+		for i := range nodes {
+			nodes[i] = &Node{}
+		}
+		edges := []*Edge{}
+		for i := 1; i < len(nodes); i++ {
+			edges = append(edges, &Edge{Source: i, Target: rnd.Intn(i)})
+		}
+		return nodes, edges
+	}
+	run := func(seed int64, parallelization, dimensions int) []vector.Vector {
+		conf := DefaultForceSimulationConfig
+		conf.Seed = seed
+		conf.Parallelization = parallelization
+		conf.Dimensions = dimensions
+		fs := NewForceSimulation(conf)
+		nodes, edges := newGraph()
+		fs.ComputeLayout(context.Background(), nodes, edges)
+		positions := []vector.Vector{}
+		for _, node := range nodes {
+			positions = append(positions, node.Pos)
+		}
+		return positions
+	}
+	assert := assert.New(t)
+	for _, dimensions := range []int{2, 3} {
+		expected := run(7, 0, dimensions)
+		assert.Equal(expected, run(7, 0, dimensions), "%dD sequential", dimensions)
+		assert.Equal(expected, run(7, 8, dimensions), "%dD parallel", dimensions)
+		assert.Equal(expected, run(7, 3, dimensions), "%dD uneven split", dimensions)
+		assert.NotEqual(expected, run(8, 8, dimensions), "%dD other seed", dimensions)
+	}
+}
+
+func TestForceSimulation_Config_seed(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{Seed: 3})
+	first := fs.Config().RandomFloat
+	assert := assert.New(t)
+	assert.Nil(first, "derived from seed")
+	a := fs.conf.RandomFloat()
+	fs.ApplyConfig(fs.Config())
+	assert.Equal(a, fs.conf.RandomFloat(), "re-applying the config starts over")
+}
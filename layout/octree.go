@@ -0,0 +1,158 @@
+package layout
+
+import (
+	"github.com/quartercastle/vector"
+)
+
+// Octree is the 3D counterpart of QuadTree
+type Octree struct {
+	Center          vector.Vector
+	TotalMass       float64
+	Region          Rect
+	Nodes           []*Node
+	Children        [8]*Octree
+	config          *QuadTreeConfig
+	forceSimulation *ForceSimulation
+}
+
+func NewOctree(config *QuadTreeConfig, forceSimulation *ForceSimulation, boundary Rect) *Octree {
+	ot := new(Octree)
+	ot.config = config
+	if config == nil {
+		ot.config = &QUADTREE_DEFAULT_CONFIG
+	}
+	if ot.config.CapacityOfEachBlock == 0 {
+		ot.config.CapacityOfEachBlock = QUADTREE_DEFAULT_CONFIG.CapacityOfEachBlock
+	}
+	if ot.config.MaxDepth == 0 {
+		ot.config.MaxDepth = QUADTREE_DEFAULT_CONFIG.MaxDepth
+	}
+	ot.Region = boundary
+	ot.Nodes = make([]*Node, 0, ot.config.CapacityOfEachBlock)
+	ot.Center = vector.Vector{0, 0, 0}
+	ot.forceSimulation = forceSimulation
+	return ot
+}
+
+func (ot *Octree) Clear() {
+	ot.Center = vector.Vector{0, 0, 0}
+	ot.Nodes = nil
+	for i := range ot.Children {
+		ot.Children[i] = nil
+	}
+	ot.TotalMass = 0
+}
+
+func (ot *Octree) Fit(nodes []*Node) {
+	if region, ok := boundingSquare(nodes, 3); ok {
+		ot.Region = region
+	}
+}
+
+// Insert adds node to the tree, returns false if node is outside of the
+// boundary of the tree
+func (ot *Octree) Insert(node *Node) bool {
+	if !ot.Region.Contains(node.Pos) {
+		return false
+	}
+	ot.insert(node, 0)
+	return true
+}
+
+func (ot *Octree) insert(node *Node, depth int) {
+	if ot.Children[0] == nil && (len(ot.Nodes) < ot.config.CapacityOfEachBlock || depth >= ot.config.MaxDepth) {
+		ot.Nodes = append(ot.Nodes, node)
+		return
+	}
+	if ot.Children[0] == nil {
+		ot.subdivide(depth)
+	}
+	ot.Children[ot.childIndex(node.Pos)].insert(node, depth+1)
+}
+
+// childIndex returns the child containing pos, see QuadTree.childIndex
+func (ot *Octree) childIndex(pos vector.Vector) int {
+	idx := 0
+	if pos.X() >= ot.Region.X+ot.Region.Width/2 {
+		idx += 1
+	}
+	if pos.Y() >= ot.Region.Y+ot.Region.Height/2 {
+		idx += 2
+	}
+	if pos.Z() >= ot.Region.Z+ot.Region.Depth/2 {
+		idx += 4
+	}
+	return idx
+}
+
+func (ot *Octree) subdivide(depth int) {
+	half := Rect{Width: ot.Region.Width / 2, Height: ot.Region.Height / 2, Depth: ot.Region.Depth / 2}
+	for i := range ot.Children {
+		region := half
+		region.X = ot.Region.X + float64(i&1)*half.Width
+		region.Y = ot.Region.Y + float64((i>>1)&1)*half.Height
+		region.Z = ot.Region.Z + float64((i>>2)&1)*half.Depth
+		ot.Children[i] = NewOctree(ot.config, ot.forceSimulation, region)
+	}
+	for _, node := range ot.Nodes {
+		ot.Children[ot.childIndex(node.Pos)].insert(node, depth+1)
+	}
+}
+
+// CalculateMasses computes the total mass and center of mass of each tree
+// node, see QuadTree.CalculateMasses
+func (ot *Octree) CalculateMasses() {
+	if ot.Children[0] == nil {
+		// Leaf
+		for _, node := range ot.Nodes {
+			ot.TotalMass += node.degree
+			ot.Center = ot.Center.Add(node.Pos.Scale(node.degree))
+		}
+	} else {
+		for _, child := range ot.Children {
+			child.CalculateMasses()
+			if child.TotalMass == 0 {
+				continue
+			}
+			ot.TotalMass += child.TotalMass
+			ot.Center = ot.Center.Add(child.Center.Scale(child.TotalMass))
+		}
+	}
+	if ot.TotalMass > 0 {
+		ot.Center = ot.Center.Scale(1 / ot.TotalMass)
+	}
+}
+
+// CalculateForce calculates the repulsion force acting on a node, see
+// QuadTree.CalculateForce
+func (ot *Octree) CalculateForce(totalForce, tmp *vector.Vector, node *Node, theta float64, parallelize int) {
+	if ot.Children[0] == nil {
+		for _, other := range ot.Nodes {
+			if node == other {
+				continue
+			}
+			ot.forceSimulation.calculateRepulsionForce(totalForce, tmp, node, other)
+		}
+		return
+	}
+	d := node.Pos.Sub(ot.Center).Magnitude()
+	s := ot.Region.Width
+	if (s / d) < theta {
+		ot.forceSimulation.calculateRepulsionForce(totalForce, tmp, node, ot)
+		return
+	}
+	for _, child := range ot.Children {
+		if child != nil && child.TotalMass > 0 {
+			child.CalculateForce(totalForce, tmp, node, theta, 0)
+		}
+	}
+}
+
+// size() is used to compute repulsion force between Octrees
+func (ot *Octree) size() float64 {
+	return ot.TotalMass
+}
+
+func (ot *Octree) position() vector.Vector {
+	return ot.Center
+}
@@ -0,0 +1,100 @@
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"github.com/quartercastle/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOctree_Insert(t *testing.T) {
+	rect := Rect{X: 0, Y: 0, Z: 0, Width: 10, Height: 10, Depth: 10}
+	fs := NewForceSimulation(ForceSimulationConfig{Rect: rect, Dimensions: 3})
+	ot := NewOctree(&QuadTreeConfig{CapacityOfEachBlock: 1}, fs, rect)
+	near, far := &Node{Pos: vector.Vector{1, 1, 1}}, &Node{Pos: vector.Vector{9, 9, 9}}
+	assert := assert.New(t)
+	assert.True(ot.Insert(near))
+	assert.True(ot.Insert(far))
+	assert.False(ot.Insert(&Node{Pos: vector.Vector{1, 1, 11}}), "outside of region")
+	assert.Equal([]*Node{near}, ot.Nodes)
+	if assert.NotNil(ot.Children[0]) {
+		assert.Equal([]*Node{near}, ot.Children[0].Nodes)
+		assert.Equal([]*Node{far}, ot.Children[7].Nodes)
+		assert.Equal(Rect{X: 5, Y: 5, Z: 5, Width: 5, Height: 5, Depth: 5}, ot.Children[7].Region)
+	}
+}
+
+func TestOctree_CalculateForce(t *testing.T) {
+	conf := ForceSimulationConfig{Rect: Rect{X: 0, Y: 0, Z: 0, Width: 10, Height: 10, Depth: 10}, Dimensions: 3}
+	fs := NewForceSimulation(conf)
+	ot := NewOctree(&QuadTreeConfig{CapacityOfEachBlock: 1}, fs, conf.Rect)
+	graph := NewGraph(
+		[]*Node{
+			{Name: "A", Pos: vector.Vector{2.5, 2.5, 2.5}},
+			{Name: "B", Pos: vector.Vector{2.5, 2.5, 7.5}},
+		},
+		[]*Edge{},
+		fs,
+	)
+	for _, n := range graph.Nodes {
+		ot.Insert(n)
+	}
+	ot.CalculateMasses()
+	force := vector.Vector{0, 0, 0}
+	tmp := vector.Vector{0, 0, 0}
+	ot.CalculateForce(&force, &tmp, graph.Nodes[0], 0.1, 0)
+	assert := assert.New(t)
+	assert.Zero(force.X())
+	assert.Zero(force.Y())
+	assert.Less(force.Z(), 0.0, "pushed away from B along the z-axis")
+}
+
+func TestOctree_Insert_coincidentNodesAndFit(t *testing.T) {
+	rect := Rect{X: 0, Y: 0, Z: 0, Width: 10, Height: 10, Depth: 10}
+	fs := NewForceSimulation(ForceSimulationConfig{Rect: rect, Dimensions: 3})
+	ot := NewOctree(&QuadTreeConfig{CapacityOfEachBlock: 2, MaxDepth: 6}, fs, rect)
+	nodes := []*Node{}
+	for i := 0; i < 50; i++ {
+		nodes = append(nodes, &Node{Pos: vector.Vector{1, 1, -500}, degree: 1})
+	}
+	assert := assert.New(t)
+	assert.False(ot.Insert(nodes[0]))
+	ot.Fit(nodes)
+	for _, node := range nodes {
+		assert.True(ot.Insert(node))
+	}
+	ot.CalculateMasses()
+	assert.Equal(50.0, ot.TotalMass)
+}
+
+func TestOctree_CalculateMasses_emptyOctants(t *testing.T) {
+	conf := ForceSimulationConfig{Rect: Rect{X: 0, Y: 0, Z: 0, Width: 10, Height: 10, Depth: 10}, Dimensions: 3}
+	fs := NewForceSimulation(conf)
+	ot := NewOctree(&QuadTreeConfig{CapacityOfEachBlock: 1}, fs, conf.Rect)
+	nodes := []*Node{
+		{Name: "A", Pos: vector.Vector{1, 1, 1}, degree: 1},
+		{Name: "B", Pos: vector.Vector{8, 8, 8}, degree: 1},
+		{Name: "C", Pos: vector.Vector{9, 9, 9}, degree: 1},
+	}
+	for _, n := range nodes {
+		ot.Insert(n)
+	}
+	ot.CalculateMasses()
+	assert := assert.New(t)
+	assert.Equal(3.0, ot.TotalMass)
+	assert.Equal(vector.Vector{6, 6, 6}, ot.Center)
+	assert.Zero(ot.Children[1].TotalMass)
+	assert.Equal(vector.Vector{0, 0, 0}, ot.Children[1].Center, "empty octant")
+	assert.Equal(vector.Vector{8.5, 8.5, 8.5}, ot.Children[7].Center, "B and C, despite empty sub-octants")
+
+	exact, approximated := vector.Vector{0, 0, 0}, vector.Vector{0, 0, 0}
+	tmp := vector.Vector{0, 0, 0}
+	ot.CalculateForce(&exact, &tmp, nodes[0], 0, 0)
+	ot.CalculateForce(&approximated, &tmp, nodes[0], 0.5, 0)
+	for i := range approximated {
+		assert.False(math.IsNaN(approximated[i]) || math.IsInf(approximated[i], 0))
+	}
+	assert.NotEqual(exact, approximated, "B and C are approximated by the center of their octant")
+	assert.InDelta(exact.X(), approximated.X(), 0.1*math.Abs(exact.X()))
+}
@@ -2,14 +2,31 @@
 package layout
 
 import (
+	"math"
+
 	"github.com/quartercastle/vector"
 )
 
 type QuadTreeConfig struct {
 	CapacityOfEachBlock int
+	// MaxDepth limits the subdivision, leafs at this depth hold any number of
+	// nodes, e.g. nodes at the exact same position
+	MaxDepth int
 }
 
-var QUADTREE_DEFAULT_CONFIG = QuadTreeConfig{CapacityOfEachBlock: 10}
+var QUADTREE_DEFAULT_CONFIG = QuadTreeConfig{CapacityOfEachBlock: 10, MaxDepth: 24}
+
+// BarnesHutTree partitions the simulation space for the Barnes-Hut
+// approximation of the repulsion forces, see QuadTree (2D) and Octree (3D).
+type BarnesHutTree interface {
+	Clear()
+	// Fit sets the boundary to the bounding box of nodes, so that all of
+	// them can be inserted
+	Fit(nodes []*Node)
+	Insert(node *Node) bool
+	CalculateMasses()
+	CalculateForce(totalForce, tmp *vector.Vector, node *Node, theta float64, parallelize int)
+}
 
 type QuadTree struct {
 	Center          vector.Vector
@@ -21,11 +38,20 @@ type QuadTree struct {
 	forceSimulation *ForceSimulation
 }
 
+// Rect is a rectangle, or a box in 3D if Depth is set
 type Rect struct {
-	X, Y, Width, Height float64
+	X      float64 `env:"X"`
+	Y      float64 `env:"Y"`
+	Width  float64 `env:"WIDTH"`
+	Height float64 `env:"HEIGHT"`
+	Z      float64 `env:"Z"`
+	Depth  float64 `env:"DEPTH"`
 }
 
 func (r *Rect) Center() vector.Vector {
+	if r.Depth != 0 {
+		return vector.Vector{r.X + r.Width/2, r.Y + r.Height/2, r.Z + r.Depth/2}
+	}
 	return vector.Vector{
 		r.Width / 2,
 		r.Height / 2,
@@ -34,18 +60,24 @@ func (r *Rect) Center() vector.Vector {
 
 func (r *Rect) Contains(pos vector.Vector) bool {
 	contains := pos.X() >= r.X && pos.X() <= r.X+r.Width && pos.Y() >= r.Y && pos.Y() <= r.Y+r.Height
+	if r.Depth != 0 && len(pos) > 2 {
+		contains = contains && pos.Z() >= r.Z && pos.Z() <= r.Z+r.Depth
+	}
 	return contains
 }
 
 func NewQuadTree(config *QuadTreeConfig, forceSimulation *ForceSimulation, boundary Rect) *QuadTree {
 	qt := new(QuadTree)
 	qt.config = config
-	if qt.config.CapacityOfEachBlock == 0 {
-		qt.config.CapacityOfEachBlock = 10
-	}
 	if config == nil {
 		qt.config = &QUADTREE_DEFAULT_CONFIG
 	}
+	if qt.config.CapacityOfEachBlock == 0 {
+		qt.config.CapacityOfEachBlock = QUADTREE_DEFAULT_CONFIG.CapacityOfEachBlock
+	}
+	if qt.config.MaxDepth == 0 {
+		qt.config.MaxDepth = QUADTREE_DEFAULT_CONFIG.MaxDepth
+	}
 	qt.Region = boundary
 	qt.Nodes = make([]*Node, 0, qt.config.CapacityOfEachBlock)
 	qt.Children = [4]*QuadTree{nil, nil, nil, nil}
@@ -64,32 +96,82 @@ func (qt *QuadTree) Clear() {
 	qt.TotalMass = 0
 }
 
-func (qt *QuadTree) Insert(node *Node) bool {
-	return qt.insert(node, 0)
+func (qt *QuadTree) Fit(nodes []*Node) {
+	if region, ok := boundingSquare(nodes, 2); ok {
+		qt.Region = region
+	}
+}
+
+// boundingSquare returns the smallest square (cube in 3D) containing all
+// nodes with finite positions, with a small margin against rounding errors
+func boundingSquare(nodes []*Node, dimensions int) (Rect, bool) {
+	lo := vector.Vector{math.Inf(+1), math.Inf(+1), math.Inf(+1)}[:dimensions]
+	hi := vector.Vector{math.Inf(-1), math.Inf(-1), math.Inf(-1)}[:dimensions]
+	found := false
+nodes:
+	for _, node := range nodes {
+		if len(node.Pos) < dimensions {
+			continue
+		}
+		for i := 0; i < dimensions; i++ {
+			if math.IsNaN(node.Pos[i]) || math.IsInf(node.Pos[i], 0) {
+				continue nodes
+			}
+		}
+		for i := 0; i < dimensions; i++ {
+			lo[i] = math.Min(lo[i], node.Pos[i])
+			hi[i] = math.Max(hi[i], node.Pos[i])
+		}
+		found = true
+	}
+	if !found {
+		return Rect{}, false
+	}
+	size := 0.0
+	for i := 0; i < dimensions; i++ {
+		size = math.Max(size, hi[i]-lo[i])
+	}
+	margin := math.Max(size*1e-3, 1e-3)
+	size += 2 * margin
+	region := Rect{X: lo[0] - margin, Y: lo[1] - margin, Width: size, Height: size}
+	if dimensions == 3 {
+		region.Z, region.Depth = lo[2]-margin, size
+	}
+	return region, true
 }
 
-func (qt *QuadTree) insert(node *Node, depth int) bool {
-	// FIXME(skep): if more than qt.forceSimulation.config.CapacityOfEachBlock
-	// nodes are at the exact same location, then this is an inifite loop!
-	// -> should wiggle those nodes a bit to divide them into different regions!
+// Insert adds node to the tree, returns false if node is outside of the
+// boundary of the tree
+func (qt *QuadTree) Insert(node *Node) bool {
 	if !qt.Region.Contains(node.Pos) {
 		return false
 	}
+	qt.insert(node, 0)
+	return true
+}
 
-	if len(qt.Nodes) < qt.config.CapacityOfEachBlock {
+func (qt *QuadTree) insert(node *Node, depth int) {
+	if qt.Children[0] == nil && (len(qt.Nodes) < qt.config.CapacityOfEachBlock || depth >= qt.config.MaxDepth) {
 		qt.Nodes = append(qt.Nodes, node)
-		return true
-	} else {
-		if qt.Children[0] == nil {
-			qt.subdivide(depth)
-		}
-		for _, child := range qt.Children {
-			if child.insert(node, depth+1) {
-				return true
-			}
-		}
+		return
 	}
-	return false
+	if qt.Children[0] == nil {
+		qt.subdivide(depth)
+	}
+	qt.Children[qt.childIndex(node.Pos)].insert(node, depth+1)
+}
+
+// childIndex returns the child containing pos, positions on the border
+// between children belong to the upper/right one
+func (qt *QuadTree) childIndex(pos vector.Vector) int {
+	idx := 0
+	if pos.X() >= qt.Region.X+qt.Region.Width/2 {
+		idx += 1
+	}
+	if pos.Y() >= qt.Region.Y+qt.Region.Height/2 {
+		idx += 2
+	}
+	return idx
 }
 
 func (qt *QuadTree) subdivide(depth int) {
@@ -105,15 +187,12 @@ func (qt *QuadTree) subdivide(depth int) {
 	qt.Children[3] = NewQuadTree(qt.config, qt.forceSimulation, Rect{X: midX, Y: midY, Width: halfWidth, Height: halfHeight})               // Bottom Right
 
 	for _, node := range qt.Nodes {
-		for _, child := range qt.Children {
-			if child.Region.Contains(node.Pos) {
-				child.insert(node, depth+1)
-				break
-			}
-		}
+		qt.Children[qt.childIndex(node.Pos)].insert(node, depth+1)
 	}
 }
 
+// CalculateMasses sums up the degrees of the nodes below each tree node and
+// computes their center of mass, empty tree nodes keep a zero center
 func (qt *QuadTree) CalculateMasses() {
 	if qt.Children[0] == nil {
 		// Leaf
@@ -121,14 +200,18 @@ func (qt *QuadTree) CalculateMasses() {
 			qt.TotalMass += node.degree
 			qt.Center = qt.Center.Add(node.Pos.Scale(node.degree))
 		}
-		qt.Center = qt.Center.Scale(1 / qt.TotalMass)
 	} else {
 		// Process children
 		for _, child := range qt.Children {
 			child.CalculateMasses()
+			if child.TotalMass == 0 {
+				continue
+			}
 			qt.TotalMass += child.TotalMass
 			qt.Center = qt.Center.Add(child.Center.Scale(child.TotalMass))
 		}
+	}
+	if qt.TotalMass > 0 {
 		qt.Center = qt.Center.Scale(1 / qt.TotalMass)
 	}
 }
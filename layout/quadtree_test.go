@@ -47,13 +47,36 @@ func TestQUandTree_CalculateMasses(t *testing.T) {
 	}
 	qt.CalculateMasses()
 	assert := assert.New(t)
-	assert.True(math.IsNaN(qt.Center.X()), "top level node has no meaningful center")
-	assert.True(math.IsNaN(qt.Center.Y()), "top level node has no meaningful center")
+	assert.False(math.IsNaN(qt.Center.X()) || math.IsNaN(qt.Center.Y()), "empty quadrant is skipped")
+	assert.Equal(qt.Children[0].TotalMass+qt.Children[1].TotalMass+qt.Children[2].TotalMass, qt.TotalMass)
 	assert.Equal(vector.Vector{2.5, 2.5}, qt.Children[0].Center)
 	assert.Equal(vector.Vector{7.5, 2.5}, qt.Children[1].Center)
 	assert.Equal(vector.Vector{2.5, 7.5}, qt.Children[2].Center)
-	assert.True(math.IsNaN(qt.Children[3].Center.X()), "all 3 nodes already in first 3 buckets")
-	assert.True(math.IsNaN(qt.Children[3].Center.Y()), "all 3 nodes already in first 3 buckets")
+	assert.Zero(qt.Children[3].TotalMass, "all 3 nodes already in first 3 buckets")
+	assert.Equal(vector.Vector{0, 0}, qt.Children[3].Center, "all 3 nodes already in first 3 buckets")
+}
+
+func TestQUandTree_CalculateMasses_approximatesBehindEmptyQuadrants(t *testing.T) {
+	rect := Rect{X: 0.0, Y: 0.0, Width: 10.0, Height: 10.0}
+	fs := NewForceSimulation(ForceSimulationConfig{Rect: rect})
+	qt := NewQuadTree(&QuadTreeConfig{CapacityOfEachBlock: 1}, fs, rect)
+	nodes := []*Node{
+		{Name: "A", Pos: vector.Vector{1, 1}, degree: 1},
+		{Name: "B", Pos: vector.Vector{8, 8}, degree: 1},
+		{Name: "C", Pos: vector.Vector{9, 9}, degree: 1},
+	}
+	for _, n := range nodes {
+		qt.Insert(n)
+	}
+	qt.CalculateMasses()
+	assert := assert.New(t)
+	assert.Equal(vector.Vector{6, 6}, qt.Center)
+	assert.Equal(vector.Vector{8.5, 8.5}, qt.Children[3].Center, "B and C, despite empty sub-quadrants")
+	exact, approximated, tmp := vector.Vector{0, 0}, vector.Vector{0, 0}, vector.Vector{0, 0}
+	qt.CalculateForce(&exact, &tmp, nodes[0], 0, 0)
+	qt.CalculateForce(&approximated, &tmp, nodes[0], 0.5, 0)
+	assert.False(math.IsNaN(approximated.X()) || math.IsNaN(approximated.Y()))
+	assert.NotEqual(exact, approximated, "B and C are approximated by the center of their quadrant")
 }
 
 func TestQUandTree_CalculateForce(t *testing.T) {
@@ -86,3 +109,56 @@ func TestRect_Center(t *testing.T) {
 	r := Rect{X: 1, Y: 1, Width: 2, Height: 10}
 	assert.Equal(t, vector.Vector{2.0, 6.0}, r.Center())
 }
+
+// depth returns the maximum depth of the tree
+func (qt *QuadTree) depth() int {
+	if qt.Children[0] == nil {
+		return 0
+	}
+	max := 0
+	for _, child := range qt.Children {
+		if d := child.depth(); d > max {
+			max = d
+		}
+	}
+	return max + 1
+}
+
+func TestQuadTree_Insert_coincidentNodes(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{})
+	qt := NewQuadTree(&QuadTreeConfig{CapacityOfEachBlock: 2, MaxDepth: 8}, fs, Rect{X: 0, Y: 0, Width: 10.0, Height: 10.0})
+	assert := assert.New(t)
+	for i := 0; i < 100; i++ {
+		assert.True(qt.Insert(&Node{Pos: vector.Vector{1.0, 1.0}, degree: 1}))
+	}
+	assert.Equal(8, qt.depth(), "subdivision stops at MaxDepth")
+	qt.CalculateMasses()
+	assert.Equal(100.0, qt.TotalMass)
+}
+
+func TestQuadTree_Insert_outsideOfRegion(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{})
+	qt := NewQuadTree(&QuadTreeConfig{CapacityOfEachBlock: 2}, fs, Rect{X: 0, Y: 0, Width: 10.0, Height: 10.0})
+	nodes := []*Node{
+		{Pos: vector.Vector{-1000, 5}}, {Pos: vector.Vector{5, 5000}}, {Pos: vector.Vector{5, 5}},
+		{Pos: vector.Vector{math.NaN(), 5}},
+	}
+	assert := assert.New(t)
+	assert.False(qt.Insert(nodes[0]))
+	qt.Fit(nodes)
+	for _, node := range nodes[:3] {
+		assert.True(qt.Insert(node), "%v not inside %v", node.Pos, qt.Region)
+	}
+	assert.False(qt.Insert(nodes[3]), "invalid position")
+	assert.Equal(qt.Region.Width, qt.Region.Height, "region is a square")
+}
+
+func TestQuadTree_Fit_singleNode(t *testing.T) {
+	fs := NewForceSimulation(ForceSimulationConfig{})
+	qt := NewQuadTree(&QuadTreeConfig{}, fs, Rect{X: 0, Y: 0, Width: 10.0, Height: 10.0})
+	node := &Node{Pos: vector.Vector{100, 100}}
+	qt.Fit([]*Node{node})
+	assert := assert.New(t)
+	assert.Greater(qt.Region.Width, 0.0)
+	assert.True(qt.Insert(node))
+}
@@ -0,0 +1,109 @@
+package layout
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Quality describes how readable a layout is.
+type Quality struct {
+	// EdgeLengthStress is the mean squared relative deviation of the edge
+	// lengths from their ideal length (Edge.Length, or the mean length of all
+	// edges if unset). Zero means all edges have their ideal length.
+	EdgeLengthStress float64
+	// EdgeCrossings is the number of crossing edges in the x-y plane. It is
+	// estimated by sampling for more than MaxEdgesForExactCrossings edges.
+	EdgeCrossings float64
+}
+
+var (
+	MaxEdgesForExactCrossings = 1000
+	CrossingSamples           = 100000
+)
+
+// MeasureQuality computes the Quality of the layout of nodes. randomFloat is
+// used for sampling edge pairs, rand.Float64 if nil.
+func MeasureQuality(nodes []*Node, edges []*Edge, randomFloat func() float64) Quality {
+	if randomFloat == nil {
+		randomFloat = rand.Float64
+	}
+	return Quality{
+		EdgeLengthStress: edgeLengthStress(nodes, edges),
+		EdgeCrossings:    edgeCrossings(nodes, edges, randomFloat),
+	}
+}
+
+func edgeLengthStress(nodes []*Node, edges []*Edge) float64 {
+	lengths := make([]float64, 0, len(edges))
+	mean := 0.0
+	for _, edge := range edges {
+		length := nodes[edge.Source].Pos.Sub(nodes[edge.Target].Pos).Magnitude()
+		lengths = append(lengths, length)
+		mean += length
+	}
+	if len(lengths) == 0 {
+		return 0
+	}
+	mean /= float64(len(lengths))
+	stress := 0.0
+	for i, edge := range edges {
+		ideal := edge.Length
+		if ideal <= 0 {
+			ideal = mean
+		}
+		if ideal <= 0 {
+			continue
+		}
+		deviation := (lengths[i] - ideal) / ideal
+		stress += deviation * deviation
+	}
+	return stress / float64(len(lengths))
+}
+
+func edgeCrossings(nodes []*Node, edges []*Edge, randomFloat func() float64) float64 {
+	m := len(edges)
+	if m <= MaxEdgesForExactCrossings {
+		crossings := 0.0
+		for i := 0; i < m; i++ {
+			for j := i + 1; j < m; j++ {
+				if edgesCross(nodes, edges[i], edges[j]) {
+					crossings++
+				}
+			}
+		}
+		return crossings
+	}
+	hits := 0
+	for k := 0; k < CrossingSamples; k++ {
+		i, j := int(randomFloat()*float64(m)), int(randomFloat()*float64(m))
+		if i != j && i < m && j < m && edgesCross(nodes, edges[i], edges[j]) {
+			hits++
+		}
+	}
+	// each unordered pair is sampled with probability 2/m²
+	return float64(hits) / float64(CrossingSamples) * float64(m) * float64(m) / 2
+}
+
+// edgesCross reports whether the edges intersect in the x-y plane, edges
+// sharing a node do not cross
+func edgesCross(nodes []*Node, a, b *Edge) bool {
+	if a.Source == b.Source || a.Source == b.Target || a.Target == b.Source || a.Target == b.Target {
+		return false
+	}
+	p1, p2 := nodes[a.Source].Pos, nodes[a.Target].Pos
+	p3, p4 := nodes[b.Source].Pos, nodes[b.Target].Pos
+	d1 := orientation(p3, p4, p1)
+	d2 := orientation(p3, p4, p2)
+	d3 := orientation(p1, p2, p3)
+	d4 := orientation(p1, p2, p4)
+	return d1*d2 < 0 && d3*d4 < 0
+}
+
+// orientation is the sign of the cross product (b-a)×(c-a) in the x-y plane
+func orientation(a, b, c []float64) float64 {
+	cross := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	if math.Abs(cross) < 1e-12 {
+		return 0
+	}
+	return math.Copysign(1, cross)
+}
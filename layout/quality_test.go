@@ -0,0 +1,67 @@
+package layout
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/quartercastle/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasureQuality(t *testing.T) {
+	// square with both diagonals: the diagonals cross
+	nodes := []*Node{
+		{Pos: vector.Vector{0, 0}}, {Pos: vector.Vector{10, 0}}, {Pos: vector.Vector{10, 10}}, {Pos: vector.Vector{0, 10}},
+	}
+	for _, test := range []struct {
+		Name        string
+		Edges       []*Edge
+		ExpStress   float64
+		ExpCrossing float64
+	}{
+		{Name: "no edges"},
+		{
+			Name:  "square without diagonals",
+			Edges: []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 2, Target: 3}, {Source: 3, Target: 0}},
+		},
+		{
+			Name:        "diagonals",
+			Edges:       []*Edge{{Source: 0, Target: 2}, {Source: 1, Target: 3}},
+			ExpCrossing: 1,
+		},
+		{
+			Name:      "ideal lengths",
+			Edges:     []*Edge{{Source: 0, Target: 1, Length: 20}, {Source: 1, Target: 2, Length: 10}},
+			ExpStress: (0.25 + 0) / 2,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			q := MeasureQuality(nodes, test.Edges, nil)
+			assert.InDelta(t, test.ExpStress, q.EdgeLengthStress, 1e-9)
+			assert.Equal(t, test.ExpCrossing, q.EdgeCrossings)
+		})
+	}
+}
+
+func TestMeasureQuality_sampledCrossings(t *testing.T) {
+	// n parallel vertical edges crossed by n horizontal edges: n² crossings
+	n := 40
+	nodes, edges := []*Node{}, []*Edge{}
+	for i := 0; i < n; i++ {
+		x := float64(i + 1)
+		nodes = append(nodes, &Node{Pos: vector.Vector{x, 0}}, &Node{Pos: vector.Vector{x, float64(n + 1)}})
+		edges = append(edges, &Edge{Source: len(nodes) - 2, Target: len(nodes) - 1})
+		nodes = append(nodes, &Node{Pos: vector.Vector{0, x}}, &Node{Pos: vector.Vector{float64(n + 1), x}})
+		edges = append(edges, &Edge{Source: len(nodes) - 2, Target: len(nodes) - 1})
+	}
+	exact := MeasureQuality(nodes, edges, nil).EdgeCrossings
+	assert := assert.New(t)
+	assert.Equal(float64(n*n), exact)
+
+	maxExact := MaxEdgesForExactCrossings
+	MaxEdgesForExactCrossings = 10
+	defer func() { MaxEdgesForExactCrossings = maxExact }()
+	rnd := rand.New(rand.NewSource(1))
+	sampled := MeasureQuality(nodes, edges, rnd.Float64).EdgeCrossings
+	assert.InEpsilon(exact, sampled, 0.05)
+}
@@ -0,0 +1,122 @@
+package layout
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/quartercastle/vector"
+)
+
+type RadialConfig struct {
+	// distance between two rings
+	RingSpacing float64
+}
+
+var DefaultRadialConfig = RadialConfig{
+	RingSpacing: 150.0,
+}
+
+// Radial computes a radial tree layout around root: nodes are placed on
+// rings by their (undirected) distance to root, and each subtree of the
+// breadth-first spanning tree gets a wedge proportional to its number of
+// leafs. Nodes not connected to root are placed on rings beyond the
+// outermost one. If root is invalid, the node with the most edges is used.
+func Radial(ctx context.Context, nodes []*Node, edges []*Edge, root int, conf RadialConfig) Stats {
+	startTime := time.Now()
+	n := len(nodes)
+	if n == 0 {
+		return Stats{}
+	}
+	neighbors := make([][]int, n)
+	for _, edge := range edges {
+		if edge.Source != edge.Target {
+			neighbors[edge.Source] = append(neighbors[edge.Source], edge.Target)
+			neighbors[edge.Target] = append(neighbors[edge.Target], edge.Source)
+		}
+	}
+	if root < 0 || root >= n {
+		root = 0
+		for v := range neighbors {
+			if len(neighbors[v]) > len(neighbors[root]) {
+				root = v
+			}
+		}
+	}
+
+	// spanning tree: nodes of other components hang below root, one ring
+	// further out than the deepest node found so far
+	parent := make([]int, n)
+	depth := make([]int, n)
+	visited := make([]bool, n)
+	bfsOrder := make([]int, 0, n)
+	bfs := func(start, startDepth, startParent int) int {
+		maxDepth := startDepth
+		visited[start] = true
+		parent[start] = startParent
+		depth[start] = startDepth
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			bfsOrder = append(bfsOrder, v)
+			if depth[v] > maxDepth {
+				maxDepth = depth[v]
+			}
+			for _, u := range neighbors[v] {
+				if !visited[u] {
+					visited[u] = true
+					parent[u] = v
+					depth[u] = depth[v] + 1
+					queue = append(queue, u)
+				}
+			}
+		}
+		return maxDepth
+	}
+	maxDepth := bfs(root, 0, -1)
+	outer := maxDepth + 1
+	for v := 0; v < n; v++ {
+		if !visited[v] {
+			if d := bfs(v, outer, root); d > maxDepth {
+				maxDepth = d
+			}
+		}
+	}
+
+	children := make([][]int, n)
+	for _, v := range bfsOrder {
+		if parent[v] >= 0 {
+			children[parent[v]] = append(children[parent[v]], v)
+		}
+	}
+	leafs := make([]float64, n)
+	for i := len(bfsOrder) - 1; i >= 0; i-- {
+		v := bfsOrder[i]
+		if len(children[v]) == 0 {
+			leafs[v] = 1
+		}
+		if parent[v] >= 0 {
+			leafs[parent[v]] += leafs[v]
+		}
+	}
+
+	start := make([]float64, n)
+	wedge := make([]float64, n)
+	wedge[root] = 2 * math.Pi
+	for _, v := range bfsOrder {
+		if ctx.Err() != nil {
+			break
+		}
+		angle := start[v] + wedge[v]/2
+		radius := float64(depth[v]) * conf.RingSpacing
+		nodes[v].Pos = vector.Vector{radius * math.Cos(angle), radius * math.Sin(angle)}
+		next := start[v]
+		for _, c := range children[v] {
+			start[c] = next
+			wedge[c] = wedge[v] * leafs[c] / leafs[v]
+			next += wedge[c]
+		}
+	}
+	return Stats{Iterations: 1, TotalTime: time.Since(startTime)}
+}
@@ -0,0 +1,31 @@
+package layout
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRadial(t *testing.T) {
+	// 0 - 1 - 2, 0 - 3, 4 - 5 (disconnected)
+	nodes := []*Node{{}, {}, {}, {}, {}, {}}
+	edges := []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 3, Target: 0}, {Source: 4, Target: 5}}
+	conf := RadialConfig{RingSpacing: 10}
+	Radial(context.Background(), nodes, edges, 0, conf)
+	assert := assert.New(t)
+	assert.InDelta(0.0, nodes[0].Pos.Magnitude(), 1e-9)
+	assert.InDelta(10.0, nodes[1].Pos.Magnitude(), 1e-9)
+	assert.InDelta(20.0, nodes[2].Pos.Magnitude(), 1e-9)
+	assert.InDelta(10.0, nodes[3].Pos.Magnitude(), 1e-9)
+	assert.InDelta(30.0, nodes[4].Pos.Magnitude(), 1e-9, "other component beyond the outermost ring")
+	assert.InDelta(40.0, nodes[5].Pos.Magnitude(), 1e-9)
+	assert.NotEqual(nodes[1].Pos, nodes[3].Pos)
+}
+
+func TestRadial_invalidRootUsesHighestDegree(t *testing.T) {
+	nodes := []*Node{{}, {}, {}, {}}
+	edges := []*Edge{{Source: 0, Target: 2}, {Source: 1, Target: 2}, {Source: 3, Target: 2}}
+	Radial(context.Background(), nodes, edges, -1, DefaultRadialConfig)
+	assert.InDelta(t, 0.0, nodes[2].Pos.Magnitude(), 1e-9)
+}
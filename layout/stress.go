@@ -0,0 +1,153 @@
+package layout
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/quartercastle/vector"
+)
+
+type StressConfig struct {
+	// desired distance of two nodes connected by an edge
+	EdgeLength    float64
+	MaxIterations int
+	// stop once the relative stress improvement of an iteration drops below
+	// Epsilon
+	Epsilon     float64
+	RandomFloat func() float64
+	// MaxNodes limits the graph size, since memory grows quadratically with
+	// the number of nodes
+	MaxNodes int
+}
+
+var DefaultStressConfig = StressConfig{
+	EdgeLength:    100.0,
+	MaxIterations: 300,
+	Epsilon:       1e-4,
+	MaxNodes:      5000,
+}
+
+// Stress computes a layout by stress majorization: the euclidean distance of
+// each pair of nodes approximates their graph-theoretic distance (times
+// EdgeLength). Disconnected pairs are kept one EdgeLength further apart than
+// the largest finite distance. Nodes with a position start from it, all
+// others are placed randomly.
+func Stress(ctx context.Context, nodes []*Node, edges []*Edge, conf StressConfig) (Stats, error) {
+	startTime := time.Now()
+	n := len(nodes)
+	if conf.MaxNodes > 0 && n > conf.MaxNodes {
+		return Stats{}, fmt.Errorf("stress layout supports at most %d nodes, got %d", conf.MaxNodes, n)
+	}
+	if conf.EdgeLength <= 0 {
+		conf.EdgeLength = DefaultStressConfig.EdgeLength
+	}
+	if conf.RandomFloat == nil {
+		conf.RandomFloat = func() float64 { return rand.Float64() }
+	}
+	dist := graphDistances(n, edges)
+	maxDist := 0.0
+	for i := range dist {
+		for _, d := range dist[i] {
+			if !math.IsInf(d, 1) && d > maxDist {
+				maxDist = d
+			}
+		}
+	}
+	for i := range dist {
+		for j := range dist[i] {
+			if math.IsInf(dist[i][j], 1) {
+				dist[i][j] = maxDist + 1
+			}
+			dist[i][j] *= conf.EdgeLength
+		}
+	}
+
+	size := math.Sqrt(float64(n)) * conf.EdgeLength
+	for _, node := range nodes {
+		if len(node.Pos) < 2 {
+			node.Pos = vector.Vector{
+				(conf.RandomFloat() - 0.5) * size,
+				(conf.RandomFloat() - 0.5) * size,
+			}
+		} else {
+			node.Pos = vector.Vector{node.Pos.X(), node.Pos.Y()}
+		}
+	}
+
+	stats := Stats{}
+	stress := computeStress(nodes, dist)
+	for ; stats.Iterations < conf.MaxIterations && ctx.Err() == nil; stats.Iterations++ {
+		for i, node := range nodes {
+			sum, weights := vector.Vector{0, 0}, 0.0
+			for j, other := range nodes {
+				if i == j {
+					continue
+				}
+				w := 1 / (dist[i][j] * dist[i][j])
+				delta := node.Pos.Sub(other.Pos)
+				length := delta.Magnitude()
+				target := other.Pos
+				if length > 0 {
+					target = other.Pos.Add(delta.Scale(dist[i][j] / length))
+				}
+				sum = sum.Add(target.Scale(w))
+				weights += w
+			}
+			if weights > 0 {
+				node.Pos = sum.Scale(1 / weights)
+			}
+		}
+		next := computeStress(nodes, dist)
+		if stress == 0 || (stress-next)/stress < conf.Epsilon {
+			stats.Iterations++
+			break
+		}
+		stress = next
+	}
+	stats.TotalTime = time.Since(startTime)
+	return stats, nil
+}
+
+// graphDistances returns the length of the shortest undirected path between
+// all pairs of nodes, +Inf if there is none
+func graphDistances(n int, edges []*Edge) [][]float64 {
+	neighbors := make([][]int, n)
+	for _, edge := range edges {
+		neighbors[edge.Source] = append(neighbors[edge.Source], edge.Target)
+		neighbors[edge.Target] = append(neighbors[edge.Target], edge.Source)
+	}
+	dist := make([][]float64, n)
+	for start := 0; start < n; start++ {
+		dist[start] = make([]float64, n)
+		for i := range dist[start] {
+			dist[start][i] = math.Inf(1)
+		}
+		dist[start][start] = 0
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for _, u := range neighbors[v] {
+				if math.IsInf(dist[start][u], 1) {
+					dist[start][u] = dist[start][v] + 1
+					queue = append(queue, u)
+				}
+			}
+		}
+	}
+	return dist
+}
+
+func computeStress(nodes []*Node, dist [][]float64) float64 {
+	stress := 0.0
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			diff := nodes[i].Pos.Sub(nodes[j].Pos).Magnitude() - dist[i][j]
+			stress += diff * diff / (dist[i][j] * dist[i][j])
+		}
+	}
+	return stress
+}
@@ -0,0 +1,42 @@
+package layout
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStress(t *testing.T) {
+	// path 0 - 1 - 2 - 3
+	nodes := []*Node{{}, {}, {}, {}}
+	edges := []*Edge{{Source: 0, Target: 1}, {Source: 1, Target: 2}, {Source: 2, Target: 3}}
+	rnd := rand.New(rand.NewSource(1))
+	conf := DefaultStressConfig
+	conf.RandomFloat = rnd.Float64
+	stats, err := Stress(context.Background(), nodes, edges, conf)
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.Greater(stats.Iterations, 0)
+	assert.InDelta(100.0, nodes[0].Pos.Sub(nodes[1].Pos).Magnitude(), 5)
+	assert.InDelta(300.0, nodes[0].Pos.Sub(nodes[3].Pos).Magnitude(), 15)
+}
+
+func TestStress_tooManyNodes(t *testing.T) {
+	conf := DefaultStressConfig
+	conf.MaxNodes = 1
+	_, err := Stress(context.Background(), []*Node{{}, {}}, nil, conf)
+	assert.Error(t, err)
+}
+
+func TestStress_disconnected(t *testing.T) {
+	nodes := []*Node{{}, {}, {}}
+	edges := []*Edge{{Source: 0, Target: 1}}
+	_, err := Stress(context.Background(), nodes, edges, DefaultStressConfig)
+	assert := assert.New(t)
+	assert.NoError(err)
+	for _, node := range nodes {
+		assert.False(node.Pos.Magnitude() != node.Pos.Magnitude(), "NaN position")
+	}
+}
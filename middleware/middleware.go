@@ -3,24 +3,34 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog/log"
+	"github.com/suxatcode/learn-graph-poc-backend/db"
 )
 
 const (
-	// TODO(maybe): could just use 'Accept-Language' header, with example content [en-US,en;q=0.9]
-	httpHeaderLanguage = "Language"
-	contextLanguage    = "Language"
+	// overrides httpHeaderAcceptLanguage, if present
+	httpHeaderLanguage       = "Language"
+	httpHeaderAcceptLanguage = "Accept-Language"
+	contextLanguage          = "Language"
+	contextLanguages         = "Languages"
 
 	httpHeaderAuthenticationToken = "Authentication"
 	contextAuthenticationToken    = "Authentication"
 
 	httpHeaderUserID = "Userid"
 	contextUserID    = "UserID"
+
+	// opt-in to unreviewed machine translations
+	httpHeaderMachineTranslations = "Machine-Translations"
+	contextMachineTranslations    = "MachineTranslations"
 )
 
 func AddAll(next http.Handler) http.Handler {
-	return addGlobalLoggerToReqCtx(AddUserID(AddAuthentication(AddLanguageAndLogging(next))))
+	return addGlobalLoggerToReqCtx(AddUserID(AddAuthentication(AddMachineTranslations(AddLanguageAndLogging(next)))))
 }
 
 func addGlobalLoggerToReqCtx(next http.Handler) http.Handler {
@@ -29,15 +39,86 @@ func addGlobalLoggerToReqCtx(next http.Handler) http.Handler {
 	})
 }
 
+// AddLanguageAndLogging stores the preferred languages of the request in the
+// context: the 'Language' header first, followed by the 'Accept-Language'
+// header ordered by quality value.
 func AddLanguageAndLogging(next http.Handler) http.Handler {
-	return translateHTTPHeaderToContextValue(next, headerConfig{
-		Name:       "language",
-		HTTPHeader: httpHeaderLanguage,
-		ContextKey: contextLanguage,
-		LoggerKey:  "lang",
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		languages := []string{}
+		if header, ok := r.Header[httpHeaderLanguage]; ok && len(header) == 1 && header[0] != "" {
+			languages = append(languages, header[0])
+		}
+		for _, lang := range ParseAcceptLanguage(r.Header.Get(httpHeaderAcceptLanguage)) {
+			if !db.Contains(languages, lang) {
+				languages = append(languages, lang)
+			}
+		}
+		if len(languages) == 0 {
+			log.Debug().Msgf("no language HTTP header (keys='%s','%s') found in request: %v", httpHeaderLanguage, httpHeaderAcceptLanguage, r.Header)
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := context.WithValue(r.Context(), contextLanguage, languages[0])
+		ctx = context.WithValue(ctx, contextLanguages, languages)
+		logger := log.Ctx(r.Context()).With().Str("lang", languages[0]).Logger()
+		next.ServeHTTP(w, r.WithContext(logger.WithContext(ctx)))
 	})
 }
 
+type weightedLanguage struct {
+	Language string
+	Quality  float64
+}
+
+// ParseAcceptLanguage returns the languages of an 'Accept-Language' header
+// (e.g. "de-CH,de;q=0.9,en;q=0.8") ordered by descending quality value.
+// Languages with a region are followed by their base language, unless the
+// base language is already listed. Wildcards and q=0 entries are dropped.
+func ParseAcceptLanguage(header string) []string {
+	weighted := []weightedLanguage{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		lang := strings.TrimSpace(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			quality = q
+		}
+		if quality == 0 {
+			continue
+		}
+		weighted = append(weighted, weightedLanguage{Language: lang, Quality: quality})
+	}
+	sort.SliceStable(weighted, func(i, j int) bool { return weighted[i].Quality > weighted[j].Quality })
+	listed := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		listed = append(listed, w.Language)
+	}
+	languages := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		if !db.Contains(languages, w.Language) {
+			languages = append(languages, w.Language)
+		}
+		if i := strings.IndexAny(w.Language, "-_"); i > 0 {
+			base := w.Language[:i]
+			if !db.Contains(listed, base) && !db.Contains(languages, base) {
+				languages = append(languages, base)
+			}
+		}
+	}
+	return languages
+}
+
 func AddAuthentication(next http.Handler) http.Handler {
 	return translateHTTPHeaderToContextValue(next, headerConfig{
 		Name:         "authentication",
@@ -56,6 +137,14 @@ func AddUserID(next http.Handler) http.Handler {
 	})
 }
 
+func AddMachineTranslations(next http.Handler) http.Handler {
+	return translateHTTPHeaderToContextValue(next, headerConfig{
+		Name:       "machine translations",
+		HTTPHeader: httpHeaderMachineTranslations,
+		ContextKey: contextMachineTranslations,
+	})
+}
+
 func ctxGetStringValueOrEmptyString(ctx context.Context, value string) string {
 	if lang, ok := ctx.Value(value).(string); ok {
 		return lang
@@ -72,11 +161,43 @@ func CtxGetLanguage(ctx context.Context) string {
 	return ctxGetStringValueOrEmptyString(ctx, contextLanguage)
 }
 
+// CtxGetMachineTranslations returns true, if the request opted in to
+// unreviewed machine translations
+func CtxGetMachineTranslations(ctx context.Context) bool {
+	enabled, err := strconv.ParseBool(ctxGetStringValueOrEmptyString(ctx, contextMachineTranslations))
+	return err == nil && enabled
+}
+
+// CtxGetLanguages returns the preferred languages of the request, most
+// preferred first.
+func CtxGetLanguages(ctx context.Context) []string {
+	if languages, ok := ctx.Value(contextLanguages).([]string); ok {
+		return languages
+	}
+	if lang := CtxGetLanguage(ctx); lang != "" {
+		return []string{lang}
+	}
+	return []string{}
+}
+
 // testing purposes only
 func TestingCtxNewWithLanguage(ctx context.Context, lang string) context.Context {
 	return context.WithValue(ctx, contextLanguage, lang)
 }
 
+// testing purposes only
+func TestingCtxNewWithLanguages(ctx context.Context, languages []string) context.Context {
+	if len(languages) > 0 {
+		ctx = context.WithValue(ctx, contextLanguage, languages[0])
+	}
+	return context.WithValue(ctx, contextLanguages, languages)
+}
+
+// testing purposes only
+func TestingCtxNewWithMachineTranslations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextMachineTranslations, "true")
+}
+
 // testing purposes only
 func TestingCtxNewWithAuthentication(ctx context.Context, token string) context.Context {
 	return context.WithValue(ctx, contextAuthenticationToken, token)
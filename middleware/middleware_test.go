@@ -27,6 +27,82 @@ func TestAddLanguageMiddleware(t *testing.T) {
 	assert.True(t, called, "middleware handler must call next handler")
 }
 
+func TestAddLanguageMiddleware_AcceptLanguage(t *testing.T) {
+	for _, test := range []struct {
+		Name         string
+		Headers      map[string]string
+		ExpLanguage  string
+		ExpLanguages []string
+	}{
+		{
+			Name:         "no headers",
+			ExpLanguage:  "",
+			ExpLanguages: []string{},
+		},
+		{
+			Name:         "Accept-Language only",
+			Headers:      map[string]string{"Accept-Language": "de;q=0.5,fr"},
+			ExpLanguage:  "fr",
+			ExpLanguages: []string{"fr", "de"},
+		},
+		{
+			Name:         "Language header overrides Accept-Language",
+			Headers:      map[string]string{"Accept-Language": "de,fr;q=0.5", "Language": "fr"},
+			ExpLanguage:  "fr",
+			ExpLanguages: []string{"fr", "de"},
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					called = true
+					assert.Equal(t, test.ExpLanguage, CtxGetLanguage(r.Context()))
+					assert.Equal(t, test.ExpLanguages, CtxGetLanguages(r.Context()))
+				},
+			)
+			handler := AddLanguageAndLogging(next)
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "idk", nil)
+			for key, value := range test.Headers {
+				req.Header.Add(key, value)
+			}
+			handler.ServeHTTP(nil, req)
+			assert.True(t, called, "middleware handler must call next handler")
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Header string
+		Exp    []string
+	}{
+		{Name: "empty", Header: "", Exp: []string{}},
+		{Name: "single", Header: "de", Exp: []string{"de"}},
+		{Name: "browser default", Header: "en-US,en;q=0.9", Exp: []string{"en-US", "en"}},
+		{Name: "ordered by quality", Header: "fr;q=0.2, de;q=0.8, zh", Exp: []string{"zh", "de", "fr"}},
+		{Name: "equal quality keeps header order", Header: "it;q=0.5,es;q=0.5", Exp: []string{"it", "es"}},
+		{Name: "base language added after region", Header: "de-CH,fr;q=0.5", Exp: []string{"de-CH", "de", "fr"}},
+		{Name: "explicit base language keeps its quality", Header: "en-GB,de;q=0.9,en;q=0.8", Exp: []string{"en-GB", "de", "en"}},
+		{Name: "wildcard and q=0 are dropped", Header: "*,ja;q=0,de;q=0.1", Exp: []string{"de"}},
+		{Name: "invalid quality is dropped", Header: "ja;q=abc,de", Exp: []string{"de"}},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Exp, ParseAcceptLanguage(test.Header))
+		})
+	}
+}
+
+func TestCtxGetLanguages(t *testing.T) {
+	assert.Equal(t, []string{}, CtxGetLanguages(context.Background()), "no language")
+	ctx := TestingCtxNewWithLanguage(context.Background(), "de")
+	assert.Equal(t, []string{"de"}, CtxGetLanguages(ctx), "single language")
+	ctx = TestingCtxNewWithLanguages(context.Background(), []string{"de", "en"})
+	assert.Equal(t, []string{"de", "en"}, CtxGetLanguages(ctx), "multiple languages")
+	assert.Equal(t, "de", CtxGetLanguage(ctx), "most preferred language")
+}
+
 func TestCtxGetLanguage(t *testing.T) {
 	ctx := context.WithValue(context.Background(), "a", "c")
 	assert.Equal(t, "", CtxGetLanguage(ctx), "language key not found")
@@ -66,6 +142,36 @@ func TestAddAuthenticationMiddlewareWithBearerPrefix(t *testing.T) {
 	assert.True(t, called, "middleware handler must call next handler")
 }
 
+func TestAddMachineTranslationsMiddleware(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Header string
+		Exp    bool
+	}{
+		{Name: "no header", Exp: false},
+		{Name: "enabled", Header: "true", Exp: true},
+		{Name: "disabled", Header: "false", Exp: false},
+		{Name: "invalid", Header: "maybe", Exp: false},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					called = true
+					assert.Equal(t, test.Exp, CtxGetMachineTranslations(r.Context()))
+				},
+			)
+			handler := AddMachineTranslations(next)
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "idk", nil)
+			if test.Header != "" {
+				req.Header.Add("Machine-Translations", test.Header)
+			}
+			handler.ServeHTTP(nil, req)
+			assert.True(t, called, "middleware handler must call next handler")
+		})
+	}
+}
+
 func TestAddAll(t *testing.T) {
 	logBuffer := bytes.NewBuffer([]byte{})
 	log.Logger = zerolog.New(logBuffer).Level(zerolog.DebugLevel).With().Str("test", "test").Logger()
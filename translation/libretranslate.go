@@ -0,0 +1,71 @@
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LibreTranslateProvider uses a (self-hosted) LibreTranslate instance,
+// see https://github.com/LibreTranslate/LibreTranslate
+type LibreTranslateProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewLibreTranslateProvider(url, apiKey string, timeout time.Duration) *LibreTranslateProvider {
+	return &LibreTranslateProvider{
+		url:    strings.TrimSuffix(url, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *LibreTranslateProvider) Name() string { return "libretranslate" }
+
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+func (p *LibreTranslateProvider) Translate(ctx context.Context, text, from, to string) (string, error) {
+	body, err := json.Marshal(libreTranslateRequest{Q: text, Source: from, Target: to, Format: "text", APIKey: p.apiKey})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "libretranslate request failed")
+	}
+	defer resp.Body.Close()
+	res := libreTranslateResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", errors.Wrapf(err, "failed to decode libretranslate response (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusBadRequest {
+			return "", errors.Wrap(ErrNotSupported, res.Error)
+		}
+		return "", errors.Errorf("libretranslate returned status %d: %s", resp.StatusCode, res.Error)
+	}
+	return res.TranslatedText, nil
+}
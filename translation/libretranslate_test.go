@@ -0,0 +1,47 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibreTranslateProvider_Translate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := libreTranslateRequest{}
+		assert.Equal(t, "/translate", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "secret", req.APIKey)
+		assert.Equal(t, "text", req.Format)
+		switch req.Target {
+		case "de":
+			json.NewEncoder(w).Encode(libreTranslateResponse{TranslatedText: req.Q + " (de)"})
+		case "xx":
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(libreTranslateResponse{Error: "xx is not supported"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(libreTranslateResponse{Error: "boom"})
+		}
+	}))
+	defer server.Close()
+	p := NewLibreTranslateProvider(server.URL, "secret", time.Second)
+	assert := assert.New(t)
+
+	translated, err := p.Translate(context.Background(), "Algebra", "en", "de")
+	assert.NoError(err)
+	assert.Equal("Algebra (de)", translated)
+
+	_, err = p.Translate(context.Background(), "Algebra", "en", "xx")
+	assert.True(errors.Is(err, ErrNotSupported), "unsupported language, got '%v'", err)
+
+	_, err = p.Translate(context.Background(), "Algebra", "en", "fr")
+	assert.Error(err)
+	assert.False(errors.Is(err, ErrNotSupported))
+}
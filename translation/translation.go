@@ -0,0 +1,159 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/pkg/errors"
+)
+
+// ErrNotSupported is returned by a Provider, which can not translate between
+// the requested languages
+var ErrNotSupported = errors.New("translation not supported")
+
+// Provider translates texts, e.g. via a machine translation engine
+type Provider interface {
+	// Name identifies the provider, it is stored alongside the drafts
+	Name() string
+	Translate(ctx context.Context, text, from, to string) (string, error)
+}
+
+type Config struct {
+	// one of {none, dictionary, libretranslate}
+	Provider string `env:"TRANSLATION_PROVIDER" envDefault:"none"`
+	// source languages tried in order, before all other languages of a text
+	SourceLanguages []string `env:"TRANSLATION_SOURCE_LANGUAGES" envSeparator:"," envDefault:"en"`
+	// JSON file for the dictionary provider, see LoadDictionaryFile
+	DictionaryFile       string        `env:"TRANSLATION_DICTIONARY_FILE"`
+	LibreTranslateURL    string        `env:"LIBRETRANSLATE_URL" envDefault:"http://localhost:5000"`
+	LibreTranslateAPIKey string        `env:"LIBRETRANSLATE_API_KEY"`
+	Timeout              time.Duration `env:"TRANSLATION_TIMEOUT" envDefault:"4s"` // per request, below the HTTP timeout of the server
+}
+
+func GetEnvConfig() Config {
+	conf := Config{}
+	env.Parse(&conf)
+	return conf
+}
+
+// Draft is a translation of a node, which has not been reviewed by a human
+type Draft struct {
+	Language    string
+	Description string
+	Resources   *string
+	Provider    string
+}
+
+type Translator struct {
+	provider        Provider
+	sourceLanguages []string
+}
+
+func NewTranslator(provider Provider, sourceLanguages []string) *Translator {
+	return &Translator{provider: provider, sourceLanguages: sourceLanguages}
+}
+
+func NewTranslatorFromConfig(conf Config) (*Translator, error) {
+	var provider Provider
+	switch conf.Provider {
+	case "", "none":
+		provider = NoopProvider{}
+	case "dictionary":
+		dict, err := LoadDictionaryFile(conf.DictionaryFile)
+		if err != nil {
+			return nil, err
+		}
+		provider = dict
+	case "libretranslate":
+		provider = NewLibreTranslateProvider(conf.LibreTranslateURL, conf.LibreTranslateAPIKey, conf.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown translation provider '%s'", conf.Provider)
+	}
+	return NewTranslator(provider, conf.SourceLanguages), nil
+}
+
+// SourceLanguage returns the language of text to translate from: the first
+// existing source language, otherwise the alphabetically first language.
+func (t *Translator) SourceLanguage(text map[string]string) (string, bool) {
+	for _, lang := range t.sourceLanguages {
+		if _, ok := text[lang]; ok {
+			return lang, true
+		}
+	}
+	languages := make([]string, 0, len(text))
+	for lang := range text {
+		languages = append(languages, lang)
+	}
+	if len(languages) == 0 {
+		return "", false
+	}
+	sort.Strings(languages)
+	return languages[0], true
+}
+
+// Draft translates description and resources of a node to language to. The
+// resources are optional: if they can not be translated, the draft has none.
+func (t *Translator) Draft(ctx context.Context, description, resources map[string]string, to string) (*Draft, error) {
+	from, ok := t.SourceLanguage(description)
+	if !ok {
+		return nil, errors.New("no description to translate from")
+	}
+	translated, err := t.provider.Translate(ctx, description[from], from, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to translate description from '%s' to '%s'", from, to)
+	}
+	draft := &Draft{Language: to, Description: translated, Provider: t.provider.Name()}
+	if from, ok := t.SourceLanguage(resources); ok {
+		translated, err := t.provider.Translate(ctx, resources[from], from, to)
+		if errors.Is(err, ErrNotSupported) {
+			return draft, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to translate resources from '%s' to '%s'", from, to)
+		}
+		draft.Resources = &translated
+	}
+	return draft, nil
+}
+
+// NoopProvider does not translate anything
+type NoopProvider struct{}
+
+func (NoopProvider) Name() string { return "none" }
+
+func (NoopProvider) Translate(ctx context.Context, text, from, to string) (string, error) {
+	return "", ErrNotSupported
+}
+
+// DictionaryProvider translates only the texts found in its dictionary,
+// which maps source language → target language → text → translation.
+type DictionaryProvider map[string]map[string]map[string]string
+
+func (DictionaryProvider) Name() string { return "dictionary" }
+
+func (d DictionaryProvider) Translate(ctx context.Context, text, from, to string) (string, error) {
+	if translated, ok := d[from][to][text]; ok {
+		return translated, nil
+	}
+	return "", ErrNotSupported
+}
+
+// LoadDictionaryFile reads a JSON encoded DictionaryProvider, e.g.
+//
+//	{"en": {"de": {"Linear algebra": "Lineare Algebra"}}}
+func LoadDictionaryFile(path string) (DictionaryProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read dictionary '%s'", path)
+	}
+	dict := DictionaryProvider{}
+	if err := json.Unmarshal(data, &dict); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse dictionary '%s'", path)
+	}
+	return dict, nil
+}
@@ -0,0 +1,113 @@
+package translation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func strptr(s string) *string {
+	return &s
+}
+
+func TestTranslator_SourceLanguage(t *testing.T) {
+	for _, test := range []struct {
+		Name            string
+		SourceLanguages []string
+		Text            map[string]string
+		Exp             string
+		ExpOK           bool
+	}{
+		{Name: "empty text", SourceLanguages: []string{"en"}, Text: map[string]string{}, ExpOK: false},
+		{Name: "source language", SourceLanguages: []string{"en"}, Text: map[string]string{"de": "a", "en": "b"}, Exp: "en", ExpOK: true},
+		{Name: "source languages in order", SourceLanguages: []string{"fr", "de", "en"}, Text: map[string]string{"de": "a", "en": "b"}, Exp: "de", ExpOK: true},
+		{Name: "alphabetically first language", SourceLanguages: []string{"en"}, Text: map[string]string{"zh": "a", "it": "b"}, Exp: "it", ExpOK: true},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			lang, ok := NewTranslator(NoopProvider{}, test.SourceLanguages).SourceLanguage(test.Text)
+			assert.Equal(t, test.Exp, lang)
+			assert.Equal(t, test.ExpOK, ok)
+		})
+	}
+}
+
+func TestTranslator_Draft(t *testing.T) {
+	dict := DictionaryProvider{"en": {"de": {"Algebra": "Algebra (de)", "a book": "ein Buch"}}}
+	for _, test := range []struct {
+		Name        string
+		Provider    Provider
+		Description map[string]string
+		Resources   map[string]string
+		Exp         *Draft
+		ExpErr      error
+	}{
+		{
+			Name:        "description & resources",
+			Provider:    dict,
+			Description: map[string]string{"en": "Algebra"},
+			Resources:   map[string]string{"en": "a book"},
+			Exp:         &Draft{Language: "de", Description: "Algebra (de)", Resources: strptr("ein Buch"), Provider: "dictionary"},
+		},
+		{
+			Name:        "only description",
+			Provider:    dict,
+			Description: map[string]string{"en": "Algebra"},
+			Exp:         &Draft{Language: "de", Description: "Algebra (de)", Provider: "dictionary"},
+		},
+		{
+			Name:        "untranslatable resources",
+			Provider:    dict,
+			Description: map[string]string{"en": "Algebra"},
+			Resources:   map[string]string{"en": "a video"},
+			Exp:         &Draft{Language: "de", Description: "Algebra (de)", Provider: "dictionary"},
+		},
+		{
+			Name:        "unknown text",
+			Provider:    dict,
+			Description: map[string]string{"en": "Geometry"},
+			ExpErr:      ErrNotSupported,
+		},
+		{
+			Name:        "no-op provider",
+			Provider:    NoopProvider{},
+			Description: map[string]string{"en": "Algebra"},
+			ExpErr:      ErrNotSupported,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			draft, err := NewTranslator(test.Provider, []string{"en"}).Draft(context.Background(), test.Description, test.Resources, "de")
+			assert.Equal(t, test.Exp, draft)
+			if test.ExpErr != nil {
+				assert.True(t, errors.Is(err, test.ExpErr), "expected error '%v', got '%v'", test.ExpErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewTranslatorFromConfig(t *testing.T) {
+	assert := assert.New(t)
+	translator, err := NewTranslatorFromConfig(Config{Provider: "none"})
+	assert.NoError(err)
+	assert.Equal(NoopProvider{}, translator.provider)
+
+	translator, err = NewTranslatorFromConfig(Config{Provider: "libretranslate", LibreTranslateURL: "http://localhost:5000/"})
+	assert.NoError(err)
+	assert.Equal("http://localhost:5000", translator.provider.(*LibreTranslateProvider).url)
+
+	file := filepath.Join(t.TempDir(), "dict.json")
+	assert.NoError(os.WriteFile(file, []byte(`{"en": {"de": {"Algebra": "Algebra (de)"}}}`), 0o600))
+	translator, err = NewTranslatorFromConfig(Config{Provider: "dictionary", DictionaryFile: file})
+	assert.NoError(err)
+	assert.Equal(DictionaryProvider{"en": {"de": {"Algebra": "Algebra (de)"}}}, translator.provider)
+
+	_, err = NewTranslatorFromConfig(Config{Provider: "dictionary", DictionaryFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(err)
+	_, err = NewTranslatorFromConfig(Config{Provider: "unknown"})
+	assert.Error(err)
+}